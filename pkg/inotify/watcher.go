@@ -0,0 +1,200 @@
+// Пакет inotify предоставляет минимальную обёртку над inotify для отслеживания
+// изменений в каталогах-источниках проекта.
+package inotify
+
+import (
+	"context"
+	"encoding/binary"
+	"io/fs"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// Mask — события, после которых содержимое каталога или файла могло измениться
+const Mask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_CLOSE_WRITE | unix.IN_MODIFY |
+	unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_ATTRIB | unix.IN_DELETE_SELF | unix.IN_MOVE_SELF
+
+// Event представляет событие inotify
+type Event struct {
+	Path string // путь файла внутри наблюдаемого каталога или сам наблюдаемый путь
+	Mask uint32
+}
+
+// Watcher наблюдает за набором путей через один inotify descriptor
+type Watcher struct {
+	fd     int
+	mu     sync.Mutex
+	paths  map[int32]string // watch descriptor → путь
+	trees  map[string]bool  // каталоги, новые подкаталоги которых наблюдаются автоматически
+	stopCh chan struct{}
+}
+
+// New создаёт Watcher с неблокирующим inotify descriptor
+func New() (*Watcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	return &Watcher{
+		fd:     fd,
+		paths:  make(map[int32]string),
+		trees:  make(map[string]bool),
+		stopCh: make(chan struct{}),
+	}, nil
+}
+
+// Add начинает наблюдение за путём. Каталоги наблюдаются без вложенных каталогов —
+// их нужно добавлять отдельно.
+func (w *Watcher) Add(path string) error {
+	wd, err := unix.InotifyAddWatch(w.fd, path, Mask)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	w.paths[int32(wd)] = path
+	w.mu.Unlock()
+	return nil
+}
+
+// AddTree начинает наблюдение за каталогом, поддерево которого отслеживается целиком:
+// каталоги, созданные в нём позже, Listen добавляет сам. Существующие подкаталоги
+// нужно добавить отдельно.
+func (w *Watcher) AddTree(path string) error {
+	if err := w.Add(path); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	w.trees[path] = true
+	w.mu.Unlock()
+	return nil
+}
+
+// watchNewDir добавляет каталог, созданный или перемещённый внутрь наблюдаемого
+// дерева, вместе с подкаталогами, появившимися до установки наблюдения
+func (w *Watcher) watchNewDir(ev Event) {
+	if ev.Mask&unix.IN_ISDIR == 0 || ev.Mask&(unix.IN_CREATE|unix.IN_MOVED_TO) == 0 {
+		return
+	}
+	w.mu.Lock()
+	inTree := w.trees[filepath.Dir(ev.Path)]
+	w.mu.Unlock()
+	if !inTree {
+		return
+	}
+	_ = filepath.WalkDir(ev.Path, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			// Ошибку (например, лимит наблюдений) пропускаем: остальное дерево наблюдается
+			_ = w.AddTree(p)
+		}
+		return nil
+	})
+}
+
+// Listen читает события inotify и отправляет их в канал.
+// Канал закрывается при отмене контекста или вызове Close().
+func (w *Watcher) Listen(ctx context.Context) <-chan Event {
+	ch := make(chan Event, 64)
+
+	go func() {
+		defer close(ch)
+		buf := make([]byte, 64*1024)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-w.stopCh:
+				return
+			default:
+			}
+
+			// Таймаут poll, чтобы периодически проверять отмену контекста
+			fds := []unix.PollFd{{Fd: int32(w.fd), Events: unix.POLLIN}}
+			n, err := unix.Poll(fds, 1000)
+			if err != nil {
+				if err == unix.EINTR {
+					continue
+				}
+				return
+			}
+			if n == 0 {
+				continue
+			}
+
+			n, err = unix.Read(w.fd, buf)
+			if err != nil {
+				if err == unix.EAGAIN || err == unix.EINTR {
+					continue
+				}
+				// Descriptor закрыт
+				return
+			}
+
+			w.mu.Lock()
+			events := ParseEvents(buf[:n], w.paths)
+			w.mu.Unlock()
+			for _, ev := range events {
+				w.watchNewDir(ev)
+				select {
+				case ch <- ev:
+				case <-ctx.Done():
+					return
+				case <-w.stopCh:
+					return
+				}
+			}
+		}
+	}()
+
+	return ch
+}
+
+// Close закрывает inotify descriptor и останавливает горутину Listen.
+func (w *Watcher) Close() error {
+	select {
+	case <-w.stopCh:
+		// Уже закрыт
+		return nil
+	default:
+		close(w.stopCh)
+	}
+	return unix.Close(w.fd)
+}
+
+// ParseEvents разбирает буфер, прочитанный из inotify descriptor.
+// Формат: struct inotify_event, за которой следует имя длиной len с нулевым дополнением.
+// События неизвестных watch descriptor пропускаются, переполнение очереди
+// передаётся событием с пустым путём.
+func ParseEvents(data []byte, paths map[int32]string) []Event {
+	var events []Event
+	for len(data) >= unix.SizeofInotifyEvent {
+		wd := int32(binary.NativeEndian.Uint32(data[0:]))
+		mask := binary.NativeEndian.Uint32(data[4:])
+		size := unix.SizeofInotifyEvent + int(binary.NativeEndian.Uint32(data[12:]))
+		if size > len(data) {
+			break
+		}
+		name := data[unix.SizeofInotifyEvent:size]
+		for i, b := range name {
+			if b == 0 {
+				name = name[:i]
+				break
+			}
+		}
+
+		if mask&unix.IN_Q_OVERFLOW != 0 {
+			// Очередь переполнена, часть событий потеряна — путь неизвестен
+			events = append(events, Event{Mask: mask})
+		} else if dir, ok := paths[wd]; ok {
+			path := dir
+			if len(name) > 0 {
+				path = filepath.Join(dir, string(name))
+			}
+			events = append(events, Event{Path: path, Mask: mask})
+		}
+		data = data[size:]
+	}
+	return events
+}
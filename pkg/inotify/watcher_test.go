@@ -0,0 +1,111 @@
+package inotify
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// buildEvent собирает struct inotify_event с именем, дополненным нулями до padded байт
+func buildEvent(wd int32, mask uint32, name string, padded int) []byte {
+	buf := make([]byte, unix.SizeofInotifyEvent+padded)
+	binary.NativeEndian.PutUint32(buf[0:], uint32(wd))
+	binary.NativeEndian.PutUint32(buf[4:], mask)
+	binary.NativeEndian.PutUint32(buf[12:], uint32(padded))
+	copy(buf[unix.SizeofInotifyEvent:], name)
+	return buf
+}
+
+func TestParseEvents(t *testing.T) {
+	paths := map[int32]string{1: "/src/photos"}
+	var data []byte
+	data = append(data, buildEvent(1, unix.IN_CREATE, "a.jpg", 16)...)
+	data = append(data, buildEvent(1, unix.IN_DELETE_SELF, "", 0)...)
+	data = append(data, buildEvent(7, unix.IN_CREATE, "unknown", 16)...)
+	data = append(data, buildEvent(-1, unix.IN_Q_OVERFLOW, "", 0)...)
+
+	events := ParseEvents(data, paths)
+	if len(events) != 3 {
+		t.Fatalf("len(events) = %d, want 3: %+v", len(events), events)
+	}
+	if events[0].Path != "/src/photos/a.jpg" || events[0].Mask != unix.IN_CREATE {
+		t.Errorf("events[0] = %+v", events[0])
+	}
+	if events[1].Path != "/src/photos" {
+		t.Errorf("events[1] = %+v", events[1])
+	}
+	if events[2].Path != "" || events[2].Mask&unix.IN_Q_OVERFLOW == 0 {
+		t.Errorf("events[2] = %+v, want overflow", events[2])
+	}
+}
+
+func TestParseEvents_Truncated(t *testing.T) {
+	data := buildEvent(1, unix.IN_CREATE, "a.jpg", 16)
+	if events := ParseEvents(data[:len(data)-4], map[int32]string{1: "/src"}); len(events) != 0 {
+		t.Errorf("truncated event parsed: %+v", events)
+	}
+}
+
+func TestWatcher_Listen(t *testing.T) {
+	dir := t.TempDir()
+	w, err := New()
+	if err != nil {
+		t.Skipf("inotify unavailable: %v", err)
+	}
+	defer w.Close()
+	if err := w.Add(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := w.Listen(ctx)
+
+	os.WriteFile(filepath.Join(dir, "new.txt"), []byte("x"), 0644)
+	select {
+	case ev := <-events:
+		if ev.Path != filepath.Join(dir, "new.txt") {
+			t.Errorf("event path = %q", ev.Path)
+		}
+	case <-ctx.Done():
+		t.Fatal("no event for a created file")
+	}
+}
+
+func TestWatcher_AddTreeWatchesNewDirs(t *testing.T) {
+	dir := t.TempDir()
+	w, err := New()
+	if err != nil {
+		t.Skipf("inotify unavailable: %v", err)
+	}
+	defer w.Close()
+	if err := w.AddTree(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := w.Listen(ctx)
+
+	sub := filepath.Join(dir, "sub")
+	os.Mkdir(sub, 0755)
+	// Файл в новом каталоге виден только через наблюдение, добавленное Listen
+	want := filepath.Join(sub, "new.txt")
+	deadline := time.After(4 * time.Second)
+	for {
+		os.WriteFile(want, []byte("x"), 0644)
+		select {
+		case ev := <-events:
+			if ev.Path == want {
+				return
+			}
+		case <-deadline:
+			t.Fatal("no event for a file in a directory created after AddTree")
+		}
+	}
+}
@@ -13,6 +13,7 @@ const (
 	BurnStateError       BurnState = "error"
 	BurnStateCancelled   BurnState = "cancelled"
 	BurnStateCreatingISO BurnState = "creating_iso"
+	BurnStateExtracting  BurnState = "extracting"
 )
 
 type BurnJob struct {
@@ -42,4 +43,8 @@ type BurnResult struct {
 	AverageSpeed string `json:"averageSpeed"`
 	MD5Match     bool   `json:"md5Match"`
 	VerifyErrors int    `json:"verifyErrors"`
+	// Извлечение из образа
+	FilesSkipped int `json:"filesSkipped,omitempty"`
+	FilesRenamed int `json:"filesRenamed,omitempty"`
+	MD5Checked   int `json:"md5Checked,omitempty"`
 }
@@ -4,3 +4,55 @@ const (
 	// BlockSizeBytes is the standard block size for optical media (CD/DVD/BD)
 	BlockSizeBytes = 2048
 )
+
+// Sort presets place files on the medium. Files with a higher weight are written
+// closer to the start of the image. Preset weights are zero or negative, so any
+// positive manual weight or rule puts a file ahead of the preset layout.
+const (
+	SortPresetManual     = "manual"      // только веса записей и правила
+	SortPresetSmallFirst = "small-first" // сначала маленькие файлы
+	SortPresetByPath     = "by-path"     // в порядке путей образа
+)
+
+// Entry types for sources that are neither regular files nor directories.
+// Such entries carry no data, only a Rock Ridge node.
+const (
+	EntryTypeSymlink = "symlink"
+	EntryTypeSpecial = "special" // устройство, FIFO или сокет
+)
+
+// Link policies decide how symbolic links found in sources are recorded.
+// An empty policy means LinkPolicyKeep.
+const (
+	LinkPolicyKeep          = "keep"           // записать ссылку как Rock Ridge symlink
+	LinkPolicyFollow        = "follow"         // записать файл или папку, на которые указывает ссылка
+	LinkPolicySkip          = "skip"           // не добавлять ссылки
+	LinkPolicyRejectOutside = "reject-outside" // не добавлять ссылки, ведущие за пределы проекта
+)
+
+// Special file policies for device nodes, FIFOs and sockets.
+// An empty policy means SpecialFilesKeep.
+const (
+	SpecialFilesKeep = "keep"
+	SpecialFilesSkip = "skip"
+)
+
+// MediaCapacity describes the data capacity of a blank medium type
+type MediaCapacity struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Capacity int64  `json:"capacity"` // байт
+}
+
+// KnownMediaCapacities lists common blank media, capacities are taken from the
+// number of writable 2048-byte blocks
+var KnownMediaCapacities = []MediaCapacity{
+	{ID: "cd-74", Name: "CD-R 74 min", Capacity: 333000 * BlockSizeBytes},
+	{ID: "cd-80", Name: "CD-R 80 min", Capacity: 360000 * BlockSizeBytes},
+	{ID: "dvd", Name: "DVD±R", Capacity: 2295104 * BlockSizeBytes},
+	{ID: "dvd-dl", Name: "DVD±R DL", Capacity: 4171712 * BlockSizeBytes},
+	{ID: "bd", Name: "BD-R 25 GB", Capacity: 12219392 * BlockSizeBytes},
+	{ID: "bd-dl", Name: "BD-R DL 50 GB", Capacity: 24438784 * BlockSizeBytes},
+	{ID: "bd-tl", Name: "BD-R XL 100 GB", Capacity: 48878592 * BlockSizeBytes},
+	{ID: "bd-ql", Name: "BD-R XL 128 GB", Capacity: 62500864 * BlockSizeBytes},
+}
@@ -33,6 +33,12 @@ type MediaInfo struct {
 	SystemID      string            `json:"systemId"`
 	CreationTime  string            `json:"creationTime"`
 	ModifyTime    string            `json:"modifyTime"`
+	ExpireTime    string            `json:"expireTime"`
+	EffectiveTime string            `json:"effectiveTime"`
+	VolumeUUID    string            `json:"volumeUuid"` // время изменения тома, как его показывает GRUB
+	AbstractFile  string            `json:"abstractFile"`
+	BiblioFile    string            `json:"biblioFile"`
+	CopyrightFile string            `json:"copyrightFile"`
 }
 
 type SpeedDescriptor struct {
@@ -55,3 +61,26 @@ type Session struct {
 	Size     int64  `json:"size"`
 	VolumeID string `json:"volumeId"`
 }
+
+// USBDevice is a removable USB block device that can receive an ISO image
+type USBDevice struct {
+	Path       string         `json:"path"` // /dev/sdX
+	Vendor     string         `json:"vendor"`
+	Model      string         `json:"model"`
+	Serial     string         `json:"serial"`
+	Size       int64          `json:"size"`                 // байт
+	MountPoint string         `json:"mountPoint,omitempty"` // файловая система на всём устройстве
+	Holders    string         `json:"holders,omitempty"`
+	Partitions []USBPartition `json:"partitions"`
+	// Описание устройства для подтверждения перед записью: запись не начнётся,
+	// если по тому же пути к моменту записи окажется другое устройство
+	Identity string `json:"identity"`
+	Busy     bool   `json:"busy"` // смонтировано или занято (LVM, dm-crypt)
+}
+
+// USBPartition is a partition of a USB device with its mount point, if any
+type USBPartition struct {
+	Path       string `json:"path"`
+	MountPoint string `json:"mountPoint,omitempty"`
+	Holders    string `json:"holders,omitempty"` // устройства поверх раздела через запятую
+}
@@ -3,6 +3,7 @@ package models
 const (
 	EventDeviceListUpdated  = "device:list-updated"
 	EventDeviceMediaChanged = "device:media-changed"
+	EventUSBListUpdated     = "device:usb-list-updated"
 
 	EventBurnProgress     = "burn:progress"
 	EventBurnStateChanged = "burn:state-changed"
@@ -14,4 +15,9 @@ const (
 	EventVerifyComplete = "verify:complete"
 
 	EventProjectSizeChanged = "project:size-changed"
+	EventSourcesSkipped     = "project:sources-skipped"
+	EventSourcesChanged     = "project:sources-changed"
+
+	EventDuplicateScanProgress = "project:duplicate-scan-progress"
+	EventDuplicateScanComplete = "project:duplicate-scan-complete"
 )
@@ -0,0 +1,37 @@
+package models
+
+// ISOFile is a file or directory of a session loaded from a disc or an ISO image
+type ISOFile struct {
+	Path   string `json:"path"`
+	Name   string `json:"name"`
+	IsDir  bool   `json:"isDir"`
+	Type   string `json:"type,omitempty"` // EntryTypeSymlink или EntryTypeSpecial, как у FileEntry
+	Size   int64  `json:"size"`
+	Mode   string `json:"mode"`             // права в формате ls -l
+	Target string `json:"target,omitempty"` // цель символической ссылки
+
+	Owner   string `json:"owner"`
+	Group   string `json:"group"`
+	ModTime int64  `json:"modTime"`       // мс Unix с точностью листинга ls -l
+	MD5     string `json:"md5,omitempty"` // записанная в образе контрольная сумма
+	// Имя в дереве Joliet (без Joliet — ISO 9660), если отличается от имени Rock Ridge
+	JolietName string `json:"jolietName,omitempty"`
+}
+
+// Поведение при совпадении пути извлекаемого файла с существующим
+const (
+	ConflictOverwrite = "overwrite"
+	ConflictSkip      = "skip"
+	ConflictRename    = "rename"
+)
+
+// ExtractOptions describes copying files from a disc or an ISO image to the local filesystem
+type ExtractOptions struct {
+	Session     int      `json:"session"` // 0 — последняя сессия
+	Paths       []string `json:"paths"`   // пути в образе; "/" — всё дерево
+	DestDir     string   `json:"destDir"`
+	Permissions bool     `json:"permissions"` // восстановить права из образа
+	Ownership   bool     `json:"ownership"`   // восстановить владельца (только при запуске от root)
+	Timestamps  bool     `json:"timestamps"`  // восстановить время изменения
+	OnConflict  string   `json:"onConflict"`  // ConflictOverwrite, ConflictSkip или ConflictRename
+}
@@ -2,14 +2,23 @@ package models
 
 import "time"
 
+// ProjectFormatVersion — текущая версия формата файла .xorriso-project.
+// При изменении структуры проекта версия увеличивается и добавляется миграция.
+const ProjectFormatVersion uint8 = 3
+
 type Project struct {
+	ID          string      `json:"id"` // идентификатор сеанса редактирования, новый при каждом открытии
 	Version     uint8       `json:"version"`
 	Name        string      `json:"name"`
 	FilePath    string      `json:"filePath"`
 	VolumeID    string      `json:"volumeId"`
 	Entries     []FileEntry `json:"entries"`
+	SortRules   []SortRule  `json:"sortRules,omitempty"`
+	Exclude     []string    `json:"exclude,omitempty"` // шаблоны имён, пропускаемых при добавлении папок
+	Removed     []string    `json:"removed,omitempty"` // источники, убранные из папок проекта: обновление не добавит их снова
 	ISOOptions  ISOOptions  `json:"isoOptions"`
 	BurnOptions BurnOptions `json:"burnOptions"`
+	BaseImage   *BaseImage  `json:"baseImage,omitempty"` // проект изменяет существующий образ
 	CreatedAt   time.Time   `json:"createdAt"`
 	UpdatedAt   time.Time   `json:"updatedAt"`
 }
@@ -20,19 +29,75 @@ type FileEntry struct {
 	Name       string `json:"name"`
 	IsDir      bool   `json:"isDir"`
 	Size       int64  `json:"size"`
-	ModTime    int64  `json:"modTime"` // Unix timestamp в миллисекундах
+	ModTime    int64  `json:"modTime"`             // Unix timestamp в миллисекундах
+	Type       string `json:"type,omitempty"`      // EntryType*: пусто для обычных файлов и папок
+	ImagePath  string `json:"imagePath,omitempty"` // путь узла в базовом образе, если запись взята из него
+	Listed     bool   `json:"listed,omitempty"`    // содержимое папки — отдельные записи; иначе папка с источником переносится целиком
+
+	Meta       EntryMetadata `json:"meta,omitzero"`
+	SortWeight int           `json:"sortWeight,omitempty"` // для папок — на всё поддерево
+}
+
+// BaseImage is an existing ISO image the project starts from. Entries with ImagePath
+// are nodes of that image; writing the project loads it and applies the differences.
+type BaseImage struct {
+	Path     string `json:"path"`
+	KeepBoot bool   `json:"keepBoot"` // сохранить загрузочные записи образа (-boot_image any keep)
+}
+
+// SortRule assigns a sort weight to every entry matching a glob. A pattern with
+// a slash is matched against the whole image path, otherwise against the name.
+type SortRule struct {
+	Pattern string `json:"pattern"`
+	Weight  int    `json:"weight"`
+}
+
+// EntryMetadata overrides what the image records for an entry instead of the
+// source file's attributes. For directories the override applies to the whole subtree.
+// Пустые поля не переопределяются.
+type EntryMetadata struct {
+	Mode  string `json:"mode,omitempty"`  // как у chmod: "0644" или "go-w"
+	Owner string `json:"owner,omitempty"` // имя или числовой uid
+	Group string `json:"group,omitempty"` // имя или числовой gid
+	ATime int64  `json:"atime,omitempty"` // Unix timestamp в миллисекундах
+	MTime int64  `json:"mtime,omitempty"`
+	CTime int64  `json:"ctime,omitempty"`
+
+	// Скрыть из отдельных деревьев образа
+	HideISO     bool `json:"hideIso,omitempty"` // ISO 9660 / Rock Ridge
+	HideJoliet  bool `json:"hideJoliet,omitempty"`
+	HideHFSPlus bool `json:"hideHfsPlus,omitempty"`
 }
 
 type ISOOptions struct {
-	UDF         bool   `json:"udf"`
-	ISOLevel    int    `json:"isoLevel"`
-	RockRidge   bool   `json:"rockRidge"`
-	Joliet      bool   `json:"joliet"`
-	HFSPlus     bool   `json:"hfsPlus"`
-	Zisofs      bool   `json:"zisofs"`
-	MD5         bool   `json:"md5"`
-	BackupMode  bool   `json:"backupMode"`
-	PublisherID string `json:"publisherId"`
+	UDF          bool   `json:"udf"`
+	ISOLevel     int    `json:"isoLevel"`
+	RockRidge    bool   `json:"rockRidge"`
+	Joliet       bool   `json:"joliet"`
+	HFSPlus      bool   `json:"hfsPlus"`
+	Zisofs       bool   `json:"zisofs"`
+	MD5          bool   `json:"md5"`
+	BackupMode   bool   `json:"backupMode"`
+	Hardlinks    bool   `json:"hardlinks"`    // одинаковые источники записываются один раз как жёсткие ссылки
+	SplitSize    int64  `json:"splitSize"`    // байт; файлы больше делятся на части (-split_size), 0 — не делить
+	SortPreset   string `json:"sortPreset"`   // SortPreset*: порядок файлов на диске
+	LinkPolicy   string `json:"linkPolicy"`   // LinkPolicy*: символические ссылки в источниках
+	SpecialFiles string `json:"specialFiles"` // SpecialFiles*: устройства, FIFO и сокеты
+	PublisherID  string `json:"publisherId"`
+
+	// Поля заголовка тома (PVD); пустые строки и нулевые даты — значения по умолчанию
+	VolumeSetID      string `json:"volumeSetId"`
+	PreparerID       string `json:"preparerId"`
+	ApplicationID    string `json:"applicationId"` // пусто — идентификатор xorriso-ui
+	SystemID         string `json:"systemId"`      // пусто — LINUX
+	AbstractFile     string `json:"abstractFile"`  // имена файлов в корне образа
+	BiblioFile       string `json:"biblioFile"`
+	CopyrightFile    string `json:"copyrightFile"`
+	CreationDate     int64  `json:"creationDate"` // Unix timestamp в миллисекундах, 0 — время записи
+	ModificationDate int64  `json:"modificationDate"`
+	ExpirationDate   int64  `json:"expirationDate"` // 0 — не задана
+	EffectiveDate    int64  `json:"effectiveDate"`
+	VolumeUUID       string `json:"volumeUuid"` // YYYYMMDDhhmmsscc; задаёт даты создания и изменения
 }
 
 type BurnOptions struct {
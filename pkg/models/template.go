@@ -0,0 +1,15 @@
+package models
+
+// TemplateFormatVersion — текущая версия формата файла шаблона проекта
+const TemplateFormatVersion uint8 = 1
+
+// ProjectTemplate is a named set of project settings a new project can start from.
+// VolumeIDPattern may contain {name}, {date}, {year}, {month}, {day} and {time}.
+type ProjectTemplate struct {
+	Version         uint8       `json:"version"`
+	Name            string      `json:"name"`
+	VolumeIDPattern string      `json:"volumeIdPattern"`
+	ISOOptions      ISOOptions  `json:"isoOptions"`
+	BurnOptions     BurnOptions `json:"burnOptions"`
+	Exclude         []string    `json:"exclude"`
+}
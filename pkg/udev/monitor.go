@@ -1,5 +1,6 @@
 // Пакет udev предоставляет netlink listener для udev событий блочных устройств.
-// Используется для отслеживания вставки/извлечения оптических дисков без polling.
+// Используется для отслеживания вставки/извлечения оптических дисков и
+// подключения USB-накопителей без polling.
 package udev
 
 import (
@@ -18,6 +19,12 @@ type Event struct {
 	DevType   string // "disk"
 }
 
+// Optical сообщает, относится ли событие к оптическому приводу
+func (e Event) Optical() bool { return strings.HasPrefix(e.DevName, "sr") }
+
+// OnUSB сообщает, подключено ли устройство через USB
+func (e Event) OnUSB() bool { return strings.Contains(e.DevPath, "/usb") }
+
 // Monitor слушает netlink udev события ядра
 type Monitor struct {
 	fd     int
@@ -48,7 +55,8 @@ func New() (*Monitor, error) {
 }
 
 // Listen читает udev пакеты и отправляет отфильтрованные события в канал.
-// Фильтрация: SUBSYSTEM=block и DEVNAME начинается с "sr".
+// Фильтрация: SUBSYSTEM=block, оптические приводы (DEVNAME начинается с "sr")
+// и устройства на шине USB.
 // Канал закрывается при отмене контекста или вызове Close().
 func (m *Monitor) Listen(ctx context.Context) (<-chan Event, error) {
 	ch := make(chan Event, 16)
@@ -91,8 +99,8 @@ func (m *Monitor) Listen(ctx context.Context) (<-chan Event, error) {
 				continue
 			}
 
-			// Фильтруем: только block-устройства sr*
-			if ev.Subsystem != "block" || !strings.HasPrefix(ev.DevName, "sr") {
+			// Фильтруем: только block-устройства sr* и USB
+			if ev.Subsystem != "block" || !ev.Optical() && !ev.OnUSB() {
 				continue
 			}
 
@@ -140,3 +140,24 @@ func TestParseUevent_GarbageWithAtSign(t *testing.T) {
 		t.Errorf("DevPath = %q, want %q", ev.DevPath, "/some/path")
 	}
 }
+
+func TestEvent_OpticalAndUSB(t *testing.T) {
+	usb := ParseUevent(buildUeventPacket(
+		"add@/devices/pci0000:00/0000:00:14.0/usb2/2-1/2-1:1.0/host6/target6:0:0/6:0:0:0/block/sdb",
+		"SUBSYSTEM=block",
+		"DEVNAME=sdb",
+		"DEVTYPE=disk",
+	))
+	if usb == nil || !usb.OnUSB() || usb.Optical() {
+		t.Errorf("USB event = %+v", usb)
+	}
+
+	optical := ParseUevent(buildUeventPacket(
+		"change@/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sr0",
+		"SUBSYSTEM=block",
+		"DEVNAME=sr0",
+	))
+	if optical == nil || !optical.Optical() || optical.OnUSB() {
+		t.Errorf("optical event = %+v", optical)
+	}
+}
@@ -5,6 +5,8 @@ import "strconv"
 // CommandBuilder constructs safe xorriso command-line arguments
 type CommandBuilder struct {
 	args []string
+	// Индексы начала каждой команды в args — для построчного вывода в скриптах
+	starts []int
 }
 
 func NewCommand() *CommandBuilder {
@@ -12,16 +14,29 @@ func NewCommand() *CommandBuilder {
 }
 
 func (b *CommandBuilder) add(args ...string) *CommandBuilder {
+	b.starts = append(b.starts, len(b.args))
 	b.args = append(b.args, args...)
 	return b
 }
 
+// addList добавляет команду с переменным списком путей, завершённым "--"
+func (b *CommandBuilder) addList(head []string, paths []string) *CommandBuilder {
+	args := append(head, paths...)
+	return b.add(append(args, "--")...)
+}
+
 // Basic settings
 func (b *CommandBuilder) PktOutput() *CommandBuilder           { return b.add("-pkt_output", "on") }
 func (b *CommandBuilder) Device(dev string) *CommandBuilder    { return b.add("-dev", dev) }
 func (b *CommandBuilder) InDevice(dev string) *CommandBuilder  { return b.add("-indev", dev) }
 func (b *CommandBuilder) OutDevice(dev string) *CommandBuilder { return b.add("-outdev", dev) }
 
+// Load выбирает сессию для следующего -indev или -dev: entity — session, track, lba, sbsector или volid
+func (b *CommandBuilder) Load(entity, id string) *CommandBuilder { return b.add("-load", entity, id) }
+
+// ReadFS выбирает дерево имён при загрузке образа: any, norock, nojoliet или ecma119
+func (b *CommandBuilder) ReadFS(mode string) *CommandBuilder { return b.add("-read_fs", mode) }
+
 // Information queries
 func (b *CommandBuilder) Devices() *CommandBuilder     { return b.add("-device_links") }
 func (b *CommandBuilder) TOC() *CommandBuilder         { return b.add("-toc") }
@@ -42,6 +57,25 @@ func (b *CommandBuilder) ApplicationID(id string) *CommandBuilder {
 	return b.add("-application_id", id)
 }
 func (b *CommandBuilder) SystemID(id string) *CommandBuilder { return b.add("-system_id", id) }
+func (b *CommandBuilder) VolsetID(id string) *CommandBuilder { return b.add("-volset_id", id) }
+func (b *CommandBuilder) PreparerID(id string) *CommandBuilder {
+	return b.add("-preparer_id", id)
+}
+func (b *CommandBuilder) AbstractFile(name string) *CommandBuilder {
+	return b.add("-abstract_file", name)
+}
+func (b *CommandBuilder) BiblioFile(name string) *CommandBuilder {
+	return b.add("-biblio_file", name)
+}
+func (b *CommandBuilder) CopyrightFile(name string) *CommandBuilder {
+	return b.add("-copyright_file", name)
+}
+
+// VolumeDate sets a volume descriptor date: kind is c, m, x, f or uuid
+func (b *CommandBuilder) VolumeDate(kind, timestring string) *CommandBuilder {
+	return b.add("-volume_date", kind, timestring)
+}
+
 func (b *CommandBuilder) RockRidge(on bool) *CommandBuilder {
 	if on {
 		return b.add("-rockridge", "on")
@@ -80,17 +114,85 @@ func (b *CommandBuilder) Zisofs(on bool) *CommandBuilder {
 	}
 	return b.add("-zisofs", "off")
 }
+func (b *CommandBuilder) SplitSize(bytes int64) *CommandBuilder {
+	return b.add("-split_size", strconv.FormatInt(bytes, 10))
+}
 func (b *CommandBuilder) ForBackup() *CommandBuilder { return b.add("-for_backup") }
+func (b *CommandBuilder) Hardlinks(on bool) *CommandBuilder {
+	if on {
+		return b.add("-hardlinks", "on")
+	}
+	return b.add("-hardlinks", "off")
+}
+func (b *CommandBuilder) Follow(occasions string) *CommandBuilder {
+	return b.add("-follow", occasions)
+}
 
 // File operations
 func (b *CommandBuilder) Map(source, dest string) *CommandBuilder {
 	return b.add("-map", source, dest)
 }
+func (b *CommandBuilder) MapSingle(source, dest string) *CommandBuilder {
+	return b.add("-map_single", source, dest)
+}
+func (b *CommandBuilder) Mkdir(paths ...string) *CommandBuilder {
+	return b.addList([]string{"-mkdir"}, paths)
+}
 func (b *CommandBuilder) Add(paths ...string) *CommandBuilder {
-	args := []string{"-add"}
-	args = append(args, paths...)
-	args = append(args, "--")
-	return b.add(args...)
+	return b.addList([]string{"-add"}, paths)
+}
+func (b *CommandBuilder) RmR(paths ...string) *CommandBuilder {
+	return b.addList([]string{"-rm_r"}, paths)
+}
+func (b *CommandBuilder) Rmdir(paths ...string) *CommandBuilder {
+	return b.addList([]string{"-rmdir"}, paths)
+}
+
+// Changes of nodes loaded from an existing image
+func (b *CommandBuilder) Mv(source, dest string) *CommandBuilder {
+	return b.addList([]string{"-mv"}, []string{source, dest})
+}
+func (b *CommandBuilder) CpRx(source, dest string) *CommandBuilder {
+	return b.addList([]string{"-cp_rx"}, []string{source, dest})
+}
+func (b *CommandBuilder) BootImage(form, treatment string) *CommandBuilder {
+	return b.add("-boot_image", form, treatment)
+}
+
+// Incremental update of a loaded session
+func (b *CommandBuilder) UpdateR(source, dest string) *CommandBuilder {
+	return b.add("-update_r", source, dest)
+}
+func (b *CommandBuilder) NotPaths(paths ...string) *CommandBuilder {
+	return b.addList([]string{"-not_paths"}, paths)
+}
+func (b *CommandBuilder) DiskDevIno(mode string) *CommandBuilder {
+	return b.add("-disk_dev_ino", mode)
+}
+
+// Inspection of the loaded image
+func (b *CommandBuilder) FindExec(isoPath, action string) *CommandBuilder {
+	return b.add("-find", isoPath, "-exec", action, "--")
+}
+
+// Metadata overrides of nodes in the image
+func (b *CommandBuilder) ChmodR(mode string, paths ...string) *CommandBuilder {
+	return b.addList([]string{"-chmod_r", mode}, paths)
+}
+func (b *CommandBuilder) ChownR(uid string, paths ...string) *CommandBuilder {
+	return b.addList([]string{"-chown_r", uid}, paths)
+}
+func (b *CommandBuilder) ChgrpR(gid string, paths ...string) *CommandBuilder {
+	return b.addList([]string{"-chgrp_r", gid}, paths)
+}
+func (b *CommandBuilder) AlterDateR(kind, timestring string, paths ...string) *CommandBuilder {
+	return b.addList([]string{"-alter_date_r", kind, timestring}, paths)
+}
+func (b *CommandBuilder) Hide(state string, paths ...string) *CommandBuilder {
+	return b.addList([]string{"-hide", state}, paths)
+}
+func (b *CommandBuilder) SortWeight(weight int, path string) *CommandBuilder {
+	return b.add("-sort_weight", strconv.Itoa(weight), path)
 }
 
 // Write operations
@@ -157,6 +259,7 @@ func (b *CommandBuilder) OsirroX(mode string) *CommandBuilder { return b.add("-o
 func (b *CommandBuilder) Extract(isoPath, diskPath string) *CommandBuilder {
 	return b.add("-extract", isoPath, diskPath)
 }
+func (b *CommandBuilder) Overwrite(mode string) *CommandBuilder { return b.add("-overwrite", mode) }
 
 // Error handling
 func (b *CommandBuilder) AbortOn(severity string) *CommandBuilder {
@@ -170,3 +273,16 @@ func (b *CommandBuilder) ReportAbout(severity string) *CommandBuilder {
 func (b *CommandBuilder) Build() []string {
 	return b.args
 }
+
+// Commands returns the arguments grouped by command, each group starting with the command name
+func (b *CommandBuilder) Commands() [][]string {
+	cmds := make([][]string, len(b.starts))
+	for i, start := range b.starts {
+		end := len(b.args)
+		if i+1 < len(b.starts) {
+			end = b.starts[i+1]
+		}
+		cmds[i] = b.args[start:end]
+	}
+	return cmds
+}
@@ -81,6 +81,59 @@ func TestMap(t *testing.T) {
 		[]string{"-map", "/home/user/data", "/data"})
 }
 
+func TestMapSingle(t *testing.T) {
+	assertArgs(t, NewCommand().MapSingle("/home/user/data", "/data").Build(),
+		[]string{"-map_single", "/home/user/data", "/data"})
+}
+
+func TestMkdir(t *testing.T) {
+	assertArgs(t, NewCommand().Mkdir("/empty", "/other").Build(),
+		[]string{"-mkdir", "/empty", "/other", "--"})
+}
+
+func TestMetadataOverrides(t *testing.T) {
+	args := NewCommand().
+		ChmodR("go-w", "/docs").
+		ChownR("0", "/docs").
+		ChgrpR("users", "/docs", "/photos").
+		AlterDateR("m", "=1700000000", "/docs").
+		Hide("joliet:hfsplus", "/autorun.inf").
+		Build()
+	assertArgs(t, args, []string{
+		"-chmod_r", "go-w", "/docs", "--",
+		"-chown_r", "0", "/docs", "--",
+		"-chgrp_r", "users", "/docs", "/photos", "--",
+		"-alter_date_r", "m", "=1700000000", "/docs", "--",
+		"-hide", "joliet:hfsplus", "/autorun.inf", "--",
+	})
+}
+
+func TestVolumeDescriptor(t *testing.T) {
+	args := NewCommand().
+		VolsetID("ARCHIVE").
+		PreparerID("Backup team").
+		AbstractFile("ABSTRACT.TXT").
+		BiblioFile("BIBLIO.TXT").
+		CopyrightFile("COPYING").
+		VolumeDate("x", "=1900000000").
+		VolumeDate("uuid", "2026101812000000").
+		Build()
+	assertArgs(t, args, []string{
+		"-volset_id", "ARCHIVE",
+		"-preparer_id", "Backup team",
+		"-abstract_file", "ABSTRACT.TXT",
+		"-biblio_file", "BIBLIO.TXT",
+		"-copyright_file", "COPYING",
+		"-volume_date", "x", "=1900000000",
+		"-volume_date", "uuid", "2026101812000000",
+	})
+}
+
+func TestSortWeight(t *testing.T) {
+	args := NewCommand().SortWeight(100, "/autorun.inf").SortWeight(-3, "/video").Build()
+	assertArgs(t, args, []string{"-sort_weight", "100", "/autorun.inf", "-sort_weight", "-3", "/video"})
+}
+
 func TestCheckMedia_WithOpts(t *testing.T) {
 	opts := map[string]string{
 		"use":     "outdev",
@@ -131,6 +184,21 @@ func TestFluentChain(t *testing.T) {
 	assertArgs(t, args, expected)
 }
 
+func TestCommands(t *testing.T) {
+	cmds := NewCommand().
+		Device("/dev/sr0").
+		Map("/a", "/b").
+		Commit().
+		Commands()
+
+	if len(cmds) != 3 {
+		t.Fatalf("ожидалось 3 команды, получили: %v", cmds)
+	}
+	assertArgs(t, cmds[0], []string{"-dev", "/dev/sr0"})
+	assertArgs(t, cmds[1], []string{"-map", "/a", "/b"})
+	assertArgs(t, cmds[2], []string{"-commit"})
+}
+
 func TestDummy_On(t *testing.T) {
 	assertArgs(t, NewCommand().Dummy(true).Build(), []string{"-dummy", "on"})
 }
@@ -167,3 +235,44 @@ func TestStdioOutDevice(t *testing.T) {
 	assertArgs(t, NewCommand().StdioOutDevice("/tmp/output.iso").Build(),
 		[]string{"-outdev", "stdio:/tmp/output.iso"})
 }
+
+func TestLoad(t *testing.T) {
+	cmd := NewCommand().Load("session", "3").InDevice("/dev/sr0")
+	assertArgs(t, cmd.Build(), []string{"-load", "session", "3", "-indev", "/dev/sr0"})
+}
+
+func TestOverwrite(t *testing.T) {
+	cmd := NewCommand().OsirroX("on").Overwrite("off").Extract("/docs", "/tmp/docs")
+	assertArgs(t, cmd.Build(), []string{"-osirrox", "on", "-overwrite", "off", "-extract", "/docs", "/tmp/docs"})
+}
+
+func TestReadFS(t *testing.T) {
+	assertArgs(t, NewCommand().ReadFS("norock").Build(), []string{"-read_fs", "norock"})
+}
+
+func TestRmR(t *testing.T) {
+	assertArgs(t, NewCommand().RmR("/old", "/tmp").Build(), []string{"-rm_r", "/old", "/tmp", "--"})
+}
+
+func TestModifyLoadedImage(t *testing.T) {
+	cmd := NewCommand().BootImage("any", "keep").Mv("/old name", "/new").CpRx("/new/a", "/b").Rmdir("/empty")
+	assertArgs(t, cmd.Build(), []string{
+		"-boot_image", "any", "keep",
+		"-mv", "/old name", "/new", "--",
+		"-cp_rx", "/new/a", "/b", "--",
+		"-rmdir", "/empty", "--",
+	})
+}
+
+func TestUpdateR(t *testing.T) {
+	cmd := NewCommand().DiskDevIno("on").NotPaths("/home/me/docs/skip").UpdateR("/home/me/docs", "/docs")
+	assertArgs(t, cmd.Build(), []string{
+		"-disk_dev_ino", "on",
+		"-not_paths", "/home/me/docs/skip", "--",
+		"-update_r", "/home/me/docs", "/docs",
+	})
+}
+
+func TestFindExec(t *testing.T) {
+	assertArgs(t, NewCommand().FindExec("/", "lsdl").Build(), []string{"-find", "/", "-exec", "lsdl", "--"})
+}
@@ -0,0 +1,34 @@
+package xorriso
+
+import "strings"
+
+// SplitGraftPoint splits an mkisofs graft point "dest=src" at the first unescaped '='.
+// Returns unescaped parts and false if the text is not a graft point.
+func SplitGraftPoint(text string) (dest, src string, ok bool) {
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '=':
+			return unescapeGraftPath(text[:i]), unescapeGraftPath(text[i+1:]), true
+		}
+	}
+	return "", unescapeGraftPath(text), false
+}
+
+// EscapeGraftPath escapes '\' and '=' so the path can be used in a graft point
+func EscapeGraftPath(p string) string {
+	p = strings.ReplaceAll(p, `\`, `\\`)
+	return strings.ReplaceAll(p, "=", `\=`)
+}
+
+func unescapeGraftPath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		if p[i] == '\\' && i+1 < len(p) {
+			i++
+		}
+		b.WriteByte(p[i])
+	}
+	return b.String()
+}
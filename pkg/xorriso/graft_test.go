@@ -0,0 +1,33 @@
+package xorriso
+
+import "testing"
+
+func TestSplitGraftPoint(t *testing.T) {
+	tests := []struct {
+		text    string
+		dest    string
+		src     string
+		isGraft bool
+	}{
+		{"/docs=/home/user/docs", "/docs", "/home/user/docs", true},
+		{`/a\=b=/src/a=b`, "/a=b", "/src/a=b", true},
+		{`/back\\slash=/src`, `/back\slash`, "/src", true},
+		{"/home/user/file.txt", "", "/home/user/file.txt", false},
+	}
+	for _, tt := range tests {
+		dest, src, ok := SplitGraftPoint(tt.text)
+		if dest != tt.dest || src != tt.src || ok != tt.isGraft {
+			t.Errorf("SplitGraftPoint(%q) = (%q, %q, %v), want (%q, %q, %v)",
+				tt.text, dest, src, ok, tt.dest, tt.src, tt.isGraft)
+		}
+	}
+}
+
+func TestEscapeGraftPath_RoundTrip(t *testing.T) {
+	for _, p := range []string{"/plain", "/a=b", `/back\slash`, `/both\=`} {
+		dest, _, ok := SplitGraftPoint(EscapeGraftPath(p) + "=/src")
+		if !ok || dest != p {
+			t.Errorf("round trip of %q = %q (graft=%v)", p, dest, ok)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package xorriso
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+// Ограничения пространств имён образа (ECMA-119, Joliet, Rock Ridge, HFS+)
+const (
+	ISO9660MaxDepth      = 8   // уровней каталогов от корня
+	ISO9660MaxPathLen    = 255 // символов в полном пути
+	ISO9660Level4NameLen = 207 // ISO 9660:1999
+	JolietMaxNameLen     = 64  // символов UCS-2
+	JolietMaxPathLen     = 240 // символов UCS-2 в полном пути
+	RockRidgeMaxNameLen  = 255 // байт
+	HFSPlusMaxNameLen    = 255 // единиц UTF-16
+)
+
+// Ограничения размера файлов
+const (
+	// ISO9660MaxExtentSize is the largest file extent libisofs writes. Bigger files
+	// need several extents (ISO level 3) or splitting into parts with -split_size.
+	ISO9660MaxExtentSize int64 = 0xFFFFF800
+	// DefaultSplitSize keeps every part below the 4 GiB limit of FAT32 as well
+	DefaultSplitSize int64 = 4095 << 20
+)
+
+// ISO9660Name simulates how libisofs maps a file name to the ISO 9660 namespace
+// at the given level (1–3; 4 means ISO 9660:1999). Level 0 is treated as level 1.
+func ISO9660Name(name string, level int, isDir bool) string {
+	if level >= 4 {
+		return truncateRunes(name, ISO9660Level4NameLen)
+	}
+
+	base, ext := name, ""
+	if dot := strings.LastIndexByte(name, '.'); dot > 0 && !isDir {
+		base, ext = name[:dot], name[dot+1:]
+	}
+	base, ext = dChars(base), dChars(ext)
+
+	if level <= 1 {
+		base = truncateRunes(base, 8)
+		if isDir || ext == "" {
+			return base
+		}
+		return base + "." + truncateRunes(ext, 3)
+	}
+
+	// Уровни 2 и 3: до 31 символа вместе с точкой
+	if isDir || ext == "" {
+		return truncateRunes(base, 31)
+	}
+	ext = truncateRunes(ext, 30-min(len(base), 1))
+	return truncateRunes(base, 30-len(ext)) + "." + ext
+}
+
+// JolietName simulates the Joliet mapping: characters forbidden by Joliet and
+// characters outside UCS-2 become '_', names are cut to 64 characters keeping the extension.
+func JolietName(name string, isDir bool) string {
+	var b strings.Builder
+	for _, r := range name {
+		if strings.ContainsRune(`*/:;?\`, r) || r < 0x20 || r > 0xFFFF {
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+	mapped := b.String()
+	if len([]rune(mapped)) <= JolietMaxNameLen {
+		return mapped
+	}
+	return TruncateName(mapped, JolietMaxNameLen, isDir)
+}
+
+// TruncateName shortens a name to maxLen characters, keeping the file extension
+func TruncateName(name string, maxLen int, isDir bool) string {
+	runes := []rune(name)
+	if len(runes) <= maxLen {
+		return name
+	}
+	if dot := strings.LastIndexByte(name, '.'); dot > 0 && !isDir {
+		ext := []rune(name[dot:])
+		if len(ext) < maxLen {
+			return string([]rune(name[:dot])[:maxLen-len(ext)]) + string(ext)
+		}
+	}
+	return string(runes[:maxLen])
+}
+
+// UTF16Len returns the name length in UTF-16 code units (Joliet, HFS+)
+func UTF16Len(name string) int {
+	return len(utf16.Encode([]rune(name)))
+}
+
+// dChars переводит имя в d-символы ISO 9660: A-Z, 0-9 и '_'
+func dChars(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(s) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
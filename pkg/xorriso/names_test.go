@@ -0,0 +1,60 @@
+package xorriso
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestISO9660Name(t *testing.T) {
+	tests := []struct {
+		name     string
+		level    int
+		isDir    bool
+		expected string
+	}{
+		{"readme.txt", 1, false, "README.TXT"},
+		{"holiday photos.jpeg", 1, false, "HOLIDAY_.JPE"},
+		{"my.folder", 1, true, "MY_FOLDE"},
+		{"Makefile", 1, false, "MAKEFILE"},
+		{"holiday photos.jpeg", 2, false, "HOLIDAY_PHOTOS.JPEG"},
+		{"a very long file name that does not fit.txt", 3, false, "A_VERY_LONG_FILE_NAME_THAT_.TXT"},
+		{"Привет.txt", 2, false, "______.TXT"},
+		{"Mixed Case.txt", 4, false, "Mixed Case.txt"},
+	}
+	for _, tt := range tests {
+		got := ISO9660Name(tt.name, tt.level, tt.isDir)
+		if got != tt.expected {
+			t.Errorf("ISO9660Name(%q, %d, %v) = %q, want %q", tt.name, tt.level, tt.isDir, got, tt.expected)
+		}
+		if tt.level == 3 && len(got) > 31 {
+			t.Errorf("level 3 name %q is longer than 31 characters", got)
+		}
+	}
+}
+
+func TestJolietName(t *testing.T) {
+	if got := JolietName("what? yes: no*.txt", false); got != "what_ yes_ no_.txt" {
+		t.Errorf("forbidden characters: got %q", got)
+	}
+	if got := JolietName("emoji 😀.txt", false); got != "emoji _.txt" {
+		t.Errorf("non-BMP character: got %q", got)
+	}
+
+	long := strings.Repeat("x", 70) + ".jpeg"
+	got := JolietName(long, false)
+	if len([]rune(got)) != JolietMaxNameLen || !strings.HasSuffix(got, ".jpeg") {
+		t.Errorf("long name: got %q (%d chars)", got, len([]rune(got)))
+	}
+}
+
+func TestTruncateName(t *testing.T) {
+	if got := TruncateName("abcdef.txt", 8, false); got != "abcd.txt" {
+		t.Errorf("file: got %q", got)
+	}
+	if got := TruncateName("abc.defghij", 8, true); got != "abc.defg" {
+		t.Errorf("dir: got %q", got)
+	}
+	if got := TruncateName("short", 8, false); got != "short" {
+		t.Errorf("short: got %q", got)
+	}
+}
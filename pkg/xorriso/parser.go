@@ -1,9 +1,12 @@
 package xorriso
 
 import (
+	"fmt"
+	"path"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"xorriso-ui/pkg/models"
 )
@@ -224,3 +227,163 @@ func ParseMediaSummary(lines []string) int {
 	}
 	return 0
 }
+
+// ParseLsdl parses lines printed by -lsdl or -find ... -exec lsdl:
+// "-rw-r--r--    1 1000     1000         1234 Jan  2 12:00 '/dir/file'".
+// Paths are quoted by xorriso; symbolic links end with "-> 'target'".
+// Times are printed like ls -l: minutes for recent files, only the date for older ones.
+// A line break inside a name splits the record into several lines; they are joined back.
+var (
+	lsdlRe      = regexp.MustCompile(`(?s)^([-dlbcps?])([-rwxsStT]{9})\S*\s+\d+\s+(\S+)\s+(\S+)\s+(\d+)(?:,\s*\d+)?\s+(\S+\s+\d+\s+\S+)\s+(.+)$`)
+	lsdlStartRe = regexp.MustCompile(`^[-dlbcps?][-rwxsStT]{9}`)
+)
+
+func ParseLsdl(lines []string) []models.ISOFile {
+	return parseLsdlAt(lines, time.Now())
+}
+
+// parseLsdlAt разбирает листинг; now нужен для года у недавних файлов
+func parseLsdlAt(lines []string, now time.Time) []models.ISOFile {
+	var files []models.ISOFile
+	for _, line := range joinQuotedLines(lines, lsdlStartRe) {
+		matches := lsdlRe.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		words, err := SplitOptionsLine(matches[7])
+		if err != nil || len(words) == 0 {
+			continue
+		}
+		size, _ := strconv.ParseInt(matches[5], 10, 64)
+		f := models.ISOFile{
+			Path:    words[0],
+			Name:    path.Base(words[0]),
+			Mode:    matches[1] + matches[2],
+			Owner:   matches[3],
+			Group:   matches[4],
+			Size:    size,
+			ModTime: parseLsTime(matches[6], now),
+		}
+		switch matches[1] {
+		case "d":
+			f.IsDir = true
+			f.Size = 0
+		case "l":
+			f.Type = models.EntryTypeSymlink
+			if len(words) >= 3 && words[1] == "->" {
+				f.Target = words[2]
+			}
+		case "-":
+		default:
+			f.Type = models.EntryTypeSpecial
+			f.Size = 0
+		}
+		files = append(files, f)
+	}
+	return files
+}
+
+// parseLsTime переводит время ls -l ("Oct 18 12:00" или "Jan  2  2024") в миллисекунды Unix.
+// Время без года относится к последним 12 месяцам; 0 — если формат не распознан.
+func parseLsTime(text string, now time.Time) int64 {
+	fields := strings.Fields(text)
+	if len(fields) != 3 {
+		return 0
+	}
+	if !strings.Contains(fields[2], ":") {
+		t, err := time.ParseInLocation("Jan 2 2006", strings.Join(fields, " "), now.Location())
+		if err != nil {
+			return 0
+		}
+		return t.UnixMilli()
+	}
+	t, err := time.ParseInLocation("2006 Jan 2 15:04", fmt.Sprintf("%d %s", now.Year(), strings.Join(fields, " ")), now.Location())
+	if err != nil {
+		return 0
+	}
+	if t.After(now.AddDate(0, 0, 1)) {
+		t = t.AddDate(-1, 0, 0)
+	}
+	return t.UnixMilli()
+}
+
+// ParseGetMD5 parses lines printed by -find ... -exec get_md5: the MD5 recorded in
+// the image followed by the file path. Files without a recorded MD5 are not listed.
+var (
+	getMD5Re      = regexp.MustCompile(`(?s)^([0-9a-fA-F]{32})\s+(.+)$`)
+	getMD5StartRe = regexp.MustCompile(`^\s*[0-9a-fA-F]{32}\s`)
+)
+
+func ParseGetMD5(lines []string) map[string]string {
+	sums := make(map[string]string)
+	for _, line := range joinQuotedLines(lines, getMD5StartRe) {
+		matches := getMD5Re.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil {
+			continue
+		}
+		sums[unquoteListedPath(matches[2])] = strings.ToLower(matches[1])
+	}
+	return sums
+}
+
+// ParseReportLBA parses lines printed by -find ... -exec report_lba:
+// "File data lba:  0 ,       33 ,        1 ,       10 , '/dir/file'".
+// Only the first extent of each file is kept.
+var (
+	reportLBARe      = regexp.MustCompile(`(?s)File data lba:\s*(\d+)\s*,\s*(\d+)\s*,\s*\d+\s*,\s*\d+\s*,\s*(.+)$`)
+	reportLBAStartRe = regexp.MustCompile(`^\s*File data lba:`)
+)
+
+func ParseReportLBA(lines []string) map[string]int64 {
+	lbas := make(map[string]int64)
+	for _, line := range joinQuotedLines(lines, reportLBAStartRe) {
+		matches := reportLBARe.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil || matches[1] != "0" {
+			continue
+		}
+		lba, _ := strconv.ParseInt(matches[2], 10, 64)
+		lbas[unquoteListedPath(matches[3])] = lba
+	}
+	return lbas
+}
+
+// joinQuotedLines склеивает записи, разорванные переводом строки внутри имени в кавычках.
+// xorriso выводит такой перевод строки как есть, и -pkt_output начинает на нём новый пакет.
+// Продолжать можно только строку, похожую на начало записи (start), чтобы случайный
+// апостроф в сообщении не съел следующие строки.
+func joinQuotedLines(lines []string, start *regexp.Regexp) []string {
+	joined := make([]string, 0, len(lines))
+	var quote byte
+	for _, line := range lines {
+		line = strings.TrimRight(line, "\n")
+		if quote != 0 {
+			joined[len(joined)-1] += "\n" + line
+		} else {
+			joined = append(joined, line)
+			if !start.MatchString(line) {
+				continue
+			}
+		}
+		for i := 0; i < len(line); i++ {
+			switch c := line[i]; {
+			case quote != 0:
+				if c == quote {
+					quote = 0
+				}
+			case c == '\'' || c == '"':
+				quote = c
+			}
+		}
+	}
+	return joined
+}
+
+// unquoteListedPath снимает кавычки xorriso с пути; путь без кавычек возвращается как есть
+func unquoteListedPath(text string) string {
+	if strings.HasPrefix(text, "'") || strings.HasPrefix(text, `"`) {
+		if words, err := SplitOptionsLine(text); err == nil && len(words) == 1 {
+			return words[0]
+		}
+	}
+	return text
+}
@@ -3,6 +3,7 @@ package xorriso
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"xorriso-ui/pkg/models"
 )
@@ -394,3 +395,132 @@ func TestParseMediaSummary_Zero(t *testing.T) {
 		t.Errorf("expected 0 sessions, got %d", n)
 	}
 }
+
+// --- ParseLsdl ---
+
+func TestParseLsdl(t *testing.T) {
+	lines := []string{
+		"drwxr-xr-x    1 0        0               0 Oct 18 12:00 '/'",
+		"drwxr-xr-x    1 1000     1000            0 Oct 18 12:00 '/docs'",
+		"-rw-r--r--    1 1000     1000         1234 Jan  2  2024 '/docs/report.pdf'",
+		`-rw-r--r--    1 1000     1000            5 Oct 18 12:00 '/docs/it'"'"'s here.txt'`,
+		"lrwxrwxrwx    1 1000     1000           10 Oct 18 12:00 '/docs/latest' -> 'report.pdf'",
+		"crw-rw----    1 0        6          8,   0 Oct 18 12:00 '/dev/sda'",
+		"xorriso : NOTE : not a listing line",
+	}
+	files := ParseLsdl(lines)
+	if len(files) != 6 {
+		t.Fatalf("len(files) = %d, want 6: %+v", len(files), files)
+	}
+	if f := files[1]; f.Path != "/docs" || !f.IsDir || f.Name != "docs" {
+		t.Errorf("dir = %+v", f)
+	}
+	if f := files[2]; f.Path != "/docs/report.pdf" || f.Size != 1234 || f.IsDir || f.Type != "" || f.Mode != "-rw-r--r--" {
+		t.Errorf("file = %+v", f)
+	}
+	if f := files[3]; f.Path != "/docs/it's here.txt" || f.Size != 5 {
+		t.Errorf("quoted name = %+v", f)
+	}
+	if f := files[4]; f.Type != models.EntryTypeSymlink || f.Target != "report.pdf" {
+		t.Errorf("link = %+v", f)
+	}
+	if f := files[5]; f.Type != models.EntryTypeSpecial || f.Path != "/dev/sda" {
+		t.Errorf("device = %+v", f)
+	}
+	if f := files[2]; f.Owner != "1000" || f.Group != "1000" || f.ModTime == 0 {
+		t.Errorf("owner and time = %+v", f)
+	}
+}
+
+func TestParseLsTime(t *testing.T) {
+	now := time.Date(2026, time.March, 10, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		text string
+		want time.Time
+	}{
+		{"Mar  9 12:30", time.Date(2026, time.March, 9, 12, 30, 0, 0, time.UTC)},
+		// Дата позже текущей — файл прошлого года
+		{"Oct 18 12:00", time.Date(2025, time.October, 18, 12, 0, 0, 0, time.UTC)},
+		{"Jan  2  2024", time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := parseLsTime(tt.text, now); got != tt.want.UnixMilli() {
+			t.Errorf("parseLsTime(%q) = %v, want %v", tt.text, time.UnixMilli(got).UTC(), tt.want)
+		}
+	}
+	if got := parseLsTime("yesterday", now); got != 0 {
+		t.Errorf("unknown format = %d", got)
+	}
+}
+
+// --- ParseGetMD5 / ParseReportLBA ---
+
+func TestParseLsdl_LineBreakInName(t *testing.T) {
+	lines := []string{
+		"xorriso : NOTE : can't open '/x",
+		"-rw-r--r--    1 1000     1000            3 Oct 18 12:00 '/docs/two",
+		"lines.txt'",
+		"-rw-r--r--    1 1000     1000            4 Oct 18 12:00 '/docs/\"quoted\" '\"'\"'name'\"'\"''",
+		"lrwxrwxrwx    1 1000     1000            1 Oct 18 12:00 '/docs/link' -> 'a",
+		"",
+		"b'",
+		"-rw-r--r--    1 1000     1000            5 Oct 18 12:00 '/docs/last.txt'",
+	}
+	files := ParseLsdl(lines)
+	if len(files) != 4 {
+		t.Fatalf("len(files) = %d, want 4: %+v", len(files), files)
+	}
+	if f := files[0]; f.Path != "/docs/two\nlines.txt" || f.Name != "two\nlines.txt" || f.Size != 3 {
+		t.Errorf("two lines = %+v", f)
+	}
+	if f := files[1]; f.Path != `/docs/"quoted" 'name'` {
+		t.Errorf("quotes = %q", f.Path)
+	}
+	if f := files[2]; f.Target != "a\n\nb" {
+		t.Errorf("link target = %q", f.Target)
+	}
+	if f := files[3]; f.Path != "/docs/last.txt" {
+		t.Errorf("last = %+v", f)
+	}
+}
+
+func TestParseGetMD5(t *testing.T) {
+	sums := ParseGetMD5([]string{
+		"D41D8CD98F00B204E9800998ECF8427E  '/empty.txt'",
+		"900150983cd24fb0d6963f7d28e17f72  /abc.txt",
+		"xorriso : NOTE : no MD5 recorded for '/other'",
+	})
+	if len(sums) != 2 || sums["/empty.txt"] != "d41d8cd98f00b204e9800998ecf8427e" || sums["/abc.txt"] == "" {
+		t.Errorf("sums = %v", sums)
+	}
+}
+
+func TestParseReportLBA(t *testing.T) {
+	lbas := ParseReportLBA([]string{
+		"File data lba:  0 ,       33 ,        1 ,       10 , '/docs/a.txt'",
+		"File data lba:  0 ,       34 ,  2097151 , 4294965248 , '/big.bin'",
+		"File data lba:  1 ,  2097185 ,        1 ,      100 , '/big.bin'",
+	})
+	if len(lbas) != 2 || lbas["/docs/a.txt"] != 33 || lbas["/big.bin"] != 34 {
+		t.Errorf("lbas = %v", lbas)
+	}
+}
+
+func TestParseGetMD5AndReportLBA_LineBreakInName(t *testing.T) {
+	sums := ParseGetMD5([]string{
+		"d41d8cd98f00b204e9800998ecf8427e  '/a",
+		"b.txt'",
+		"900150983cd24fb0d6963f7d28e17f72  '/c.txt'",
+	})
+	if len(sums) != 2 || sums["/a\nb.txt"] != "d41d8cd98f00b204e9800998ecf8427e" || sums["/c.txt"] == "" {
+		t.Errorf("sums = %q", sums)
+	}
+	lbas := ParseReportLBA([]string{
+		"File data lba:  0 ,       33 ,        1 ,       10 , '/a",
+		"b.txt'",
+		"File data lba:  0 ,       34 ,        1 ,       10 , '/c.txt'",
+	})
+	if len(lbas) != 2 || lbas["/a\nb.txt"] != 33 || lbas["/c.txt"] != 34 {
+		t.Errorf("lbas = %v", lbas)
+	}
+}
@@ -1,6 +1,7 @@
 package xorriso
 
 import (
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
@@ -22,6 +23,9 @@ var updateFifoRe = regexp.MustCompile(`fifo\s+(\d+)%`)
 var updateSpeedRe = regexp.MustCompile(`(\d+\.?\d*x[A-Z]+|\d+\.?\d*\s*[kMG]B/s)`)
 var updateETARe = regexp.MustCompile(`remaining\s+(\d+:\d+:\d+|\d+:\d+)`)
 
+// Извлечение с диска: "xorriso : UPDATE : 42 files restored ( 12.5m) in 3 s"
+var restoredRe = regexp.MustCompile(`files restored\s*\(\s*(\d+\.?\d*)([kmgt]?)\)`)
+
 // ParsePacifierLine tries to extract progress from an xorriso info line
 func ParsePacifierLine(line string) (Progress, bool) {
 	if !strings.Contains(line, "UPDATE") && !strings.Contains(line, "Writing:") && !strings.Contains(line, "Verifying") {
@@ -32,6 +36,8 @@ func ParsePacifierLine(line string) (Progress, bool) {
 
 	// Determine phase
 	switch {
+	case strings.Contains(line, "restored"):
+		p.Phase = "extracting"
 	case strings.Contains(line, "Writing"):
 		p.Phase = "writing"
 	case strings.Contains(line, "Blanking"), strings.Contains(line, "Formatting"):
@@ -62,5 +68,12 @@ func ParsePacifierLine(line string) (Progress, bool) {
 		p.ETA = m[1]
 	}
 
+	// Извлечённый объём; процент считает вызывающий по известному общему размеру
+	if m := restoredRe.FindStringSubmatch(line); m != nil {
+		value, _ := strconv.ParseFloat(m[1], 64)
+		// Пустой суффикс — байты, k/m/g/t — степени 1024
+		p.BytesWritten = int64(value * math.Pow(1024, float64(strings.Index(" kmgt", m[2]))))
+	}
+
 	return p, true
 }
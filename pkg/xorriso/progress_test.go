@@ -15,6 +15,7 @@ func TestParsePacifierLine(t *testing.T) {
 		wantSpeed   string
 		wantFIFO    int
 		wantETA     string
+		wantBytes   int64
 	}{
 		{
 			name:        "Writing с процентом, скоростью и fifo",
@@ -73,6 +74,13 @@ func TestParsePacifierLine(t *testing.T) {
 			wantPercent: 60.0,
 			wantETA:     "0:05:30",
 		},
+		{
+			name:      "Извлечение — объём без процента",
+			line:      "xorriso : UPDATE :      42 files restored ( 12.5m) in 3 s = 4.1xD",
+			wantOK:    true,
+			wantPhase: "extracting",
+			wantBytes: 13107200,
+		},
 		{
 			name:   "Обычная строка — не прогресс",
 			line:   "xorriso : NOTE : some informational message",
@@ -115,6 +123,10 @@ func TestParsePacifierLine(t *testing.T) {
 			if tt.wantETA != "" && p.ETA != tt.wantETA {
 				t.Errorf("ETA = %q, хотели %q", p.ETA, tt.wantETA)
 			}
+
+			if tt.wantBytes != 0 && p.BytesWritten != tt.wantBytes {
+				t.Errorf("BytesWritten = %d, хотели %d", p.BytesWritten, tt.wantBytes)
+			}
 		})
 	}
 }
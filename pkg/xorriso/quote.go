@@ -0,0 +1,171 @@
+package xorriso
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ShellQuote quotes a single argument for a POSIX shell.
+// Arguments made only of safe characters are returned unchanged.
+func ShellQuote(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+	if strings.IndexFunc(arg, needsQuote) < 0 {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// ShellJoin quotes every argument and joins them with spaces
+func ShellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = ShellQuote(a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// OptionQuote quotes a single argument for an xorriso -options_from_file line.
+// xorriso takes text between quotes literally and has no escape character
+// (unless -backslash_codes is enabled), so embedded quotes are written as
+// adjacent pieces quoted with the other kind of quotation mark.
+func OptionQuote(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+	if strings.IndexFunc(arg, needsQuote) < 0 {
+		return arg
+	}
+	if !strings.Contains(arg, "'") {
+		return "'" + arg + "'"
+	}
+	if !strings.Contains(arg, `"`) {
+		return `"` + arg + `"`
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'"'"'`) + "'"
+}
+
+// OptionJoin quotes every argument for an options file and joins them with spaces
+func OptionJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = OptionQuote(a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// SplitShellWords splits a POSIX shell command line into words.
+// Supports single and double quotes and backslash escapes; no expansions are performed.
+func SplitShellWords(line string) ([]string, error) {
+	commands, err := splitCommands(line, true)
+	return flatten(commands), err
+}
+
+// SplitOptionsLine splits an xorriso dialog / options file line into words.
+// Quoted text is taken literally, backslashes have no special meaning.
+func SplitOptionsLine(line string) ([]string, error) {
+	commands, err := splitCommands(line, false)
+	return flatten(commands), err
+}
+
+// SplitShellScript splits a shell script into simple commands.
+// Commands end at an unquoted line break or ';', comments start with '#'.
+func SplitShellScript(text string) ([][]string, error) {
+	return splitCommands(text, true)
+}
+
+// SplitOptionsFile splits an xorriso -options_from_file text into lines of words.
+// Lines starting with '#' and empty lines are skipped.
+func SplitOptionsFile(text string) ([][]string, error) {
+	return splitCommands(text, false)
+}
+
+func flatten(commands [][]string) []string {
+	var words []string
+	for _, c := range commands {
+		words = append(words, c...)
+	}
+	return words
+}
+
+func splitCommands(text string, shell bool) ([][]string, error) {
+	var commands [][]string
+	var words []string
+	var cur strings.Builder
+	inWord := false
+	lineStart := true
+	var quote byte
+
+	endWord := func() {
+		if inWord {
+			words = append(words, cur.String())
+			cur.Reset()
+			inWord = false
+		}
+	}
+	endCommand := func() {
+		endWord()
+		if len(words) > 0 {
+			commands = append(commands, words)
+			words = nil
+		}
+	}
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			switch {
+			case c == quote:
+				quote = 0
+			case shell && quote == '"' && c == '\\' && i+1 < len(text) && strings.IndexByte("\"\\$`\n", text[i+1]) >= 0:
+				i++
+				cur.WriteByte(text[i])
+			default:
+				cur.WriteByte(c)
+			}
+			continue
+		case c == '#' && !inWord && (shell || lineStart):
+			// Комментарий до конца строки
+			for i+1 < len(text) && text[i+1] != '\n' {
+				i++
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inWord = true
+		case shell && c == '\\' && i+1 < len(text):
+			i++
+			if text[i] != '\n' {
+				cur.WriteByte(text[i])
+				inWord = true
+			}
+			// Обратная косая черта перед переводом строки — продолжение команды
+			continue
+		case c == '\n' || (shell && c == ';'):
+			endCommand()
+			lineStart = true
+			continue
+		case c == ' ' || c == '\t' || c == '\r':
+			endWord()
+			continue
+		default:
+			cur.WriteByte(c)
+			inWord = true
+		}
+		lineStart = false
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	endCommand()
+	return commands, nil
+}
+
+func needsQuote(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	}
+	return !strings.ContainsRune("-_./=:,+@%", r)
+}
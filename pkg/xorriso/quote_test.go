@@ -0,0 +1,91 @@
+package xorriso
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"/dev/sr0", "/dev/sr0"},
+		{"stdio:/tmp/out.iso", "stdio:/tmp/out.iso"},
+		{"", "''"},
+		{"My Disc", "'My Disc'"},
+		{"it's", `'it'\''s'`},
+		{"$HOME", "'$HOME'"},
+		{"a\nb", "'a\nb'"},
+	}
+	for _, tt := range tests {
+		if got := ShellQuote(tt.input); got != tt.expected {
+			t.Errorf("ShellQuote(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestShellJoin(t *testing.T) {
+	got := ShellJoin([]string{"-volid", "My Disc", "-map", "/a b", "/c"})
+	want := "-volid 'My Disc' -map '/a b' /c"
+	if got != want {
+		t.Errorf("ShellJoin = %q, want %q", got, want)
+	}
+}
+
+func TestOptionQuote(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"/dev/sr0", "/dev/sr0"},
+		{"My Disc", "'My Disc'"},
+		{"it's", `"it's"`},
+		{`it's "x"`, `'it'"'"'s "x"'`},
+		{`back\slash`, `'back\slash'`},
+	}
+	for _, tt := range tests {
+		if got := OptionQuote(tt.input); got != tt.expected {
+			t.Errorf("OptionQuote(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestSplitShellWords(t *testing.T) {
+	got, err := SplitShellWords(`xorriso -volid 'My Disc' -map "/a \"b\"" /c\ d 'it'\''s'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"xorriso", "-volid", "My Disc", "-map", `/a "b"`, "/c d", "it's"}
+	if !slices.Equal(got, want) {
+		t.Errorf("SplitShellWords = %q, want %q", got, want)
+	}
+
+	if _, err := SplitShellWords(`-volid 'open`); err == nil {
+		t.Error("expected error for unterminated quote")
+	}
+}
+
+func TestSplitOptionsLine(t *testing.T) {
+	got, err := SplitOptionsLine(`-map 'C:\dir' "it's" 'a'"'"'b' ''`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"-map", `C:\dir`, "it's", "a'b", ""}
+	if !slices.Equal(got, want) {
+		t.Errorf("SplitOptionsLine = %q, want %q", got, want)
+	}
+}
+
+func TestQuote_RoundTrip(t *testing.T) {
+	args := []string{"plain", "with space", "it's", `"quoted"`, `mix 'a' "b"`, `back\slash`, "$VAR", ""}
+
+	shellWords, err := SplitShellWords(ShellJoin(args))
+	if err != nil || !slices.Equal(shellWords, args) {
+		t.Errorf("shell round trip = %q (%v), want %q", shellWords, err, args)
+	}
+	optionWords, err := SplitOptionsLine(OptionJoin(args))
+	if err != nil || !slices.Equal(optionWords, args) {
+		t.Errorf("options round trip = %q (%v), want %q", optionWords, err, args)
+	}
+}
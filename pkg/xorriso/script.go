@@ -0,0 +1,454 @@
+package xorriso
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"xorriso-ui/pkg/models"
+)
+
+// Script is the result of parsing an xorriso shell script or options file.
+// Project carries the volume ID and ISO/burn options; the file tree is described
+// by Mappings because turning them into entries needs access to the source files.
+type Script struct {
+	Project     *models.Project
+	Mappings    []Mapping
+	Metadata    map[string]models.EntryMetadata // переопределения атрибутов по пути в образе
+	SortWeights map[string]int                  // -sort_weight по пути в образе
+	Device      string                          // -dev / -outdev привода
+	OutputPath  string                          // -outdev stdio:<path> при создании ISO-файла
+	Warnings    []string
+}
+
+// Mapping is one file operation of a script in order of appearance
+type Mapping struct {
+	Source string // пусто для -mkdir
+	Dest   string
+	Single bool // -map_single: каталог без содержимого
+}
+
+// argEnd — число аргументов команды до конца списка "--"
+const argEnd = -1
+
+// scriptCommandArgs — число аргументов команд, которые понимает ParseScript.
+// Остальные команды разбираются эвристически: до следующего слова, начинающегося с "-".
+var scriptCommandArgs = map[string]int{
+	"-dev": 1, "-indev": 1, "-outdev": 1,
+	"-volid": 1, "-publisher": 1, "-application_id": 1, "-system_id": 1,
+	"-volset_id": 1, "-preparer_id": 1, "-abstract_file": 1, "-biblio_file": 1, "-copyright_file": 1,
+	"-volume_date": 2,
+	"-rockridge":   1, "-joliet": 1, "-udf": 1, "-hfsplus": 1, "-zisofs": 1,
+	"-md5": 1, "-iso_level": 1, "-for_backup": 0, "-hardlinks": 1, "-pathspecs": 1,
+	"-follow": 1, "-split_size": 1,
+	"-map": 2, "-map_single": 2, "-mkdir": argEnd, "-add": argEnd,
+	"-speed": 1, "-write_type": 1, "-padding": 1, "-dummy": 1, "-close": 1, "-stream_recording": 1,
+	"-commit": 0, "-end": 0, "-eject": 1, "-blank": 1, "-format": 1,
+	"-check_media": argEnd, "-check_md5": argEnd, "-check_md5_r": argEnd,
+	"-abort_on": 1, "-report_about": 1, "-return_with": 2, "-pkt_output": 1, "-pacifier": 1,
+	"-options_from_file": 1, "-chmod_r": argEnd, "-chown_r": argEnd, "-chgrp_r": argEnd,
+	"-alter_date_r": argEnd, "-hide": argEnd, "-sort_weight": 2, "-sort_weight_list": 1,
+}
+
+// scriptIgnored — служебные команды, не влияющие на проект
+var scriptIgnored = map[string]bool{
+	"-commit": true, "-end": true, "-abort_on": true, "-report_about": true,
+	"-return_with": true, "-pkt_output": true, "-pacifier": true,
+}
+
+// ParseScript parses a POSIX shell script that runs xorriso or an
+// -options_from_file text. Only the subset of commands emitted by CommandBuilder
+// is understood; everything else is skipped with a warning.
+func ParseScript(text string) (*Script, error) {
+	script := &Script{
+		Project: &models.Project{
+			// Значения по умолчанию самого xorriso
+			ISOOptions:  models.ISOOptions{RockRidge: true},
+			BurnOptions: models.BurnOptions{Speed: "auto", BurnMode: "auto"},
+		},
+		Metadata:    make(map[string]models.EntryMetadata),
+		SortWeights: make(map[string]int),
+	}
+	p := &scriptParser{script: script}
+
+	lines, isShell, err := splitScript(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script: %w", err)
+	}
+	for _, words := range lines {
+		if isShell {
+			if path.Base(words[0]) != "xorriso" {
+				if words[0] != "set" && words[0] != "exit" {
+					script.warnf("shell command ignored: %s", words[0])
+				}
+				continue
+			}
+			words = words[1:]
+			if len(words) > 0 && words[0] == "-as" {
+				script.warnf("emulation mode (-as %s) is not supported, command skipped", strings.Join(words[1:2], ""))
+				continue
+			}
+		}
+		for _, cmd := range splitScriptCommands(words) {
+			p.apply(cmd)
+		}
+	}
+	return script, nil
+}
+
+// splitScript определяет формат текста: shell-скрипт, если хотя бы одна команда вызывает xorriso
+func splitScript(text string) ([][]string, bool, error) {
+	commands, err := SplitShellScript(text)
+	if err == nil {
+		for _, words := range commands {
+			if path.Base(words[0]) == "xorriso" {
+				return commands, true, nil
+			}
+		}
+	}
+	commands, err = SplitOptionsFile(text)
+	return commands, false, err
+}
+
+// splitScriptCommands делит плоский список аргументов на отдельные команды xorriso
+func splitScriptCommands(args []string) [][]string {
+	var commands [][]string
+	for i := 0; i < len(args); {
+		name := args[i]
+		n, known := scriptCommandArgs[name]
+		end := i + 1
+		switch {
+		case known && n == argEnd:
+			for end < len(args) && args[end] != "--" {
+				end++
+			}
+			if end < len(args) {
+				end++ // включить "--"
+			}
+		case known:
+			end = min(i+1+n, len(args))
+		default:
+			for end < len(args) && !strings.HasPrefix(args[end], "-") {
+				end++
+			}
+		}
+		commands = append(commands, args[i:end])
+		i = end
+	}
+	return commands
+}
+
+type scriptParser struct {
+	script    *Script
+	pathspecs bool
+}
+
+func (s *Script) warnf(format string, args ...any) {
+	s.Warnings = append(s.Warnings, fmt.Sprintf(format, args...))
+}
+
+func (p *scriptParser) apply(cmd []string) {
+	s := p.script
+	iso := &s.Project.ISOOptions
+	burn := &s.Project.BurnOptions
+	name, args := cmd[0], cmd[1:]
+
+	if n, known := scriptCommandArgs[name]; known && n != argEnd && len(args) < n {
+		s.warnf("%s: missing argument", name)
+		return
+	}
+	if scriptIgnored[name] {
+		return
+	}
+
+	switch name {
+	case "-dev", "-outdev":
+		if out, ok := strings.CutPrefix(args[0], "stdio:"); ok {
+			s.OutputPath = out
+		} else {
+			s.Device = args[0]
+		}
+	case "-indev":
+		// -indev того же привода после -commit — шаг проверки записи
+		if args[0] != s.Device {
+			s.warnf("-indev %s ignored: projects always create a new image", args[0])
+		}
+	case "-volid":
+		s.Project.VolumeID = args[0]
+	case "-publisher":
+		iso.PublisherID = args[0]
+	case "-application_id":
+		iso.ApplicationID = args[0]
+	case "-system_id":
+		iso.SystemID = args[0]
+	case "-volset_id":
+		iso.VolumeSetID = args[0]
+	case "-preparer_id":
+		iso.PreparerID = args[0]
+	case "-abstract_file":
+		iso.AbstractFile = args[0]
+	case "-biblio_file":
+		iso.BiblioFile = args[0]
+	case "-copyright_file":
+		iso.CopyrightFile = args[0]
+	case "-volume_date":
+		p.volumeDate(iso, args[0], args[1])
+	case "-rockridge":
+		iso.RockRidge = p.onOff(name, args[0])
+	case "-joliet":
+		iso.Joliet = p.onOff(name, args[0])
+	case "-udf":
+		iso.UDF = p.onOff(name, args[0])
+	case "-hfsplus":
+		iso.HFSPlus = p.onOff(name, args[0])
+	case "-zisofs":
+		iso.Zisofs = args[0] != "off" && args[0] != "default"
+	case "-md5":
+		iso.MD5 = args[0] == "on" || args[0] == "all"
+	case "-for_backup":
+		iso.BackupMode = true
+		iso.MD5 = true
+	case "-hardlinks":
+		iso.Hardlinks = args[0] != "off"
+	case "-iso_level":
+		level, err := strconv.Atoi(args[0])
+		if err != nil || level < 1 || level > 4 {
+			s.warnf("-iso_level: invalid level %q", args[0])
+			return
+		}
+		iso.ISOLevel = level
+	case "-follow":
+		iso.LinkPolicy = "" // ссылки-аргументы сохраняются как есть
+		for _, occasion := range strings.Split(args[0], ":") {
+			if occasion == "on" || occasion == "link" || occasion == "param" {
+				iso.LinkPolicy = models.LinkPolicyFollow
+			}
+		}
+	case "-split_size":
+		size, ok := parseByteSize(args[0])
+		if !ok {
+			s.warnf("-split_size: unsupported value %q", args[0])
+			return
+		}
+		iso.SplitSize = size
+	case "-pathspecs":
+		p.pathspecs = args[0] != "off"
+	case "-map", "-map_single":
+		s.Mappings = append(s.Mappings, Mapping{Source: args[0], Dest: isoPath(args[1]), Single: name == "-map_single"})
+	case "-add":
+		for _, a := range listArgs(args) {
+			dest, src, graft := SplitGraftPoint(a)
+			if !p.pathspecs || !graft {
+				dest, src = a, a
+			}
+			s.Mappings = append(s.Mappings, Mapping{Source: src, Dest: isoPath(dest)})
+		}
+	case "-mkdir":
+		for _, a := range listArgs(args) {
+			s.Mappings = append(s.Mappings, Mapping{Dest: isoPath(a)})
+		}
+	case "-speed":
+		switch args[0] {
+		case "0", "any", "auto":
+			burn.Speed = "auto"
+		default:
+			burn.Speed = args[0]
+		}
+	case "-write_type":
+		switch mode := strings.ToUpper(args[0]); mode {
+		case "AUTO":
+			burn.BurnMode = "auto"
+		case "TAO", "SAO", "DAO":
+			burn.BurnMode = mode
+		case "SAO/DAO":
+			burn.BurnMode = "DAO"
+		default:
+			s.warnf("-write_type: unsupported mode %q", args[0])
+		}
+	case "-padding":
+		kib, ok := parsePadding(args[0])
+		if !ok {
+			s.warnf("-padding: unsupported value %q", args[0])
+			return
+		}
+		burn.Padding = kib
+	case "-dummy":
+		burn.DummyMode = p.onOff(name, args[0])
+	case "-close":
+		burn.CloseDisc = args[0] == "on"
+		if burn.CloseDisc {
+			burn.Multisession = false
+		}
+	case "-stream_recording":
+		burn.StreamRecording = args[0] != "off"
+	case "-eject":
+		burn.Eject = true
+	case "-check_media", "-check_md5", "-check_md5_r":
+		burn.Verify = true
+	case "-chmod_r", "-chown_r", "-chgrp_r", "-alter_date_r", "-hide":
+		p.applyMetadata(name, listArgs(args))
+	case "-sort_weight":
+		weight, err := strconv.ParseInt(args[0], 10, 32)
+		if err != nil {
+			s.warnf("-sort_weight: invalid weight %q", args[0])
+			return
+		}
+		s.SortWeights[isoPath(args[1])] = int(weight)
+	case "-sort_weight_list":
+		s.warnf("-sort_weight_list %s ignored: weight lists are not imported", args[0])
+	case "-blank", "-format":
+		s.warnf("%s ignored: blanking and formatting are not part of a project", name)
+	case "-options_from_file":
+		s.warnf("nested -options_from_file %s ignored", args[0])
+	default:
+		s.warnf("unsupported command ignored: %s", strings.Join(cmd, " "))
+	}
+}
+
+// applyMetadata переносит переопределения атрибутов на пути образа
+func (p *scriptParser) applyMetadata(name string, args []string) {
+	s := p.script
+	head := 1
+	if name == "-alter_date_r" {
+		head = 2
+	}
+	if len(args) <= head {
+		s.warnf("%s: missing argument", name)
+		return
+	}
+
+	for _, target := range args[head:] {
+		target = isoPath(target)
+		m := s.Metadata[target]
+		switch name {
+		case "-chmod_r":
+			m.Mode = args[0]
+		case "-chown_r":
+			m.Owner = args[0]
+		case "-chgrp_r":
+			m.Group = args[0]
+		case "-alter_date_r":
+			seconds, err := strconv.ParseInt(strings.TrimPrefix(args[1], "="), 10, 64)
+			if !strings.HasPrefix(args[1], "=") || err != nil {
+				s.warnf("-alter_date_r: only =<seconds> timestamps are supported, got %q", args[1])
+				return
+			}
+			switch args[0] {
+			case "a", "a-c":
+				m.ATime = seconds * 1000
+			case "m", "m-c":
+				m.MTime = seconds * 1000
+			case "b", "b-c":
+				m.ATime, m.MTime = seconds*1000, seconds*1000
+			case "c":
+				m.CTime = seconds * 1000
+			default:
+				s.warnf("-alter_date_r: unsupported type %q", args[0])
+				return
+			}
+		case "-hide":
+			state := args[0]
+			m.HideISO = state == "on" || strings.Contains(state, "iso_rr")
+			m.HideJoliet = state == "on" || strings.Contains(state, "joliet")
+			m.HideHFSPlus = state == "on" || strings.Contains(state, "hfsplus")
+		}
+		s.Metadata[target] = m
+	}
+}
+
+// volumeDate переносит дату заголовка тома; понимаются только =<seconds> и uuid
+func (p *scriptParser) volumeDate(iso *models.ISOOptions, kind, timestring string) {
+	if kind == "uuid" {
+		iso.VolumeUUID = timestring
+		return
+	}
+	seconds, err := strconv.ParseInt(strings.TrimPrefix(timestring, "="), 10, 64)
+	if !strings.HasPrefix(timestring, "=") || err != nil {
+		p.script.warnf("-volume_date: only =<seconds> timestamps are supported, got %q", timestring)
+		return
+	}
+	switch kind {
+	case "c":
+		iso.CreationDate = seconds * 1000
+	case "m":
+		iso.ModificationDate = seconds * 1000
+	case "x":
+		iso.ExpirationDate = seconds * 1000
+	case "f":
+		iso.EffectiveDate = seconds * 1000
+	default:
+		p.script.warnf("-volume_date: unsupported type %q", kind)
+	}
+}
+
+func (p *scriptParser) onOff(name, value string) bool {
+	switch value {
+	case "on":
+		return true
+	case "off":
+		return false
+	}
+	p.script.warnf("%s: expected on or off, got %q", name, value)
+	return false
+}
+
+// listArgs отбрасывает завершающий "--" у списка аргументов
+func listArgs(args []string) []string {
+	if len(args) > 0 && args[len(args)-1] == "--" {
+		return args[:len(args)-1]
+	}
+	return args
+}
+
+// isoPath приводит путь в образе к абсолютному виду
+func isoPath(p string) string {
+	return path.Join("/", p)
+}
+
+// parseByteSize разбирает размер в байтах с необязательным суффиксом k, m, g или t
+func parseByteSize(value string) (int64, bool) {
+	mult := int64(1)
+	if n := len(value); n > 0 {
+		switch value[n-1] {
+		case 'k', 'K':
+			mult = 1 << 10
+		case 'm', 'M':
+			mult = 1 << 20
+		case 'g', 'G':
+			mult = 1 << 30
+		case 't', 'T':
+			mult = 1 << 40
+		}
+		if mult > 1 {
+			value = value[:n-1]
+		}
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n * mult, true
+}
+
+// parsePadding разбирает значение -padding в KiB: "300k", "1m" или число байт
+func parsePadding(value string) (int, bool) {
+	mult, digits := 1, value
+	switch {
+	case strings.HasSuffix(value, "k"):
+		digits = strings.TrimSuffix(value, "k")
+	case strings.HasSuffix(value, "m"):
+		mult, digits = 1024, strings.TrimSuffix(value, "m")
+	default:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		return (n + 1023) / 1024, true
+	}
+	n, err := strconv.Atoi(digits)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n * mult, true
+}
@@ -0,0 +1,271 @@
+package xorriso
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"xorriso-ui/pkg/models"
+)
+
+func TestParseScript_OptionsFile(t *testing.T) {
+	text := `# burn backup
+-dev /dev/sr0
+-abort_on FAILURE
+-volid 'My Disc'
+-publisher "it's me"
+-rockridge off -joliet on -udf on
+-iso_level 3
+-md5 on
+-mkdir /empty --
+-map_single /src/docs /docs
+-map '/src/docs/a b.txt' '/docs/a b.txt'
+-map /src/photos photos
+-speed 8x -write_type sao/dao -padding 300k
+-dummy on -close on -stream_recording on
+-commit
+-indev /dev/sr0
+-check_media --
+-eject all
+`
+	script, err := ParseScript(text)
+	if err != nil {
+		t.Fatalf("ParseScript: %v", err)
+	}
+
+	p := script.Project
+	if p.VolumeID != "My Disc" || p.ISOOptions.PublisherID != "it's me" {
+		t.Errorf("volid = %q, publisher = %q", p.VolumeID, p.ISOOptions.PublisherID)
+	}
+	wantISO := models.ISOOptions{Joliet: true, UDF: true, ISOLevel: 3, MD5: true, PublisherID: "it's me"}
+	if p.ISOOptions != wantISO {
+		t.Errorf("ISOOptions = %+v, want %+v", p.ISOOptions, wantISO)
+	}
+	wantBurn := models.BurnOptions{
+		Speed: "8x", BurnMode: "DAO", Padding: 300, DummyMode: true,
+		CloseDisc: true, StreamRecording: true, Verify: true, Eject: true,
+	}
+	if p.BurnOptions != wantBurn {
+		t.Errorf("BurnOptions = %+v, want %+v", p.BurnOptions, wantBurn)
+	}
+	if script.Device != "/dev/sr0" {
+		t.Errorf("Device = %q", script.Device)
+	}
+
+	wantMappings := []Mapping{
+		{Dest: "/empty"},
+		{Source: "/src/docs", Dest: "/docs", Single: true},
+		{Source: "/src/docs/a b.txt", Dest: "/docs/a b.txt"},
+		{Source: "/src/photos", Dest: "/photos"},
+	}
+	if !slices.Equal(script.Mappings, wantMappings) {
+		t.Errorf("Mappings = %+v, want %+v", script.Mappings, wantMappings)
+	}
+	if len(script.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", script.Warnings)
+	}
+}
+
+func TestParseScript_Shell(t *testing.T) {
+	text := `#!/bin/sh
+set -e
+# write
+/usr/bin/xorriso \
+  -outdev stdio:'/tmp/my image.iso' \
+  -pathspecs on \
+  -add /music=/home/user/Music /etc/hosts -- \
+  -boot_image any bin_path=/isolinux.bin \
+  -commit
+echo done
+xorriso -as mkisofs -o out.iso dir
+`
+	script, err := ParseScript(text)
+	if err != nil {
+		t.Fatalf("ParseScript: %v", err)
+	}
+	if script.OutputPath != "/tmp/my image.iso" || script.Device != "" {
+		t.Errorf("OutputPath = %q, Device = %q", script.OutputPath, script.Device)
+	}
+	wantMappings := []Mapping{
+		{Source: "/home/user/Music", Dest: "/music"},
+		{Source: "/etc/hosts", Dest: "/etc/hosts"},
+	}
+	if !slices.Equal(script.Mappings, wantMappings) {
+		t.Errorf("Mappings = %+v, want %+v", script.Mappings, wantMappings)
+	}
+	for _, w := range []string{"-boot_image", "echo", "-as mkisofs"} {
+		found := false
+		for _, got := range script.Warnings {
+			found = found || strings.Contains(got, w)
+		}
+		if !found {
+			t.Errorf("expected warning about %s, got %v", w, script.Warnings)
+		}
+	}
+}
+
+func TestParseScript_BuilderRoundTrip(t *testing.T) {
+	cmd := NewCommand().
+		Device("/dev/sr0").
+		VolumeID("ROUND TRIP").
+		ISOLevel(4).
+		RockRidge(true).
+		Joliet(true).
+		Zisofs(true).
+		ForBackup().
+		Map("/src/it's here", "/it's here").
+		WriteSpeed("4").
+		WriteType("TAO").
+		Padding(64).
+		Close(false).
+		Commit()
+
+	var lines []string
+	for _, c := range cmd.Commands() {
+		lines = append(lines, OptionJoin(c))
+	}
+	script, err := ParseScript(strings.Join(lines, "\n"))
+	if err != nil {
+		t.Fatalf("ParseScript: %v", err)
+	}
+
+	p := script.Project
+	if p.VolumeID != "ROUND TRIP" || p.ISOOptions.ISOLevel != 4 || !p.ISOOptions.Joliet ||
+		!p.ISOOptions.Zisofs || !p.ISOOptions.BackupMode {
+		t.Errorf("unexpected ISO options: %q %+v", p.VolumeID, p.ISOOptions)
+	}
+	if p.BurnOptions.Speed != "4" || p.BurnOptions.BurnMode != "TAO" || p.BurnOptions.Padding != 64 || p.BurnOptions.CloseDisc {
+		t.Errorf("unexpected burn options: %+v", p.BurnOptions)
+	}
+	if len(script.Mappings) != 1 || script.Mappings[0].Source != "/src/it's here" {
+		t.Errorf("Mappings = %+v", script.Mappings)
+	}
+	if len(script.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", script.Warnings)
+	}
+}
+
+func TestParseScript_Metadata(t *testing.T) {
+	text := `-map /src/docs /docs
+-chmod_r go-w /docs --
+-chown_r 0 /docs --
+-chgrp_r users /docs /docs/a --
+-alter_date_r b =1700000000 /docs --
+-alter_date_r m 'Nov 8 14:51:13 CET 2007' /docs --
+-hide joliet:hfsplus /docs/a --
+`
+	script, err := ParseScript(text)
+	if err != nil {
+		t.Fatalf("ParseScript: %v", err)
+	}
+
+	want := map[string]models.EntryMetadata{
+		"/docs":   {Mode: "go-w", Owner: "0", Group: "users", ATime: 1700000000000, MTime: 1700000000000},
+		"/docs/a": {Group: "users", HideJoliet: true, HideHFSPlus: true},
+	}
+	for p, m := range want {
+		if script.Metadata[p] != m {
+			t.Errorf("Metadata[%s] = %+v, want %+v", p, script.Metadata[p], m)
+		}
+	}
+	if len(script.Warnings) != 1 || !strings.Contains(script.Warnings[0], "=<seconds>") {
+		t.Errorf("expected one warning about the date format, got %v", script.Warnings)
+	}
+}
+
+func TestParseScript_VolumeDescriptor(t *testing.T) {
+	text := `-application_id 'My Tool 1.0'
+-system_id GNU
+-volset_id ARCHIVES
+-preparer_id 'Backup team'
+-abstract_file ABSTRACT.TXT
+-biblio_file BIBLIO.TXT
+-copyright_file COPYING
+-volume_date x =1900000000
+-volume_date f =1700000000
+-volume_date uuid 2026101812000000
+-volume_date c 'Nov 8 14:51:13 CET 2007'
+`
+	script, err := ParseScript(text)
+	if err != nil {
+		t.Fatalf("ParseScript: %v", err)
+	}
+
+	want := models.ISOOptions{
+		RockRidge:      true,
+		ApplicationID:  "My Tool 1.0",
+		SystemID:       "GNU",
+		VolumeSetID:    "ARCHIVES",
+		PreparerID:     "Backup team",
+		AbstractFile:   "ABSTRACT.TXT",
+		BiblioFile:     "BIBLIO.TXT",
+		CopyrightFile:  "COPYING",
+		ExpirationDate: 1900000000000,
+		EffectiveDate:  1700000000000,
+		VolumeUUID:     "2026101812000000",
+	}
+	if script.Project.ISOOptions != want {
+		t.Errorf("ISOOptions = %+v, want %+v", script.Project.ISOOptions, want)
+	}
+	if len(script.Warnings) != 1 || !strings.Contains(script.Warnings[0], "=<seconds>") {
+		t.Errorf("expected one warning about the date format, got %v", script.Warnings)
+	}
+}
+
+func TestParseScript_SortWeight(t *testing.T) {
+	text := `-map /src/autorun.inf /autorun.inf
+-sort_weight 100 /autorun.inf
+-sort_weight -2 video
+-sort_weight heavy /x
+-sort_weight_list /tmp/weights
+`
+	script, err := ParseScript(text)
+	if err != nil {
+		t.Fatalf("ParseScript: %v", err)
+	}
+	if script.SortWeights["/autorun.inf"] != 100 || script.SortWeights["/video"] != -2 || len(script.SortWeights) != 2 {
+		t.Errorf("SortWeights = %v", script.SortWeights)
+	}
+	if len(script.Warnings) != 2 {
+		t.Errorf("expected warnings for the invalid weight and the list, got %v", script.Warnings)
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		value string
+		bytes int64
+		ok    bool
+	}{
+		{"4095m", 4095 << 20, true},
+		{"2g", 2 << 30, true},
+		{"1048576", 1 << 20, true},
+		{"-1", 0, false},
+		{"big", 0, false},
+	}
+	for _, tt := range tests {
+		bytes, ok := parseByteSize(tt.value)
+		if bytes != tt.bytes || ok != tt.ok {
+			t.Errorf("parseByteSize(%q) = (%d, %v), want (%d, %v)", tt.value, bytes, ok, tt.bytes, tt.ok)
+		}
+	}
+}
+
+func TestParsePadding(t *testing.T) {
+	tests := []struct {
+		value string
+		kib   int
+		ok    bool
+	}{
+		{"300k", 300, true},
+		{"2m", 2048, true},
+		{"4096", 4, true},
+		{"included", 0, false},
+	}
+	for _, tt := range tests {
+		kib, ok := parsePadding(tt.value)
+		if kib != tt.kib || ok != tt.ok {
+			t.Errorf("parsePadding(%q) = (%d, %v), want (%d, %v)", tt.value, kib, ok, tt.kib, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,415 @@
+package services
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"xorriso-ui/pkg/models"
+	"xorriso-ui/pkg/xorriso"
+)
+
+// restoreTarget — путь в образе и куда он извлекается
+type restoreTarget struct {
+	isoPath  string
+	diskPath string
+}
+
+// extractPlan — команды -extract и файлы, которые они создадут
+type extractPlan struct {
+	extracts  []restoreTarget
+	extracted []restoreTarget // все узлы, которые появятся на диске, с их файлами в образе
+	files     map[string]models.ISOFile
+	skipped   int
+	renamed   int
+	total     int64
+}
+
+// StartExtract copies files and folders from the disc in a drive or from an ISO image
+// file to a local directory. Each selected path is extracted into DestDir under its
+// own name; "/" extracts the whole tree into DestDir. Files with an MD5 recorded in
+// the image are checked after copying.
+func (s *BurnService) StartExtract(source string, opts models.ExtractOptions) (string, error) {
+	if opts.Session < 0 {
+		return "", fmt.Errorf("invalid session number: %d", opts.Session)
+	}
+	if info, err := os.Stat(opts.DestDir); err != nil || !info.IsDir() || !filepath.IsAbs(opts.DestDir) {
+		return "", fmt.Errorf("destination is not an existing absolute directory: %s", opts.DestDir)
+	}
+	switch opts.OnConflict {
+	case "":
+		opts.OnConflict = models.ConflictOverwrite
+	case models.ConflictOverwrite, models.ConflictSkip, models.ConflictRename:
+	default:
+		return "", fmt.Errorf("invalid conflict policy: %s", opts.OnConflict)
+	}
+	targets, err := restoreTargets(opts.Paths, opts.DestDir)
+	if err != nil {
+		return "", err
+	}
+
+	jobID, ctx, err := s.startJob()
+	if err != nil {
+		return "", err
+	}
+	go s.runExtract(ctx, source, opts, targets, jobID)
+
+	return jobID, nil
+}
+
+// StartSessionRestore restores files and folders of a session of the disc into a
+// local directory with their permissions, owners and times, overwriting existing
+// files. Session 0 is the last session.
+func (s *BurnService) StartSessionRestore(devicePath string, session int, isoPaths []string, destDir string) (string, error) {
+	return s.StartExtract(devicePath, models.ExtractOptions{
+		Session:     session,
+		Paths:       isoPaths,
+		DestDir:     destDir,
+		Permissions: true,
+		Ownership:   true,
+		Timestamps:  true,
+		OnConflict:  models.ConflictOverwrite,
+	})
+}
+
+// jobActiveLocked сообщает, выполняется ли задача, которую нельзя перебивать новой.
+// Pending — задача уже зарегистрирована, но её горутина ещё не сменила состояние.
+func (s *BurnService) jobActiveLocked() bool {
+	if s.currentJob == nil {
+		return false
+	}
+	switch s.currentJob.State {
+	case models.BurnStatePending, models.BurnStateWriting, models.BurnStateVerifying, models.BurnStateCreatingISO, models.BurnStateExtracting:
+		return true
+	}
+	return false
+}
+
+// restoreTargets проверяет выбранные пути и сопоставляет им пути на диске
+func restoreTargets(isoPaths []string, destDir string) ([]restoreTarget, error) {
+	if len(isoPaths) == 0 {
+		return nil, fmt.Errorf("no paths selected")
+	}
+	targets := make([]restoreTarget, 0, len(isoPaths))
+	used := make(map[string]string, len(isoPaths))
+	for _, p := range isoPaths {
+		if !path.IsAbs(p) {
+			return nil, fmt.Errorf("path in session must be absolute: %s", p)
+		}
+		p = path.Clean(p)
+		diskPath := destDir
+		if p != "/" {
+			diskPath = filepath.Join(destDir, path.Base(p))
+		}
+		if other, ok := used[diskPath]; ok {
+			return nil, fmt.Errorf("%s and %s would be restored to the same path %s", other, p, diskPath)
+		}
+		used[diskPath] = p
+		targets = append(targets, restoreTarget{isoPath: p, diskPath: diskPath})
+	}
+	if len(targets) > 1 && used[destDir] != "" {
+		return nil, fmt.Errorf("the whole session cannot be restored together with other paths")
+	}
+	return targets, nil
+}
+
+// planExtract сопоставляет выбранные пути с каталогом назначения. Свободный путь
+// извлекается целиком; в совпадающий каталог при skip и rename план спускается,
+// чтобы решить судьбу каждого совпавшего файла отдельно.
+func planExtract(files []models.ISOFile, targets []restoreTarget, policy string) (*extractPlan, error) {
+	plan := &extractPlan{files: make(map[string]models.ISOFile, len(files))}
+	children := make(map[string][]models.ISOFile)
+	for _, f := range files {
+		plan.files[f.Path] = f
+		if f.Path != "/" {
+			children[path.Dir(f.Path)] = append(children[path.Dir(f.Path)], f)
+		}
+	}
+
+	// addSubtree учитывает узел и всё его содержимое под новым путём на диске
+	var addSubtree func(isoPath, diskPath string)
+	addSubtree = func(isoPath, diskPath string) {
+		plan.extracted = append(plan.extracted, restoreTarget{isoPath: isoPath, diskPath: diskPath})
+		plan.total += plan.files[isoPath].Size
+		for _, c := range children[isoPath] {
+			addSubtree(c.Path, filepath.Join(diskPath, c.Name))
+		}
+	}
+
+	var visit func(isoPath, diskPath string)
+	visit = func(isoPath, diskPath string) {
+		f := plan.files[isoPath]
+		info, err := os.Lstat(diskPath)
+		switch {
+		case err != nil:
+			plan.extracts = append(plan.extracts, restoreTarget{isoPath: isoPath, diskPath: diskPath})
+			addSubtree(isoPath, diskPath)
+		case policy == models.ConflictOverwrite:
+			// -overwrite nondir заменяет файлы и сливает каталоги
+			plan.extracts = append(plan.extracts, restoreTarget{isoPath: isoPath, diskPath: diskPath})
+			addSubtree(isoPath, diskPath)
+		case f.IsDir && info.IsDir():
+			for _, c := range children[isoPath] {
+				visit(c.Path, filepath.Join(diskPath, c.Name))
+			}
+		case policy == models.ConflictSkip:
+			plan.skipped++
+		default:
+			// Новое имя не должно совпасть ни с файлом на диске, ни с соседями в образе
+			used := make(map[string]bool)
+			if items, err := os.ReadDir(filepath.Dir(diskPath)); err == nil {
+				for _, item := range items {
+					used[item.Name()] = true
+				}
+			}
+			for _, c := range children[path.Dir(isoPath)] {
+				used[c.Name] = true
+			}
+			for _, t := range plan.extracts {
+				if filepath.Dir(t.diskPath) == filepath.Dir(diskPath) {
+					used[filepath.Base(t.diskPath)] = true
+				}
+			}
+			renamed := filepath.Join(filepath.Dir(diskPath), uniqueName(filepath.Base(diskPath), used))
+			plan.extracts = append(plan.extracts, restoreTarget{isoPath: isoPath, diskPath: renamed})
+			addSubtree(isoPath, renamed)
+			plan.renamed++
+		}
+	}
+
+	for _, t := range targets {
+		if _, ok := plan.files[t.isoPath]; !ok {
+			return nil, fmt.Errorf("%s not found in the image", t.isoPath)
+		}
+		visit(t.isoPath, t.diskPath)
+	}
+	return plan, nil
+}
+
+// buildExtractCommand формирует извлечение по плану
+func buildExtractCommand(source string, session int, plan *extractPlan, policy string) *xorriso.CommandBuilder {
+	cmd := loadSession(xorriso.NewCommand(), source, session)
+	cmd.AbortOn("FAILURE")
+	// Без -osirrox on xorriso не копирует файлы из образа на диск
+	cmd.OsirroX("on")
+	if policy == models.ConflictOverwrite {
+		cmd.Overwrite("nondir")
+	} else {
+		// Совпадения уже разобраны планом — xorriso не должен ничего заменять
+		cmd.Overwrite("off")
+	}
+	for _, t := range plan.extracts {
+		cmd.Extract(t.isoPath, t.diskPath)
+	}
+	return cmd
+}
+
+// runExtract читает дерево образа, извлекает файлы с прогрессом, приводит атрибуты
+// к выбранным опциям и проверяет записанные MD5
+func (s *BurnService) runExtract(ctx context.Context, source string, opts models.ExtractOptions, targets []restoreTarget, jobID string) {
+	startTime := time.Now()
+	s.updateState(jobID, models.BurnStateExtracting)
+
+	listCtx, listCancel := context.WithTimeout(ctx, sessionListTimeout)
+	files, _, err := readImageFiles(listCtx, s.executor, source, opts.Session)
+	listCancel()
+	if err != nil {
+		s.finishJob(jobID, models.BurnStateError, nil, err.Error())
+		return
+	}
+	plan, err := planExtract(files, targets, opts.OnConflict)
+	if err != nil {
+		s.finishJob(jobID, models.BurnStateError, nil, err.Error())
+		return
+	}
+
+	if len(plan.extracts) > 0 {
+		cmd := buildExtractCommand(source, opts.Session, plan, opts.OnConflict)
+		result, err := s.executor.RunWithProgress(ctx, func(p xorriso.Progress) {
+			s.setJobProgress(jobID, "extracting", p.BytesWritten, plan.total, p.Speed)
+		}, cmd.Build()...)
+		if ctx.Err() != nil {
+			// Отменено через CancelBurn — состояние уже выставлено
+			return
+		}
+		if err != nil {
+			s.finishJob(jobID, models.BurnStateError, nil, err.Error())
+			return
+		}
+
+		s.emitLogLines(result.InfoLines)
+
+		if result.ExitCode != 0 {
+			errMsg := "xorriso exited with code " + fmt.Sprintf("%d", result.ExitCode)
+			if len(result.InfoLines) > 0 {
+				errMsg = result.InfoLines[len(result.InfoLines)-1]
+			}
+			s.finishJob(jobID, models.BurnStateError, nil, errMsg)
+			return
+		}
+	}
+
+	for _, err := range applyExtractAttributes(plan, opts) {
+		s.emitLog(err.Error())
+	}
+
+	checked, mismatches, err := s.verifyExtracted(ctx, jobID, plan)
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		s.finishJob(jobID, models.BurnStateError, nil, err.Error())
+		return
+	}
+
+	duration := time.Since(startTime)
+	var avgSpeed string
+	if duration.Seconds() > 0 && plan.total > 0 {
+		mbPerSec := float64(plan.total) / 1024.0 / 1024.0 / duration.Seconds()
+		avgSpeed = fmt.Sprintf("%.2f MB/s", mbPerSec)
+	}
+	extractResult := &models.BurnResult{
+		Success:      mismatches == 0,
+		BytesWritten: plan.total,
+		Duration:     duration.String(),
+		AverageSpeed: avgSpeed,
+		MD5Match:     checked > 0 && mismatches == 0,
+		VerifyErrors: mismatches,
+		FilesSkipped: plan.skipped,
+		FilesRenamed: plan.renamed,
+		MD5Checked:   checked,
+	}
+	if mismatches > 0 {
+		s.finishJob(jobID, models.BurnStateError, extractResult, fmt.Sprintf("MD5 mismatch in %d extracted files", mismatches))
+		return
+	}
+	s.finishJob(jobID, models.BurnStateDone, extractResult, "")
+}
+
+// applyExtractAttributes сбрасывает атрибуты, которые не нужно было восстанавливать.
+// xorriso восстанавливает права и время всегда, владельца — только при запуске от root.
+func applyExtractAttributes(plan *extractPlan, opts models.ExtractOptions) []error {
+	if opts.Permissions && opts.Timestamps && (opts.Ownership || os.Geteuid() != 0) {
+		return nil
+	}
+	var errs []error
+	now := time.Now()
+	umask := processUmask()
+	// С конца, чтобы время каталога не менялось после обработки его содержимого
+	for i := len(plan.extracted) - 1; i >= 0; i-- {
+		t := plan.extracted[i]
+		f := plan.files[t.isoPath]
+		if f.Type == models.EntryTypeSymlink {
+			continue
+		}
+		if !opts.Permissions {
+			// Как cp без -p: права по умолчанию с учётом umask, бит исполнения сохраняется
+			mode := 0666 | lsExecBits(f.Mode)
+			if f.IsDir {
+				mode = 0777
+			}
+			if err := os.Chmod(t.diskPath, mode&^umask); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if !opts.Ownership && os.Geteuid() == 0 {
+			if err := os.Lchown(t.diskPath, os.Getuid(), os.Getgid()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if !opts.Timestamps {
+			if err := os.Chtimes(t.diskPath, now, now); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// lsExecBits возвращает биты исполнения из прав в формате ls -l ("-rwxr-x---")
+func lsExecBits(mode string) os.FileMode {
+	var bits os.FileMode
+	if len(mode) != 10 {
+		return 0
+	}
+	for i, bit := range map[int]os.FileMode{3: 0100, 6: 0010, 9: 0001} {
+		// s и t — исполнение вместе с setuid/setgid/sticky, S и T — без исполнения
+		if c := mode[i]; c == 'x' || c == 's' || c == 't' {
+			bits |= bit
+		}
+	}
+	return bits
+}
+
+// processUmask читает umask процесса из /proc, не меняя его: umask общий для всех
+// потоков, и временная замена повлияла бы на файлы, создаваемые параллельно
+func processUmask() os.FileMode {
+	data, err := os.ReadFile("/proc/self/status")
+	if err == nil {
+		for line := range strings.Lines(string(data)) {
+			if v, ok := strings.CutPrefix(line, "Umask:"); ok {
+				if mask, err := strconv.ParseUint(strings.TrimSpace(v), 8, 32); err == nil {
+					return os.FileMode(mask) & os.ModePerm
+				}
+			}
+		}
+	}
+	return 0022
+}
+
+// verifyExtracted сверяет MD5 извлечённых файлов с записанными в образе
+func (s *BurnService) verifyExtracted(ctx context.Context, jobID string, plan *extractPlan) (checked, mismatches int, err error) {
+	var total, done int64
+	for _, t := range plan.extracted {
+		if plan.files[t.isoPath].MD5 != "" {
+			total += plan.files[t.isoPath].Size
+		}
+	}
+	if total == 0 {
+		return 0, 0, nil
+	}
+	s.updateState(jobID, models.BurnStateVerifying)
+
+	for _, t := range plan.extracted {
+		f := plan.files[t.isoPath]
+		if f.MD5 == "" {
+			continue
+		}
+		if ctx.Err() != nil {
+			return checked, mismatches, ctx.Err()
+		}
+		sum, err := fileMD5(t.diskPath)
+		if err != nil {
+			return checked, mismatches, fmt.Errorf("failed to check %s: %w", t.diskPath, err)
+		}
+		checked++
+		if !strings.EqualFold(sum, f.MD5) {
+			mismatches++
+			s.emitLog(fmt.Sprintf("MD5 mismatch: %s", t.diskPath))
+		}
+		done += f.Size
+		s.setJobProgress(jobID, "verifying", done, total, "")
+	}
+	return checked, mismatches, nil
+}
+
+func fileMD5(p string) (string, error) {
+	file, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = file.Close() }()
+	h := md5.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
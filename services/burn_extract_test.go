@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"xorriso-ui/pkg/models"
+	"xorriso-ui/pkg/xorriso"
+)
+
+func TestRestoreTargets(t *testing.T) {
+	dest := t.TempDir()
+	targets, err := restoreTargets([]string{"/docs/", "/photos/2024"}, dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []restoreTarget{
+		{isoPath: "/docs", diskPath: filepath.Join(dest, "docs")},
+		{isoPath: "/photos/2024", diskPath: filepath.Join(dest, "2024")},
+	}
+	if len(targets) != 2 || targets[0] != want[0] || targets[1] != want[1] {
+		t.Errorf("targets = %+v, want %+v", targets, want)
+	}
+
+	if targets, _ := restoreTargets([]string{"/"}, dest); len(targets) != 1 || targets[0].diskPath != dest {
+		t.Errorf("whole session = %+v", targets)
+	}
+
+	for name, paths := range map[string][]string{
+		"empty":          nil,
+		"relative":       {"docs"},
+		"same base name": {"/a/docs", "/b/docs"},
+		"root and more":  {"/", "/docs"},
+	} {
+		if _, err := restoreTargets(paths, dest); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+var extractFiles = []models.ISOFile{
+	{Path: "/", Name: "/", IsDir: true},
+	{Path: "/docs", Name: "docs", IsDir: true},
+	{Path: "/docs/a.txt", Name: "a.txt", Size: 2},
+	{Path: "/docs/a (2).txt", Name: "a (2).txt", Size: 3},
+	{Path: "/docs/b.txt", Name: "b.txt", Size: 4},
+	{Path: "/docs/sub", Name: "sub", IsDir: true},
+	{Path: "/docs/sub/c.txt", Name: "c.txt", Size: 5},
+}
+
+// extractDest создаёт каталог назначения, где уже есть docs/a.txt
+func extractDest(t *testing.T) string {
+	t.Helper()
+	dest := t.TempDir()
+	os.MkdirAll(filepath.Join(dest, "docs"), 0755)
+	os.WriteFile(filepath.Join(dest, "docs", "a.txt"), []byte("old"), 0644)
+	return dest
+}
+
+func TestPlanExtract_Policies(t *testing.T) {
+	dest := extractDest(t)
+	targets := []restoreTarget{{isoPath: "/docs", diskPath: filepath.Join(dest, "docs")}}
+	join := func(ts []restoreTarget) string {
+		var parts []string
+		for _, t := range ts {
+			parts = append(parts, t.isoPath+"→"+strings.TrimPrefix(t.diskPath, dest))
+		}
+		return strings.Join(parts, " ")
+	}
+
+	plan, err := planExtract(extractFiles, targets, models.ConflictOverwrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := join(plan.extracts); got != "/docs→/docs" || plan.total != 14 {
+		t.Errorf("overwrite: %s, total %d", got, plan.total)
+	}
+
+	plan, _ = planExtract(extractFiles, targets, models.ConflictSkip)
+	want := "/docs/a (2).txt→/docs/a (2).txt /docs/b.txt→/docs/b.txt /docs/sub→/docs/sub"
+	if got := join(plan.extracts); got != want || plan.skipped != 1 || plan.total != 12 {
+		t.Errorf("skip: %s (skipped %d, total %d)", got, plan.skipped, plan.total)
+	}
+
+	// "a (2).txt" занят соседом в образе — копия получает следующий номер
+	plan, _ = planExtract(extractFiles, targets, models.ConflictRename)
+	if got := join(plan.extracts); !strings.HasPrefix(got, "/docs/a.txt→/docs/a (3).txt ") || plan.renamed != 1 {
+		t.Errorf("rename: %s (renamed %d)", got, plan.renamed)
+	}
+
+	if _, err := planExtract(extractFiles, []restoreTarget{{isoPath: "/missing", diskPath: dest}}, models.ConflictSkip); err == nil {
+		t.Error("expected an error for a path missing from the image")
+	}
+}
+
+func TestApplyExtractAttributes(t *testing.T) {
+	defer syscall.Umask(syscall.Umask(0027))
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.txt")
+	os.WriteFile(file, []byte("a"), 0600)
+	script := filepath.Join(dir, "run.sh")
+	os.WriteFile(script, []byte("#!/bin/sh"), 0700)
+	sub := filepath.Join(dir, "sub")
+	os.Mkdir(sub, 0700)
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	os.Chtimes(file, old, old)
+
+	plan := &extractPlan{
+		extracted: []restoreTarget{
+			{isoPath: "/a.txt", diskPath: file},
+			{isoPath: "/run.sh", diskPath: script},
+			{isoPath: "/sub", diskPath: sub},
+		},
+		files: map[string]models.ISOFile{
+			"/a.txt":  {Path: "/a.txt", Name: "a.txt", Mode: "-rw-------"},
+			"/run.sh": {Path: "/run.sh", Name: "run.sh", Mode: "-rwxr-x--T"},
+			"/sub":    {Path: "/sub", Name: "sub", IsDir: true, Mode: "drwx------"},
+		},
+	}
+	if errs := applyExtractAttributes(plan, models.ExtractOptions{Ownership: true}); len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	// Права по умолчанию с учётом umask 027, бит исполнения скрипта сохранён
+	for path, want := range map[string]os.FileMode{file: 0640, script: 0750, sub: 0750} {
+		if info, _ := os.Stat(path); info.Mode().Perm() != want {
+			t.Errorf("%s: mode %v, want %v", filepath.Base(path), info.Mode().Perm(), want)
+		}
+	}
+	if info, _ := os.Stat(file); !info.ModTime().After(old) {
+		t.Errorf("time %v", info.ModTime())
+	}
+}
+
+func TestStartExtract_VerifiesMD5(t *testing.T) {
+	dest := extractDest(t)
+	var extractArgs []string
+	runner := &mockRunner{
+		RunFn: func(ctx context.Context, args ...string) (*xorriso.CmdResult, error) {
+			return &xorriso.CmdResult{ResultLines: []string{
+				"drwxr-xr-x    1 0        0               0 Oct 18 12:00 '/'",
+				"drwxr-xr-x    1 1000     1000            0 Oct 18 12:00 '/docs'",
+				"-rw-r--r--    1 1000     1000            2 Oct 18 12:00 '/docs/a.txt'",
+				"-rw-r--r--    1 1000     1000            3 Oct 18 12:00 '/docs/b.txt'",
+				// md5("aa") и md5("bbb")
+				"4124bc0a9335c27f086f24ba207a4912  '/docs/a.txt'",
+				"08f8e0260c64418510cefb2b06eee5cd  '/docs/b.txt'",
+			}}, nil
+		},
+		RunWithProgressFn: func(ctx context.Context, progressFn func(xorriso.Progress), args ...string) (*xorriso.CmdResult, error) {
+			extractArgs = args
+			// Имитация xorriso: b.txt извлекается повреждённым
+			os.WriteFile(filepath.Join(dest, "docs", "a (2).txt"), []byte("aa"), 0644)
+			os.WriteFile(filepath.Join(dest, "docs", "b.txt"), []byte("bbX"), 0644)
+			progressFn(xorriso.Progress{BytesWritten: 5})
+			return &xorriso.CmdResult{}, nil
+		},
+	}
+	svc := NewBurnService(runner)
+	svc.emitEvent = noopEmit
+
+	jobID, err := svc.StartExtract("/tmp/disc.iso", models.ExtractOptions{
+		Paths: []string{"/docs"}, DestDir: dest, OnConflict: models.ConflictRename,
+		Permissions: true, Ownership: true, Timestamps: true,
+	})
+	if err != nil {
+		t.Fatalf("StartExtract: %v", err)
+	}
+	job := waitForJob(t, svc, jobID)
+
+	want := "-indev /tmp/disc.iso -abort_on FAILURE -osirrox on -overwrite off " +
+		"-extract /docs/a.txt " + filepath.Join(dest, "docs", "a (2).txt") + " " +
+		"-extract /docs/b.txt " + filepath.Join(dest, "docs", "b.txt")
+	if got := strings.Join(extractArgs, " "); got != want {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+	if job.State != models.BurnStateError || job.Result == nil {
+		t.Fatalf("job = %+v", job)
+	}
+	if r := job.Result; r.MD5Checked != 2 || r.VerifyErrors != 1 || r.FilesRenamed != 1 || r.MD5Match {
+		t.Errorf("result = %+v", r)
+	}
+}
+
+func TestStartExtract_InvalidOptions(t *testing.T) {
+	svc := NewBurnService(&mockRunner{})
+	dest := t.TempDir()
+	for name, opts := range map[string]models.ExtractOptions{
+		"relative destination": {Paths: []string{"/a"}, DestDir: "restore"},
+		"missing destination":  {Paths: []string{"/a"}, DestDir: filepath.Join(dest, "missing")},
+		"conflict policy":      {Paths: []string{"/a"}, DestDir: dest, OnConflict: "merge"},
+		"session":              {Paths: []string{"/a"}, DestDir: dest, Session: -1},
+	} {
+		if _, err := svc.StartExtract("/dev/sr0", opts); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestStartSessionRestore(t *testing.T) {
+	dest := t.TempDir()
+	var listCalls [][]string
+	var extractArgs []string
+	runner := sessionRunner(sessionListings, &listCalls)
+	runner.RunWithProgressFn = func(ctx context.Context, progressFn func(xorriso.Progress), args ...string) (*xorriso.CmdResult, error) {
+		extractArgs = args
+		progressFn(xorriso.Progress{Phase: "extracting", BytesWritten: 5})
+		return &xorriso.CmdResult{}, nil
+	}
+
+	var progress []models.BurnProgress
+	svc := NewBurnService(runner)
+	svc.emitEvent = func(name string, data ...any) {
+		if name == models.EventBurnProgress {
+			progress = append(progress, data[0].(models.BurnProgress))
+		}
+	}
+
+	jobID, err := svc.StartSessionRestore("/dev/sr0", 1, []string{"/docs"}, dest)
+	if err != nil {
+		t.Fatalf("StartSessionRestore: %v", err)
+	}
+	job := waitForJob(t, svc, jobID)
+	if job.State != models.BurnStateDone || job.Result == nil || job.Result.BytesWritten != 15 {
+		t.Fatalf("job = %+v", job)
+	}
+
+	want := "-load session 1 -indev /dev/sr0 -abort_on FAILURE -osirrox on -overwrite nondir -extract /docs " + filepath.Join(dest, "docs")
+	if got := strings.Join(extractArgs, " "); got != want {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+	if len(progress) != 1 || progress[0].BytesTotal != 15 || progress[0].Percent < 33 || progress[0].Percent > 34 {
+		t.Errorf("progress = %+v", progress)
+	}
+}
+
+func TestStartSessionRestore_MissingPath(t *testing.T) {
+	var calls [][]string
+	runner := sessionRunner(sessionListings, &calls)
+	runner.RunWithProgressFn = func(ctx context.Context, progressFn func(xorriso.Progress), args ...string) (*xorriso.CmdResult, error) {
+		t.Error("extraction must not start for a path missing from the session")
+		return &xorriso.CmdResult{}, nil
+	}
+	svc := NewBurnService(runner)
+	svc.emitEvent = noopEmit
+
+	jobID, err := svc.StartSessionRestore("/dev/sr0", 2, []string{"/docs/b.txt"}, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job := waitForJob(t, svc, jobID); job.State != models.BurnStateError || !strings.Contains(job.Error, "not found") {
+		t.Errorf("job = %+v", job)
+	}
+}
+
+// waitForJob ждёт завершения фоновой задачи BurnService
+func waitForJob(t *testing.T, svc *BurnService, jobID string) models.BurnJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := svc.GetJobStatus(jobID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		svc.mu.Lock()
+		snapshot := *job
+		svc.mu.Unlock()
+		if !snapshot.FinishedAt.IsZero() {
+			return snapshot
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("job did not finish")
+	return models.BurnJob{}
+}
@@ -1,11 +1,49 @@
 package services
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"xorriso-ui/pkg/models"
+
+	"github.com/google/uuid"
 )
 
+// startJob регистрирует новую задачу, если другая не выполняется. Задача и её
+// функция отмены появляются под одной блокировкой, чтобы CancelBurn видел обе.
+func (s *BurnService) startJob() (string, context.Context, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.jobActiveLocked() {
+		return "", nil, fmt.Errorf("operation already in progress")
+	}
+	jobID := uuid.New().String()
+	s.currentJob = &models.BurnJob{
+		ID:        jobID,
+		State:     models.BurnStatePending,
+		StartedAt: time.Now(),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancelFn = cancel
+	return jobID, ctx, nil
+}
+
+// releaseJob снимает регистрацию задачи, которая так и не началась; её идентификатор
+// никому не передавался, поэтому задача просто убирается
+func (s *BurnService) releaseJob(jobID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.currentJob == nil || s.currentJob.ID != jobID {
+		return
+	}
+	s.currentJob = nil
+	if s.cancelFn != nil {
+		s.cancelFn()
+		s.cancelFn = nil
+	}
+}
+
 func (s *BurnService) updateState(jobID string, state models.BurnState) {
 	s.mu.Lock()
 	if s.currentJob != nil && s.currentJob.ID == jobID {
@@ -34,6 +72,20 @@ func (s *BurnService) finishJob(jobID string, state models.BurnState, result *mo
 	}
 }
 
+// rememberBurn сохраняет результат задачи в библиотеке проектов
+func (s *BurnService) rememberBurn(project *models.Project, target string, jobID string) {
+	s.mu.Lock()
+	job := s.currentJob
+	if job == nil || job.ID != jobID {
+		s.mu.Unlock()
+		return
+	}
+	burn := lastBurnOf(job, target)
+	s.mu.Unlock()
+
+	recordLibraryBurn(project.FilePath, burn)
+}
+
 // emitLog sends a single log message via Wails event
 func (s *BurnService) emitLog(msg string) {
 	s.emitEvent(models.EventBurnLogLine, msg)
@@ -45,3 +97,25 @@ func (s *BurnService) emitLogLines(lines []string) {
 		s.emitEvent(models.EventBurnLogLine, line)
 	}
 }
+
+// setJobProgress обновляет прогресс задачи, которую выполняет не xorriso или чей
+// прогресс xorriso не сообщает в процентах
+func (s *BurnService) setJobProgress(jobID, phase string, done, total int64, speed string) {
+	progress := models.BurnProgress{
+		Phase:        phase,
+		BytesWritten: done,
+		BytesTotal:   total,
+		Speed:        speed,
+	}
+	if total > 0 {
+		progress.Percent = min(100, float64(done)*100/float64(total))
+	}
+
+	s.mu.Lock()
+	if s.currentJob != nil && s.currentJob.ID == jobID {
+		s.currentJob.Progress = progress
+	}
+	s.mu.Unlock()
+
+	s.emitEvent(models.EventBurnProgress, progress)
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"xorriso-ui/pkg/models"
+	"xorriso-ui/pkg/xorriso"
+)
+
+// Чтение последней сессии с оптического диска может занять время на раскрутку и загрузку дерева
+const incrementalScanTimeout = 5 * time.Minute
+
+// IncrementalPreview describes the next session of an incremental multisession burn:
+// what changed between the last session on the disc and the project sources.
+// Files changed without a change of size are found by xorriso only during the write.
+type IncrementalPreview struct {
+	Changes      []SourceChange `json:"changes"` // SourceAdded, SourceModified, SourceRemoved
+	DataBytes    int64          `json:"dataBytes"`
+	SessionBytes int64          `json:"sessionBytes"` // оценка новой сессии: данные и дерево каталогов
+	FreeBytes    int64          `json:"freeBytes"`
+	FirstSession bool           `json:"firstSession"` // на диске ещё нет данных — записывается весь проект
+	Command      string         `json:"command"`
+}
+
+// incrementalPlan — команды -update_r и сопутствующие им исключения и удаления
+type incrementalPlan struct {
+	roots    []models.FileEntry // записи, источник которых обновляется через -update_r
+	mkdirs   []string
+	notPaths []string // файлы на диске внутри каталогов-источников, которых нет в проекте
+	remove   []string // пути сессии вне обновляемых каталогов, которых нет в проекте
+	preview  IncrementalPreview
+}
+
+// diskFile — ожидаемый узел новой сессии
+type diskFile struct {
+	source string
+	isDir  bool
+	size   int64
+}
+
+// PreviewIncremental loads the last session of the disc in the drive and shows
+// which files the next incremental session would add, update and delete
+func (s *BurnService) PreviewIncremental(project *models.Project, devicePath string, opts models.BurnOptions) (*IncrementalPreview, error) {
+	plan, err := s.planIncremental(project, devicePath)
+	if err != nil {
+		return nil, err
+	}
+	plan.preview.Command = xorriso.ShellJoin(append([]string{"xorriso"}, plan.command(project, devicePath, opts).Build()...))
+	return &plan.preview, nil
+}
+
+// StartIncrementalBurn writes a new session with only the files that changed since
+// the last session on the disc, using xorriso -update_r. Files missing from the
+// project are deleted in the new session. The disc stays open unless CloseDisc is set.
+func (s *BurnService) StartIncrementalBurn(project *models.Project, devicePath string, opts models.BurnOptions) (string, error) {
+	if err := validateBurnOptions(opts); err != nil {
+		return "", err
+	}
+	if err := validateLargeFiles(project); err != nil {
+		return "", err
+	}
+	if len(project.Entries) == 0 {
+		return "", fmt.Errorf("project has no entries")
+	}
+
+	// Привод занимается до чтения последней сессии, чтобы другая задача не началась,
+	// пока xorriso читает диск
+	jobID, ctx, err := s.startJob()
+	if err != nil {
+		return "", err
+	}
+	plan, err := s.planIncremental(project, devicePath)
+	if err == nil && plan.preview.FreeBytes > 0 && plan.preview.SessionBytes > plan.preview.FreeBytes {
+		err = fmt.Errorf("new session needs %d bytes, only %d bytes free on the disc",
+			plan.preview.SessionBytes, plan.preview.FreeBytes)
+	}
+	if err != nil {
+		s.releaseJob(jobID)
+		return "", err
+	}
+	cmd := plan.command(project, devicePath, opts)
+
+	go s.runBurn(ctx, project, devicePath, opts, jobID, cmd)
+
+	return jobID, nil
+}
+
+// planIncremental читает дерево последней сессии и свободное место на диске
+func (s *BurnService) planIncremental(project *models.Project, devicePath string) (*incrementalPlan, error) {
+	if project.BaseImage != nil {
+		return nil, fmt.Errorf("incremental burns are not available for a project based on an existing image")
+	}
+	if err := validateVolumeDescriptor(project.VolumeID, project.ISOOptions); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), incrementalScanTimeout)
+	defer cancel()
+
+	cmd := xorriso.NewCommand().Device(devicePath).TellMediaSpace().FindExec("/", "lsdl")
+	result, err := s.executor.Run(ctx, cmd.Build()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the last session: %w", err)
+	}
+	if result.ExitCode != 0 {
+		errMsg := fmt.Sprintf("xorriso exited with code %d", result.ExitCode)
+		if len(result.InfoLines) > 0 {
+			errMsg = result.InfoLines[len(result.InfoLines)-1]
+		}
+		return nil, fmt.Errorf("failed to read the last session: %s", errMsg)
+	}
+
+	plan := buildIncrementalPlan(project, xorriso.ParseLsdl(result.ResultLines))
+	freeBlocks, _ := xorriso.ParseMediaSpace(result.ResultLines)
+	plan.preview.FreeBytes = freeBlocks * models.BlockSizeBytes
+	return plan, nil
+}
+
+// command формирует запись новой сессии поверх загруженной последней
+func (p *incrementalPlan) command(project *models.Project, devicePath string, opts models.BurnOptions) *xorriso.CommandBuilder {
+	cmd := xorriso.NewCommand()
+	// -dev загружает последнюю сессию и пишет новую на тот же носитель
+	cmd.Device(devicePath)
+	cmd.AbortOn("FAILURE")
+
+	buildISOOptions(cmd, project)
+	// Номера устройств и inode ускоряют сравнение при следующих обновлениях
+	cmd.DiskDevIno("on")
+
+	if len(p.notPaths) > 0 {
+		cmd.NotPaths(p.notPaths...)
+	}
+	if len(p.remove) > 0 {
+		cmd.RmR(p.remove...)
+	}
+	if len(p.mkdirs) > 0 {
+		cmd.Mkdir(p.mkdirs...)
+	}
+	for _, root := range p.roots {
+		cmd.UpdateR(root.SourcePath, root.DestPath)
+	}
+	buildLayout(cmd, project)
+	// Без CloseDisc диск остаётся открытым для следующих сессий
+	buildWriteOptions(cmd, opts)
+
+	cmd.Commit()
+	return cmd
+}
+
+// buildIncrementalPlan сравнивает дерево сессии с источниками проекта
+func buildIncrementalPlan(project *models.Project, session []models.ISOFile) *incrementalPlan {
+	plan := &incrementalPlan{}
+	byDest := make(map[string]models.FileEntry, len(project.Entries))
+	for _, e := range project.Entries {
+		byDest[e.DestPath] = e
+	}
+
+	// Запись на своём месте внутри каталога-источника родителя обновляется вместе с ним
+	inPlace := func(e models.FileEntry) bool {
+		parent, ok := byDest[path.Dir(e.DestPath)]
+		return ok && parent.IsDir && parent.Type == "" && parent.SourcePath != "" &&
+			e.SourcePath == filepath.Join(parent.SourcePath, path.Base(e.DestPath))
+	}
+
+	expected := make(map[string]diskFile)
+	var walk func(src, dest string, whole bool)
+	walk = func(src, dest string, whole bool) {
+		items, err := os.ReadDir(src)
+		if err != nil {
+			return
+		}
+		for _, item := range items {
+			p := filepath.Join(src, item.Name())
+			d := path.Join(dest, item.Name())
+			if !whole {
+				if e, ok := byDest[d]; !ok || e.SourcePath != p {
+					// Нет в проекте, исключён или перенесён в другое место образа
+					plan.notPaths = append(plan.notPaths, p)
+					continue
+				}
+			}
+			info, err := item.Info()
+			if err != nil {
+				continue
+			}
+			expected[d] = diskFile{source: p, isDir: item.IsDir(), size: fileDataSize(info)}
+			if item.IsDir() {
+				walk(p, d, whole || !byDest[d].Listed)
+			}
+		}
+	}
+
+	var rootDirs []string
+	for _, e := range project.Entries {
+		switch {
+		case e.SourcePath == "":
+			plan.mkdirs = append(plan.mkdirs, e.DestPath)
+			expected[e.DestPath] = diskFile{isDir: true}
+		case !inPlace(e):
+			plan.roots = append(plan.roots, e)
+			info, err := os.Lstat(e.SourcePath)
+			if err != nil {
+				// Источник пропал — -update_r удалит путь из образа
+				continue
+			}
+			expected[e.DestPath] = diskFile{source: e.SourcePath, isDir: info.IsDir(), size: fileDataSize(info)}
+			if info.IsDir() {
+				rootDirs = append(rootDirs, e.DestPath)
+				walk(e.SourcePath, e.DestPath, !e.Listed)
+			}
+		}
+	}
+	// Промежуточные каталоги образа создаются автоматически
+	for dest := range expected {
+		for dir := path.Dir(dest); dir != "/" && dir != "."; dir = path.Dir(dir) {
+			if _, ok := expected[dir]; !ok {
+				expected[dir] = diskFile{isDir: true}
+			}
+		}
+	}
+
+	inSession := make(map[string]models.ISOFile, len(session))
+	for _, f := range session {
+		if f.Path != "/" {
+			inSession[f.Path] = f
+		}
+	}
+	preview := &plan.preview
+	preview.Changes = []SourceChange{}
+	preview.FirstSession = len(inSession) == 0
+
+	for _, dest := range slices.Sorted(maps.Keys(expected)) {
+		want := expected[dest]
+		if want.isDir {
+			continue
+		}
+		have, ok := inSession[dest]
+		switch {
+		case !ok:
+			preview.Changes = append(preview.Changes, SourceChange{
+				Kind: SourceAdded, DestPath: dest, SourcePath: want.source, NewSize: want.size,
+			})
+		case have.IsDir || have.Size != want.size:
+			preview.Changes = append(preview.Changes, SourceChange{
+				Kind: SourceModified, DestPath: dest, SourcePath: want.source, OldSize: have.Size, NewSize: want.size,
+			})
+		default:
+			continue
+		}
+		preview.DataBytes += want.size
+		preview.SessionBytes += roundToBlock(want.size)
+	}
+
+	for _, dest := range slices.Sorted(maps.Keys(inSession)) {
+		if _, ok := expected[dest]; ok {
+			continue
+		}
+		if f := inSession[dest]; !f.IsDir {
+			preview.Changes = append(preview.Changes, SourceChange{Kind: SourceRemoved, DestPath: dest, OldSize: f.Size})
+		}
+		// Внутри обновляемых каталогов удаление выполнит -update_r; удаляем только верхний путь
+		parent := path.Dir(dest)
+		_, parentKept := expected[parent]
+		underRoot := slices.ContainsFunc(rootDirs, func(dir string) bool { return isUnderPath(dest, dir) })
+		if (parent == "/" || parentKept) && !underRoot {
+			plan.remove = append(plan.remove, dest)
+		}
+	}
+
+	slices.SortStableFunc(preview.Changes, func(a, b SourceChange) int { return cmp.Compare(a.DestPath, b.DestPath) })
+	// Каждая сессия заново записывает дерево каталогов целиком
+	preview.SessionBytes += imageFixedOverhead + int64(len(expected))*imageEntryOverhead
+	return plan
+}
+
+// fileDataSize — объём данных узла; у каталогов, ссылок и специальных файлов он нулевой
+func fileDataSize(info os.FileInfo) int64 {
+	if info.Mode().IsRegular() {
+		return info.Size()
+	}
+	return 0
+}
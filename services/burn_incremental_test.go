@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"xorriso-ui/pkg/models"
+	"xorriso-ui/pkg/xorriso"
+)
+
+// newIncrementalProject создаёт проект с каталогом docs из двух файлов и файлом вне проекта
+func newIncrementalProject(t *testing.T) (*models.Project, string) {
+	t.Helper()
+	src := filepath.Join(t.TempDir(), "docs")
+	os.MkdirAll(src, 0755)
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("aa"), 0644)
+	os.WriteFile(filepath.Join(src, "b.txt"), []byte("bbb"), 0644)
+
+	svc := NewProjectService()
+	svc.emitEvent = noopEmit
+	project := svc.NewProject("Docs", "DOCS")
+	svc.AddFiles(project, []string{src}, "/")
+	svc.CreateFolder(project, "/", "empty")
+	// Появился после добавления — в новую сессию не попадает
+	os.WriteFile(filepath.Join(src, "extra.txt"), []byte("x"), 0644)
+	return project, src
+}
+
+var incrementalSession = []string{
+	"drwxr-xr-x    1 0        0               0 Oct 18 12:00 '/'",
+	"drwxr-xr-x    1 1000     1000            0 Oct 18 12:00 '/docs'",
+	"-rw-r--r--    1 1000     1000            1 Oct 18 12:00 '/docs/a.txt'",
+	"-rw-r--r--    1 1000     1000            7 Oct 18 12:00 '/docs/old.txt'",
+	"drwxr-xr-x    1 1000     1000            0 Oct 18 12:00 '/gone'",
+	"-rw-r--r--    1 1000     1000            4 Oct 18 12:00 '/gone/x.txt'",
+}
+
+func TestBuildIncrementalPlan(t *testing.T) {
+	project, src := newIncrementalProject(t)
+	plan := buildIncrementalPlan(project, xorriso.ParseLsdl(incrementalSession))
+
+	if len(plan.roots) != 1 || plan.roots[0].DestPath != "/docs" {
+		t.Errorf("roots = %+v", plan.roots)
+	}
+	if !slices.Equal(plan.mkdirs, []string{"/empty"}) {
+		t.Errorf("mkdirs = %v", plan.mkdirs)
+	}
+	if !slices.Equal(plan.notPaths, []string{filepath.Join(src, "extra.txt")}) {
+		t.Errorf("notPaths = %v", plan.notPaths)
+	}
+	// Удаления внутри /docs выполняет -update_r
+	if !slices.Equal(plan.remove, []string{"/gone"}) {
+		t.Errorf("remove = %v", plan.remove)
+	}
+
+	want := map[string]string{
+		"/docs/a.txt":   SourceModified,
+		"/docs/b.txt":   SourceAdded,
+		"/docs/old.txt": SourceRemoved,
+		"/gone/x.txt":   SourceRemoved,
+	}
+	got := changeKinds(&SourceReport{Changes: plan.preview.Changes})
+	if len(got) != len(want) {
+		t.Errorf("changes = %v, want %v", got, want)
+	}
+	for dest, kind := range want {
+		if got[dest] != kind {
+			t.Errorf("%s: kind = %q, want %q", dest, got[dest], kind)
+		}
+	}
+	if plan.preview.DataBytes != 5 || plan.preview.FirstSession {
+		t.Errorf("preview = %+v", plan.preview)
+	}
+	if plan.preview.SessionBytes < imageFixedOverhead+2*models.BlockSizeBytes {
+		t.Errorf("SessionBytes = %d", plan.preview.SessionBytes)
+	}
+}
+
+func TestBuildIncrementalPlan_FirstSession(t *testing.T) {
+	project, _ := newIncrementalProject(t)
+	plan := buildIncrementalPlan(project, xorriso.ParseLsdl(incrementalSession[:1]))
+
+	if !plan.preview.FirstSession || len(plan.remove) != 0 {
+		t.Errorf("plan = %+v", plan)
+	}
+	for _, c := range plan.preview.Changes {
+		if c.Kind != SourceAdded {
+			t.Errorf("blank disc change = %+v", c)
+		}
+	}
+}
+
+func TestPreviewIncremental(t *testing.T) {
+	project, src := newIncrementalProject(t)
+	var scanArgs []string
+	runner := &mockRunner{
+		RunFn: func(ctx context.Context, args ...string) (*xorriso.CmdResult, error) {
+			scanArgs = args
+			return &xorriso.CmdResult{ResultLines: append([]string{"Media space  : 1000s"}, incrementalSession...)}, nil
+		},
+	}
+	svc := NewBurnService(runner)
+	svc.emitEvent = noopEmit
+
+	preview, err := svc.PreviewIncremental(project, "/dev/sr0", models.BurnOptions{})
+	if err != nil {
+		t.Fatalf("PreviewIncremental: %v", err)
+	}
+	if !containsSequence(scanArgs, "-dev", "/dev/sr0") || !containsSequence(scanArgs, "-find", "/", "-exec", "lsdl", "--") {
+		t.Errorf("scan args = %v", scanArgs)
+	}
+	if preview.FreeBytes != 1000*models.BlockSizeBytes || len(preview.Changes) != 4 {
+		t.Errorf("preview = %+v", preview)
+	}
+	for _, part := range []string{
+		"-disk_dev_ino on",
+		"-not_paths " + filepath.Join(src, "extra.txt") + " --",
+		"-rm_r /gone --",
+		"-mkdir /empty",
+		"-update_r " + src + " /docs",
+		"-close off",
+		"-commit",
+	} {
+		if !strings.Contains(preview.Command, part) {
+			t.Errorf("command %q lacks %q", preview.Command, part)
+		}
+	}
+
+	preview, _ = svc.PreviewIncremental(project, "/dev/sr0", models.BurnOptions{CloseDisc: true})
+	if !strings.Contains(preview.Command, "-close on") {
+		t.Errorf("closing command = %q", preview.Command)
+	}
+}
+
+func TestStartIncrementalBurn_NoSpace(t *testing.T) {
+	project, _ := newIncrementalProject(t)
+	runner := &mockRunner{
+		RunFn: func(ctx context.Context, args ...string) (*xorriso.CmdResult, error) {
+			return &xorriso.CmdResult{ResultLines: append([]string{"Media space  : 1s"}, incrementalSession...)}, nil
+		},
+		RunWithProgressFn: func(ctx context.Context, progressFn func(xorriso.Progress), args ...string) (*xorriso.CmdResult, error) {
+			t.Error("burn must not start without space on the disc")
+			return &xorriso.CmdResult{}, nil
+		},
+	}
+	svc := NewBurnService(runner)
+	svc.emitEvent = noopEmit
+
+	if _, err := svc.StartIncrementalBurn(project, "/dev/sr0", models.BurnOptions{}); err == nil {
+		t.Fatal("expected an error for a full disc")
+	}
+	if svc.currentJob != nil {
+		t.Errorf("job created: %+v", svc.currentJob)
+	}
+}
+
+func TestPreviewIncremental_ScanError(t *testing.T) {
+	project, _ := newIncrementalProject(t)
+	runner := &mockRunner{
+		RunFn: func(ctx context.Context, args ...string) (*xorriso.CmdResult, error) {
+			return &xorriso.CmdResult{ExitCode: 5, InfoLines: []string{"libburn : SORRY : No medium present"}}, nil
+		},
+	}
+	svc := NewBurnService(runner)
+
+	_, err := svc.PreviewIncremental(project, "/dev/sr0", models.BurnOptions{})
+	if err == nil || !strings.Contains(err.Error(), "No medium present") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestStartIncrementalBurn_ReservesDrive(t *testing.T) {
+	project, _ := newIncrementalProject(t)
+	svc := NewBurnService(nil)
+	svc.emitEvent = noopEmit
+
+	// Пока xorriso читает последнюю сессию, другая задача начаться не может
+	scanned := false
+	svc.executor = &mockRunner{
+		RunFn: func(ctx context.Context, args ...string) (*xorriso.CmdResult, error) {
+			scanned = true
+			if _, _, err := svc.startJob(); err == nil {
+				t.Error("another job started while the disc was being read")
+			}
+			return &xorriso.CmdResult{ExitCode: 5, InfoLines: []string{"libburn : SORRY : No medium present"}}, nil
+		},
+	}
+
+	if _, err := svc.StartIncrementalBurn(project, "/dev/sr0", models.BurnOptions{}); err == nil {
+		t.Fatal("expected the scan error")
+	}
+	if !scanned || svc.currentJob != nil {
+		t.Errorf("scanned = %v, job = %+v", scanned, svc.currentJob)
+	}
+
+	// Во время выполняющейся задачи диск даже не читается
+	scanned = false
+	svc.startJob()
+	if _, err := svc.StartIncrementalBurn(project, "/dev/sr0", models.BurnOptions{}); err == nil || scanned {
+		t.Errorf("err = %v, scanned = %v", err, scanned)
+	}
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"xorriso-ui/pkg/models"
+	"xorriso-ui/pkg/xorriso"
+)
+
+// OpenImageProject reads the file tree of an existing ISO image and returns a new
+// project that starts from it. Writing such a project loads the image and produces
+// a new one with the project's removals, renames and additions; the boot setup of
+// the image is kept unless BaseImage.KeepBoot is cleared. The result is not saved.
+func (s *BurnService) OpenImageProject(imagePath string) (*models.Project, error) {
+	imagePath, err := filepath.Abs(imagePath)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(imagePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open image: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("not an image file: %s", imagePath)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sessionListTimeout)
+	defer cancel()
+	cmd := xorriso.NewCommand().InDevice(imagePath).PVDInfo().FindExec("/", "lsdl")
+	result, err := s.executor.Run(ctx, cmd.Build()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	files := xorriso.ParseLsdl(result.ResultLines)
+	if result.ExitCode != 0 || len(files) == 0 {
+		errMsg := fmt.Sprintf("xorriso exited with code %d", result.ExitCode)
+		if len(result.InfoLines) > 0 {
+			errMsg = result.InfoLines[len(result.InfoLines)-1]
+		}
+		return nil, fmt.Errorf("failed to read image: %s", errMsg)
+	}
+
+	var volumeID string
+	for _, line := range result.ResultLines {
+		if strings.Contains(line, "Volume Id    :") {
+			volumeID = extractAfterColon(line)
+		}
+	}
+
+	name := strings.TrimSuffix(filepath.Base(imagePath), filepath.Ext(imagePath))
+	project := newProject(name, volumeID)
+	project.BaseImage = &models.BaseImage{Path: imagePath, KeepBoot: true}
+	// Иначе новый образ потеряет длинные имена и атрибуты исходного
+	project.ISOOptions.RockRidge = true
+	project.ISOOptions.Joliet = true
+	project.Entries = imageEntries(files)
+	return project, nil
+}
+
+// imageEntries превращает дерево образа в записи проекта, родители раньше детей
+func imageEntries(files []models.ISOFile) []models.FileEntry {
+	files = slices.Clone(files)
+	slices.SortFunc(files, func(a, b models.ISOFile) int { return cmp.Compare(a.Path, b.Path) })
+
+	entries := make([]models.FileEntry, 0, len(files))
+	for _, f := range files {
+		if f.Path == "/" {
+			continue
+		}
+		entry := models.FileEntry{
+			DestPath:  f.Path,
+			Name:      f.Name,
+			IsDir:     f.IsDir,
+			Listed:    f.IsDir, // содержимое каталога образа — его собственные записи
+			Type:      f.Type,
+			ModTime:   f.ModTime,
+			ImagePath: f.Path,
+		}
+		if !f.IsDir {
+			entry.Size = f.Size
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// modifyCommand читает текущее дерево базового образа и формирует команду записи
+// изменённого образа на outdev; opts задаются только для записи на привод
+func (s *BurnService) modifyCommand(project *models.Project, outdev string, opts *models.BurnOptions) (*xorriso.CommandBuilder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sessionListTimeout)
+	defer cancel()
+	base, err := listSessionFiles(ctx, s.executor, project.BaseImage.Path, 0)
+	if err != nil {
+		return nil, err
+	}
+	return buildModifyCommand(project, base, outdev, opts)
+}
+
+// buildModifyCommand сравнивает записи проекта с деревом базового образа. Узлы образа,
+// которых нет в проекте, удаляются; перемещённые и переименованные переносятся через -mv,
+// повторные записи одного узла копируются внутри образа; файлы с диска добавляются как обычно.
+func buildModifyCommand(project *models.Project, base []models.ISOFile, outdev string, opts *models.BurnOptions) (*xorriso.CommandBuilder, error) {
+	inBase := make(map[string]bool, len(base))
+	for _, f := range base {
+		inBase[f.Path] = true
+	}
+
+	// Первая запись с путём узла — сам узел, остальные — его копии
+	primary := make(map[string]models.FileEntry)
+	for _, e := range project.Entries {
+		if e.ImagePath == "" {
+			continue
+		}
+		if !inBase[e.ImagePath] {
+			return nil, fmt.Errorf("%s is no longer in the base image", e.ImagePath)
+		}
+		if _, ok := primary[e.ImagePath]; !ok {
+			primary[e.ImagePath] = e
+		}
+	}
+
+	// Каталоги, внутри которых остаются узлы образа
+	keepsNodes := map[string]bool{"/": true}
+	for p := range primary {
+		for d := path.Dir(p); !keepsNodes[d]; d = path.Dir(d) {
+			keepsNodes[d] = true
+		}
+	}
+
+	// Удаляются верхние из убранных узлов; каталог, из которого ещё нужно
+	// перенести оставшиеся узлы, удаляется после переноса
+	var remove, hollow []string
+	for _, f := range base {
+		if _, kept := primary[f.Path]; kept || f.Path == "/" {
+			continue
+		}
+		if keepsNodes[f.Path] {
+			hollow = append(hollow, f.Path)
+			continue
+		}
+		parent := path.Dir(f.Path)
+		if _, kept := primary[parent]; kept || keepsNodes[parent] {
+			remove = append(remove, f.Path)
+		}
+	}
+	slices.Sort(remove)
+
+	cmd := xorriso.NewCommand()
+	cmd.InDevice(project.BaseImage.Path)
+	cmd.OutDevice(outdev)
+	cmd.AbortOn("FAILURE")
+	if project.BaseImage.KeepBoot {
+		cmd.BootImage("any", "keep")
+	} else {
+		cmd.BootImage("any", "discard")
+	}
+	// После -indev: загрузка образа заменяет метку тома и другие поля заголовка
+	buildISOOptions(cmd, project)
+	if len(remove) > 0 {
+		cmd.RmR(remove...)
+	}
+
+	byDest := make(map[string]models.FileEntry, len(project.Entries))
+	for _, e := range project.Entries {
+		byDest[e.DestPath] = e
+	}
+	byDepth := func(entries []models.FileEntry) {
+		slices.SortStableFunc(entries, func(a, b models.FileEntry) int {
+			return cmp.Compare(strings.Count(a.DestPath, "/"), strings.Count(b.DestPath, "/"))
+		})
+	}
+
+	// Переносим узлы образа до добавления файлов, чтобы новые файлы не заняли их
+	// прежние места. Положение узлов меняется вместе с перенесёнными каталогами.
+	loc := make(map[string]string, len(primary))
+	moving := make([]models.FileEntry, 0, len(primary))
+	for p, e := range primary {
+		loc[p] = p
+		moving = append(moving, e)
+	}
+	slices.SortFunc(moving, func(a, b models.FileEntry) int { return cmp.Compare(a.DestPath, b.DestPath) })
+	byDepth(moving)
+
+	created := make(map[string]bool)
+	for _, e := range moving {
+		from := loc[e.ImagePath]
+		if from == e.DestPath {
+			continue
+		}
+		for _, l := range loc {
+			if l == e.DestPath {
+				return nil, fmt.Errorf("cannot move %s to %s: the path is still in use in the image", from, e.DestPath)
+			}
+		}
+		if slices.Contains(hollow, e.DestPath) {
+			return nil, fmt.Errorf("cannot move %s to %s: the path is still in use in the image", from, e.DestPath)
+		}
+
+		// Каталог назначения, которого нет в образе, создаём заранее
+		var missing []string
+		for d := path.Dir(e.DestPath); d != "/" && !created[d]; d = path.Dir(d) {
+			if parent, ok := byDest[d]; !ok || parent.ImagePath != "" && primary[parent.ImagePath].DestPath == d {
+				break
+			}
+			missing = append(missing, d)
+		}
+		for _, d := range slices.Backward(missing) {
+			cmd.Mkdir(d)
+			created[d] = true
+		}
+
+		cmd.Mv(from, e.DestPath)
+		for p, l := range loc {
+			if isUnderPath(l, from) {
+				loc[p] = e.DestPath + strings.TrimPrefix(l, from)
+			}
+		}
+	}
+
+	// Опустевшие каталоги, из которых перенесены оставшиеся узлы, глубокие первыми
+	slices.SortFunc(hollow, func(a, b string) int {
+		return cmp.Compare(strings.Count(b, "/"), strings.Count(a, "/"))
+	})
+	if len(hollow) > 0 {
+		cmd.Rmdir(hollow...)
+	}
+
+	// Остальные записи — родители раньше детей
+	rest := slices.DeleteFunc(slices.Clone(project.Entries), func(e models.FileEntry) bool {
+		return e.ImagePath != "" && primary[e.ImagePath].DestPath == e.DestPath
+	})
+	byDepth(rest)
+	for _, e := range rest {
+		switch {
+		case created[e.DestPath] && (e.SourcePath == "" || e.Listed):
+			// Каталог уже создан перед переносом узлов в него
+		case e.ImagePath != "" && !e.IsDir:
+			cmd.CpRx(loc[e.ImagePath], e.DestPath)
+		case e.SourcePath == "":
+			// Виртуальная папка или копия каталога образа: содержимое — отдельные записи
+			cmd.Mkdir(e.DestPath)
+		case e.IsDir && e.Listed:
+			cmd.MapSingle(e.SourcePath, e.DestPath)
+		default:
+			cmd.Map(e.SourcePath, e.DestPath)
+		}
+	}
+
+	buildLayout(cmd, project)
+	if opts != nil {
+		buildWriteOptions(cmd, *opts)
+	}
+	cmd.Commit()
+	return cmd, nil
+}
+
+// sameFile сообщает, указывают ли пути на один файл; несуществующие пути сравниваются как строки
+func sameFile(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	if errA == nil && errB == nil {
+		return os.SameFile(infoA, infoB)
+	}
+	absA, _ := filepath.Abs(a)
+	absB, _ := filepath.Abs(b)
+	return absA == absB
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"xorriso-ui/pkg/models"
+	"xorriso-ui/pkg/xorriso"
+)
+
+var baseImageTree = []string{
+	"dr-xr-xr-x    1 0        0               0 Oct 18 12:00 '/'",
+	"dr-xr-xr-x    1 0        0            2048 Oct 18 12:00 '/boot'",
+	"-r--r--r--    1 0        0           32768 Oct 18 12:00 '/boot/boot.img'",
+	"dr-xr-xr-x    1 0        0               0 Oct 18 12:00 '/docs'",
+	"-r--r--r--    1 0        0              10 Oct 18 12:00 '/docs/a.txt'",
+	"-r--r--r--    1 0        0              20 Oct 18 12:00 '/docs/b.txt'",
+	"dr-xr-xr-x    1 0        0               0 Oct 18 12:00 '/old'",
+	"-r--r--r--    1 0        0              30 Oct 18 12:00 '/old/keep.txt'",
+	"-r--r--r--    1 0        0              40 Oct 18 12:00 '/old/junk.txt'",
+	"-r--r--r--    1 0        0               5 Oct 18 12:00 '/readme.txt'",
+}
+
+// newBaseImageProject создаёт проект из дерева baseImageTree
+func newBaseImageProject(t *testing.T) (*models.Project, *ProjectService) {
+	t.Helper()
+	image := filepath.Join(t.TempDir(), "base.iso")
+	os.WriteFile(image, []byte("iso"), 0644)
+
+	runner := &mockRunner{
+		RunFn: func(ctx context.Context, args ...string) (*xorriso.CmdResult, error) {
+			lines := append([]string{"Volume Id    : BASE_DISC"}, baseImageTree...)
+			return &xorriso.CmdResult{ResultLines: lines}, nil
+		},
+	}
+	project, err := NewBurnService(runner).OpenImageProject(image)
+	if err != nil {
+		t.Fatalf("OpenImageProject: %v", err)
+	}
+	svc := NewProjectService()
+	svc.emitEvent = noopEmit
+	return project, svc
+}
+
+func TestOpenImageProject(t *testing.T) {
+	var args []string
+	image := filepath.Join(t.TempDir(), "install.iso")
+	os.WriteFile(image, []byte("iso"), 0644)
+	runner := &mockRunner{
+		RunFn: func(ctx context.Context, a ...string) (*xorriso.CmdResult, error) {
+			args = a
+			lines := append([]string{"Volume Id    : BASE_DISC"}, baseImageTree...)
+			return &xorriso.CmdResult{ResultLines: lines}, nil
+		},
+	}
+
+	project, err := NewBurnService(runner).OpenImageProject(image)
+	if err != nil {
+		t.Fatalf("OpenImageProject: %v", err)
+	}
+	if !containsSequence(args, "-indev", image) || !slices.Contains(args, "-pvd_info") {
+		t.Errorf("args = %v", args)
+	}
+	if project.Name != "install" || project.VolumeID != "BASE_DISC" {
+		t.Errorf("name = %q, volume id = %q", project.Name, project.VolumeID)
+	}
+	if !project.ISOOptions.RockRidge || !project.ISOOptions.Joliet {
+		t.Errorf("ISOOptions = %+v", project.ISOOptions)
+	}
+	if project.BaseImage == nil || project.BaseImage.Path != image || !project.BaseImage.KeepBoot {
+		t.Errorf("BaseImage = %+v", project.BaseImage)
+	}
+	if len(project.Entries) != len(baseImageTree)-1 {
+		t.Fatalf("entries = %d, want %d", len(project.Entries), len(baseImageTree)-1)
+	}
+	for _, e := range project.Entries {
+		if e.ImagePath != e.DestPath || e.SourcePath != "" {
+			t.Errorf("entry = %+v", e)
+		}
+		if e.DestPath == "/boot" && e.Size != 0 {
+			t.Errorf("directory size = %d", e.Size)
+		}
+	}
+	if got := entriesDataSize(project.Entries); got != 32768+10+20+30+40+5 {
+		t.Errorf("data size = %d", got)
+	}
+}
+
+func TestOpenImageProject_Errors(t *testing.T) {
+	svc := NewBurnService(&mockRunner{
+		RunFn: func(ctx context.Context, args ...string) (*xorriso.CmdResult, error) {
+			return &xorriso.CmdResult{ExitCode: 32, InfoLines: []string{"libisofs: FAILURE : Not a valid ISO image"}}, nil
+		},
+	})
+	if _, err := svc.OpenImageProject(filepath.Join(t.TempDir(), "missing.iso")); err == nil {
+		t.Error("expected error for missing image")
+	}
+	if _, err := svc.OpenImageProject(t.TempDir()); err == nil {
+		t.Error("expected error for a directory")
+	}
+
+	image := filepath.Join(t.TempDir(), "bad.iso")
+	os.WriteFile(image, []byte("not iso"), 0644)
+	_, err := svc.OpenImageProject(image)
+	if err == nil || !strings.Contains(err.Error(), "Not a valid ISO image") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestBuildModifyCommand(t *testing.T) {
+	project, svc := newBaseImageProject(t)
+	src := filepath.Join(t.TempDir(), "new.txt")
+	os.WriteFile(src, []byte("new"), 0644)
+
+	svc.RemoveEntries(project, []string{"/readme.txt", "/old/junk.txt"})
+	svc.RenameEntry(project, "/docs", "papers")
+	svc.CreateFolder(project, "/", "kept")
+	svc.MoveEntries(project, []string{"/old/keep.txt"}, "/kept")
+	svc.RemoveEntry(project, "/old")
+	svc.CopyEntries(project, []string{"/papers/a.txt"}, "/kept")
+	svc.AddFiles(project, []string{src}, "/")
+
+	cmd, err := buildModifyCommand(project, xorriso.ParseLsdl(baseImageTree), "stdio:/tmp/out.iso", nil)
+	if err != nil {
+		t.Fatalf("buildModifyCommand: %v", err)
+	}
+	args := cmd.Build()
+	idx := func(seq ...string) int {
+		for i := 0; i+len(seq) <= len(args); i++ {
+			if slices.Equal(args[i:i+len(seq)], seq) {
+				return i
+			}
+		}
+		t.Errorf("args missing %v: %v", seq, args)
+		return -1
+	}
+
+	indev := idx("-indev", project.BaseImage.Path)
+	idx("-outdev", "stdio:/tmp/out.iso")
+	idx("-boot_image", "any", "keep")
+	volid := idx("-volid", "BASE_DISC")
+	rm := idx("-rm_r", "/old/junk.txt", "/readme.txt", "--")
+	mkdir := idx("-mkdir", "/kept", "--")
+	mvDocs := idx("-mv", "/docs", "/papers", "--")
+	mvKeep := idx("-mv", "/old/keep.txt", "/kept/keep.txt", "--")
+	rmdir := idx("-rmdir", "/old", "--")
+	cp := idx("-cp_rx", "/papers/a.txt", "/kept/a.txt", "--")
+	add := idx("-map", src, "/new.txt")
+	commit := idx("-commit")
+
+	// Метка тома задаётся после загрузки образа, узлы переносятся до добавления файлов
+	for _, order := range [][2]int{{indev, volid}, {volid, rm}, {rm, mkdir}, {mkdir, mvKeep},
+		{mvDocs, mvKeep}, {mvKeep, rmdir}, {rmdir, cp}, {rmdir, add}, {cp, commit}, {add, commit}} {
+		if order[0] > order[1] {
+			t.Errorf("wrong order %v: %v", order, args)
+		}
+	}
+	// Неизменённые узлы не трогаем
+	if slices.Contains(args, "/boot/boot.img") || strings.Count(strings.Join(args, " "), "-mkdir") != 1 {
+		t.Errorf("args = %v", args)
+	}
+}
+
+func TestBuildModifyCommand_EmptiedSourceFolder(t *testing.T) {
+	project, svc := newBaseImageProject(t)
+	src := filepath.Join(t.TempDir(), "extra")
+	os.MkdirAll(src, 0755)
+	os.WriteFile(filepath.Join(src, "c.txt"), []byte("c"), 0644)
+
+	svc.AddFiles(project, []string{src}, "/")
+	if _, err := svc.MoveEntries(project, []string{"/extra/c.txt"}, "/docs"); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd, err := buildModifyCommand(project, xorriso.ParseLsdl(baseImageTree), "stdio:/tmp/out.iso", nil)
+	if err != nil {
+		t.Fatalf("buildModifyCommand: %v", err)
+	}
+	args := cmd.Build()
+	// Папка, из которой перемещены все файлы, не переносится поверх образа целиком
+	if !containsSequence(args, "-map_single", src, "/extra") || containsSequence(args, "-map", src, "/extra") ||
+		!containsSequence(args, "-map", filepath.Join(src, "c.txt"), "/docs/c.txt") {
+		t.Errorf("args = %v", args)
+	}
+	for _, e := range project.Entries {
+		if e.ImagePath != "" && e.IsDir != e.Listed {
+			t.Errorf("image entry %s: Listed = %v", e.DestPath, e.Listed)
+		}
+	}
+}
+
+func TestBuildModifyCommand_DiscardBootAndBurnOptions(t *testing.T) {
+	project, _ := newBaseImageProject(t)
+	project.BaseImage.KeepBoot = false
+
+	cmd, err := buildModifyCommand(project, xorriso.ParseLsdl(baseImageTree), "/dev/sr0", &models.BurnOptions{Speed: "4"})
+	if err != nil {
+		t.Fatalf("buildModifyCommand: %v", err)
+	}
+	args := cmd.Build()
+	for _, seq := range [][]string{{"-outdev", "/dev/sr0"}, {"-boot_image", "any", "discard"}, {"-speed", "4"}} {
+		if !containsSequence(args, seq...) {
+			t.Errorf("args missing %v: %v", seq, args)
+		}
+	}
+	if slices.Contains(args, "-rm_r") || slices.Contains(args, "-mv") {
+		t.Errorf("unchanged project must not change the tree: %v", args)
+	}
+}
+
+func TestBuildModifyCommand_Errors(t *testing.T) {
+	project, svc := newBaseImageProject(t)
+	base := xorriso.ParseLsdl(baseImageTree)
+
+	// Узел исчез из образа после открытия проекта
+	if _, err := buildModifyCommand(project, base[:len(base)-1], "/dev/sr0", nil); err == nil ||
+		!strings.Contains(err.Error(), "/readme.txt") {
+		t.Errorf("err = %v", err)
+	}
+
+	// Обмен именами двух узлов образа за одну запись
+	svc.RenameEntry(project, "/docs/a.txt", "tmp.txt")
+	svc.RenameEntry(project, "/docs/b.txt", "a.txt")
+	svc.RenameEntry(project, "/docs/tmp.txt", "b.txt")
+	if _, err := buildModifyCommand(project, base, "/dev/sr0", nil); err == nil ||
+		!strings.Contains(err.Error(), "still in use") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestCreateISO_BaseImage(t *testing.T) {
+	project, _ := newBaseImageProject(t)
+	svc := NewBurnService(&mockRunner{})
+
+	if _, err := svc.CreateISO(project, project.BaseImage.Path); err == nil ||
+		!strings.Contains(err.Error(), "differ from the base image") {
+		t.Errorf("err = %v", err)
+	}
+	if _, err := svc.PreviewIncremental(project, "/dev/sr0", models.BurnOptions{}); err == nil {
+		t.Error("expected incremental burns to be rejected")
+	}
+}
+
+func TestGetBurnCommand_BaseImage(t *testing.T) {
+	project, _ := newBaseImageProject(t)
+	svc := NewBurnService(&mockRunner{
+		RunFn: func(ctx context.Context, args ...string) (*xorriso.CmdResult, error) {
+			return &xorriso.CmdResult{ResultLines: baseImageTree}, nil
+		},
+	})
+
+	got, err := svc.GetBurnCommand(project, "/dev/sr0", models.BurnOptions{Eject: true})
+	if err != nil {
+		t.Fatalf("GetBurnCommand: %v", err)
+	}
+	if !strings.Contains(got, "-indev "+xorriso.ShellJoin([]string{project.BaseImage.Path})+" -outdev /dev/sr0") ||
+		!strings.HasSuffix(got, "-commit -eject all") {
+		t.Errorf("command = %s", got)
+	}
+}
@@ -9,8 +9,10 @@ import (
 	"xorriso-ui/pkg/xorriso"
 )
 
-func (s *BurnService) runBurn(ctx context.Context, project *models.Project, devicePath string, opts models.BurnOptions, jobID string) {
+// runBurn выполняет подготовленную команду записи, затем проверку и извлечение диска
+func (s *BurnService) runBurn(ctx context.Context, project *models.Project, devicePath string, opts models.BurnOptions, jobID string, cmd *xorriso.CommandBuilder) {
 	startTime := time.Now()
+	defer s.rememberBurn(project, devicePath, jobID)
 
 	if len(project.Entries) == 0 {
 		s.finishJob(jobID, models.BurnStateError, nil, "project has no entries")
@@ -19,33 +21,6 @@ func (s *BurnService) runBurn(ctx context.Context, project *models.Project, devi
 
 	s.updateState(jobID, models.BurnStateWriting)
 
-	// Формируем команду xorriso
-	cmd := xorriso.NewCommand()
-	cmd.Device(devicePath)
-	cmd.AbortOn("FAILURE")
-
-	s.buildISOCommand(cmd, project)
-
-	// Опции записи
-	if opts.Speed != "" && opts.Speed != "auto" {
-		cmd.WriteSpeed(opts.Speed)
-	}
-	if opts.BurnMode != "" && opts.BurnMode != "auto" {
-		cmd.WriteType(opts.BurnMode)
-	}
-	if opts.Padding > 0 {
-		cmd.Padding(opts.Padding)
-	}
-
-	cmd.Dummy(opts.DummyMode)
-	if opts.Multisession {
-		cmd.Close(false) // диск остаётся открытым для дозаписи
-	} else {
-		cmd.Close(opts.CloseDisc)
-	}
-	cmd.StreamRecording(opts.StreamRecording)
-
-	cmd.Commit()
 	// Eject НЕ добавляем в основную команду — выполняем отдельно после верификации
 
 	// Выполняем запись с отслеживанием прогресса
@@ -94,14 +69,7 @@ func (s *BurnService) runBurn(ctx context.Context, project *models.Project, devi
 	if opts.Verify {
 		s.updateState(jobID, models.BurnStateVerifying)
 
-		verifyCmd := xorriso.NewCommand()
-		verifyCmd.InDevice(devicePath)
-		verifyCmd.AbortOn("FAILURE")
-		if project.ISOOptions.MD5 {
-			verifyCmd.MD5("on")
-			verifyCmd.CheckMD5Recursive("FAILURE", "/")
-		}
-		verifyCmd.CheckMedia(nil)
+		verifyCmd := buildVerifyCommand(project, devicePath)
 
 		verifyResult, verifyErr := s.executor.RunWithProgress(ctx, func(p xorriso.Progress) {
 			progress := models.BurnProgress{
@@ -138,9 +106,7 @@ func (s *BurnService) runBurn(ctx context.Context, project *models.Project, devi
 
 	// Eject после всех операций
 	if opts.Eject {
-		ejectCmd := xorriso.NewCommand()
-		ejectCmd.Device(devicePath)
-		ejectCmd.Eject("all")
+		ejectCmd := xorriso.NewCommand().Device(devicePath).Eject("all")
 		ejectCtx, ejectCancel := context.WithTimeout(context.Background(), ejectTimeout)
 		defer ejectCancel()
 		if _, err := s.executor.Run(ejectCtx, ejectCmd.Build()...); err != nil {
@@ -169,16 +135,81 @@ func (s *BurnService) runBurn(ctx context.Context, project *models.Project, devi
 	s.finishJob(jobID, models.BurnStateDone, burnResult, "")
 }
 
-func (s *BurnService) runCreateISO(ctx context.Context, project *models.Project, outputPath string, jobID string) {
-	startTime := time.Now()
+// buildBurnCommand формирует команду записи проекта на привод без шагов верификации и извлечения
+func (s *BurnService) buildBurnCommand(project *models.Project, devicePath string, opts models.BurnOptions) *xorriso.CommandBuilder {
+	cmd := xorriso.NewCommand()
+	cmd.Device(devicePath)
+	cmd.AbortOn("FAILURE")
 
-	if len(project.Entries) == 0 {
-		s.finishJob(jobID, models.BurnStateError, nil, "project has no entries")
-		return
+	s.buildISOCommand(cmd, project)
+	buildWriteOptions(cmd, opts)
+
+	cmd.Commit()
+	return cmd
+}
+
+// buildWriteOptions добавляет опции записи на физический носитель
+func buildWriteOptions(cmd *xorriso.CommandBuilder, opts models.BurnOptions) {
+	if opts.Speed != "" && opts.Speed != "auto" {
+		cmd.WriteSpeed(opts.Speed)
+	}
+	if opts.BurnMode != "" && opts.BurnMode != "auto" {
+		cmd.WriteType(opts.BurnMode)
+	}
+	if opts.Padding > 0 {
+		cmd.Padding(opts.Padding)
 	}
 
-	s.updateState(jobID, models.BurnStateCreatingISO)
+	cmd.Dummy(opts.DummyMode)
+	if opts.Multisession {
+		cmd.Close(false) // диск остаётся открытым для дозаписи
+	} else {
+		cmd.Close(opts.CloseDisc)
+	}
+	cmd.StreamRecording(opts.StreamRecording)
+}
 
+// buildVerifyCommand формирует команду проверки записанного диска
+func buildVerifyCommand(project *models.Project, devicePath string) *xorriso.CommandBuilder {
+	cmd := xorriso.NewCommand()
+	cmd.InDevice(devicePath)
+	cmd.AbortOn("FAILURE")
+	if project.ISOOptions.MD5 {
+		cmd.MD5("on")
+		cmd.CheckMD5Recursive("FAILURE", "/")
+	}
+	cmd.CheckMedia(nil)
+	return cmd
+}
+
+// projectBurnCommand формирует команду записи на привод; проект на основе
+// существующего образа записывается из загруженного образа с изменениями
+func (s *BurnService) projectBurnCommand(project *models.Project, devicePath string, opts models.BurnOptions) (*xorriso.CommandBuilder, error) {
+	if err := validateVolumeDescriptor(project.VolumeID, project.ISOOptions); err != nil {
+		return nil, err
+	}
+	if project.BaseImage != nil {
+		return s.modifyCommand(project, devicePath, &opts)
+	}
+	return s.buildBurnCommand(project, devicePath, opts), nil
+}
+
+// projectISOCommand формирует команду создания ISO-файла, в том числе изменённой копии базового образа
+func (s *BurnService) projectISOCommand(project *models.Project, outputPath string) (*xorriso.CommandBuilder, error) {
+	if err := validateVolumeDescriptor(project.VolumeID, project.ISOOptions); err != nil {
+		return nil, err
+	}
+	if project.BaseImage == nil {
+		return s.buildCreateISOCommand(project, outputPath), nil
+	}
+	if sameFile(project.BaseImage.Path, outputPath) {
+		return nil, fmt.Errorf("output file must differ from the base image %s", project.BaseImage.Path)
+	}
+	return s.modifyCommand(project, "stdio:"+outputPath, nil)
+}
+
+// buildCreateISOCommand формирует команду создания ISO-файла
+func (s *BurnService) buildCreateISOCommand(project *models.Project, outputPath string) *xorriso.CommandBuilder {
 	cmd := xorriso.NewCommand()
 	cmd.StdioOutDevice(outputPath)
 	cmd.AbortOn("FAILURE")
@@ -186,6 +217,19 @@ func (s *BurnService) runCreateISO(ctx context.Context, project *models.Project,
 	s.buildISOCommand(cmd, project)
 
 	cmd.Commit()
+	return cmd
+}
+
+func (s *BurnService) runCreateISO(ctx context.Context, project *models.Project, outputPath string, jobID string, cmd *xorriso.CommandBuilder) {
+	startTime := time.Now()
+	defer s.rememberBurn(project, outputPath, jobID)
+
+	if len(project.Entries) == 0 {
+		s.finishJob(jobID, models.BurnStateError, nil, "project has no entries")
+		return
+	}
+
+	s.updateState(jobID, models.BurnStateCreatingISO)
 
 	var lastProgress models.BurnProgress
 	result, err := s.executor.RunWithProgress(ctx, func(p xorriso.Progress) {
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"xorriso-ui/pkg/models"
+	"xorriso-ui/pkg/xorriso"
+)
+
+const (
+	ScriptFormatShell   = "shell"
+	ScriptFormatOptions = "options"
+
+	ScriptTargetBurn = "burn"
+	ScriptTargetISO  = "iso"
+)
+
+// ScriptOptions describes what ExportScript should produce
+type ScriptOptions struct {
+	Format     string `json:"format"`     // "shell" или "options" (-options_from_file)
+	Target     string `json:"target"`     // "burn" или "iso"
+	DevicePath string `json:"devicePath"` // для Target == "burn"
+	OutputPath string `json:"outputPath"` // для Target == "iso"
+	Verify     bool   `json:"verify"`     // только для записи на привод
+	Eject      bool   `json:"eject"`      // только для записи на привод
+}
+
+// ExportScript renders the project as a runnable xorriso script: either a POSIX
+// shell script or a file for "xorriso -options_from_file". The commands are the
+// same ones BurnService runs, including the optional verify and eject steps.
+func (s *BurnService) ExportScript(project *models.Project, opts models.BurnOptions, scriptOpts ScriptOptions) (string, error) {
+	steps, err := s.scriptSteps(project, opts, scriptOpts)
+	if err != nil {
+		return "", err
+	}
+
+	switch scriptOpts.Format {
+	case ScriptFormatShell, "":
+		return renderShellScript(project, steps), nil
+	case ScriptFormatOptions:
+		return renderOptionsScript(project, steps)
+	default:
+		return "", fmt.Errorf("unknown script format: %s", scriptOpts.Format)
+	}
+}
+
+// SaveScript writes the exported script to filePath. Shell scripts are made executable.
+func (s *BurnService) SaveScript(project *models.Project, opts models.BurnOptions, scriptOpts ScriptOptions, filePath string) error {
+	script, err := s.ExportScript(project, opts, scriptOpts)
+	if err != nil {
+		return err
+	}
+
+	perm := os.FileMode(0644)
+	if scriptOpts.Format != ScriptFormatOptions {
+		perm = 0755
+	}
+	if err := os.WriteFile(filePath, []byte(script), perm); err != nil {
+		return fmt.Errorf("failed to write script: %w", err)
+	}
+	// WriteFile не меняет права существующего файла
+	return os.Chmod(filePath, perm)
+}
+
+// scriptSteps возвращает отдельные запуски xorriso в порядке выполнения, как в runBurn/runCreateISO
+func (s *BurnService) scriptSteps(project *models.Project, opts models.BurnOptions, scriptOpts ScriptOptions) ([]*xorriso.CommandBuilder, error) {
+	if project == nil {
+		return nil, fmt.Errorf("project is nil")
+	}
+	if len(project.Entries) == 0 {
+		return nil, fmt.Errorf("project has no entries")
+	}
+
+	switch scriptOpts.Target {
+	case ScriptTargetBurn, "":
+		if scriptOpts.DevicePath == "" {
+			return nil, fmt.Errorf("device path is empty")
+		}
+		if err := validateBurnOptions(opts); err != nil {
+			return nil, err
+		}
+		cmd, err := s.projectBurnCommand(project, scriptOpts.DevicePath, opts)
+		if err != nil {
+			return nil, err
+		}
+		steps := []*xorriso.CommandBuilder{cmd}
+		if scriptOpts.Verify {
+			steps = append(steps, buildVerifyCommand(project, scriptOpts.DevicePath))
+		}
+		if scriptOpts.Eject {
+			steps = append(steps, xorriso.NewCommand().Device(scriptOpts.DevicePath).Eject("all"))
+		}
+		return steps, nil
+	case ScriptTargetISO:
+		if scriptOpts.OutputPath == "" {
+			return nil, fmt.Errorf("output path is empty")
+		}
+		cmd, err := s.projectISOCommand(project, scriptOpts.OutputPath)
+		if err != nil {
+			return nil, err
+		}
+		return []*xorriso.CommandBuilder{cmd}, nil
+	default:
+		return nil, fmt.Errorf("unknown script target: %s", scriptOpts.Target)
+	}
+}
+
+// renderShellScript выводит каждый запуск xorriso отдельной командой, по одной опции на строку
+func renderShellScript(project *models.Project, steps []*xorriso.CommandBuilder) string {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	writeScriptHeader(&b, project)
+	b.WriteString("set -e\n")
+
+	for _, step := range steps {
+		b.WriteString("\nxorriso")
+		for _, command := range step.Commands() {
+			b.WriteString(" \\\n  ")
+			b.WriteString(xorriso.ShellJoin(command))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// renderOptionsScript выводит все шаги в одном сеансе xorriso, по одной команде на строку.
+// В файле опций нет способа записать перевод строки внутри аргумента.
+func renderOptionsScript(project *models.Project, steps []*xorriso.CommandBuilder) (string, error) {
+	var b strings.Builder
+	writeScriptHeader(&b, project)
+	b.WriteString("# Run with: xorriso -options_from_file <this file>\n")
+
+	for _, step := range steps {
+		for _, command := range step.Commands() {
+			for _, arg := range command {
+				if strings.ContainsAny(arg, "\n\r") {
+					return "", fmt.Errorf("argument with a line break cannot be written to an options file: %q", arg)
+				}
+			}
+			b.WriteString(xorriso.OptionJoin(command))
+			b.WriteByte('\n')
+		}
+	}
+	return b.String(), nil
+}
+
+func writeScriptHeader(b *strings.Builder, project *models.Project) {
+	name := strings.NewReplacer("\n", " ", "\r", " ").Replace(project.Name)
+	fmt.Fprintf(b, "# Generated by xorriso-ui from project %q\n", name)
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"xorriso-ui/pkg/models"
+	"xorriso-ui/pkg/xorriso"
+)
+
+func newScriptTestProject() *models.Project {
+	return &models.Project{
+		Name:     "Backup",
+		VolumeID: "My Disc",
+		Entries: []models.FileEntry{
+			{SourcePath: "/home/user/My Documents", DestPath: "/My Documents", IsDir: true},
+			{SourcePath: "/home/user/it's \"quoted\".txt", DestPath: "/it's \"quoted\".txt"},
+			{SourcePath: `/home/user/back\slash $HOME`, DestPath: `/back\slash $HOME`},
+		},
+		ISOOptions: models.ISOOptions{RockRidge: true, Joliet: true, MD5: true, PublisherID: "ACME Corp"},
+	}
+}
+
+// parseShellScript разбирает сгенерированный shell-скрипт обратно в аргументы каждого запуска xorriso
+func parseShellScript(t *testing.T, script string) [][]string {
+	t.Helper()
+	var runs [][]string
+	for _, block := range strings.Split(script, "\n\n") {
+		if !strings.HasPrefix(block, "xorriso") {
+			continue
+		}
+		words, err := xorriso.SplitShellWords(block)
+		if err != nil {
+			t.Fatalf("SplitShellWords: %v", err)
+		}
+		runs = append(runs, words[1:])
+	}
+	return runs
+}
+
+// parseOptionsScript разбирает файл опций обратно в плоский список аргументов
+func parseOptionsScript(t *testing.T, script string) []string {
+	t.Helper()
+	var args []string
+	for _, line := range strings.Split(script, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words, err := xorriso.SplitOptionsLine(line)
+		if err != nil {
+			t.Fatalf("SplitOptionsLine(%q): %v", line, err)
+		}
+		args = append(args, words...)
+	}
+	return args
+}
+
+func TestExportScript_ShellRoundTrip(t *testing.T) {
+	svc := NewBurnService(&mockRunner{})
+	project := newScriptTestProject()
+	opts := models.BurnOptions{Speed: "4", BurnMode: "DAO", CloseDisc: true}
+
+	script, err := svc.ExportScript(project, opts, ScriptOptions{
+		Format: ScriptFormatShell, DevicePath: "/dev/sr0", Verify: true, Eject: true,
+	})
+	if err != nil {
+		t.Fatalf("ExportScript: %v", err)
+	}
+	if !strings.HasPrefix(script, "#!/bin/sh\n") || !strings.Contains(script, "set -e\n") {
+		t.Errorf("missing shell preamble:\n%s", script)
+	}
+
+	want := [][]string{
+		svc.buildBurnCommand(project, "/dev/sr0", opts).Build(),
+		buildVerifyCommand(project, "/dev/sr0").Build(),
+		{"-dev", "/dev/sr0", "-eject", "all"},
+	}
+	got := parseShellScript(t, script)
+	if len(got) != len(want) {
+		t.Fatalf("got %d xorriso runs, want %d:\n%s", len(got), len(want), script)
+	}
+	for i := range want {
+		if !slices.Equal(got[i], want[i]) {
+			t.Errorf("run %d:\ngot  %q\nwant %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestExportScript_OptionsRoundTrip(t *testing.T) {
+	svc := NewBurnService(&mockRunner{})
+	project := newScriptTestProject()
+	opts := models.BurnOptions{Multisession: true}
+
+	script, err := svc.ExportScript(project, opts, ScriptOptions{
+		Format: ScriptFormatOptions, DevicePath: "/dev/sr0", Verify: true,
+	})
+	if err != nil {
+		t.Fatalf("ExportScript: %v", err)
+	}
+
+	want := append(svc.buildBurnCommand(project, "/dev/sr0", opts).Build(),
+		buildVerifyCommand(project, "/dev/sr0").Build()...)
+	if got := parseOptionsScript(t, script); !slices.Equal(got, want) {
+		t.Errorf("options round trip:\ngot  %q\nwant %q", got, want)
+	}
+	if strings.Contains(script, "-eject") {
+		t.Error("eject step should be omitted when not requested")
+	}
+}
+
+func TestExportScript_ISO(t *testing.T) {
+	svc := NewBurnService(&mockRunner{})
+	project := newScriptTestProject()
+
+	script, err := svc.ExportScript(project, models.BurnOptions{}, ScriptOptions{
+		Format: ScriptFormatOptions, Target: ScriptTargetISO, OutputPath: "/tmp/my image.iso", Eject: true,
+	})
+	if err != nil {
+		t.Fatalf("ExportScript: %v", err)
+	}
+
+	want := svc.buildCreateISOCommand(project, "/tmp/my image.iso").Build()
+	if got := parseOptionsScript(t, script); !slices.Equal(got, want) {
+		t.Errorf("ISO script:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestExportScript_Errors(t *testing.T) {
+	svc := NewBurnService(&mockRunner{})
+	project := newScriptTestProject()
+
+	tests := []struct {
+		name       string
+		scriptOpts ScriptOptions
+	}{
+		{"no device", ScriptOptions{Format: ScriptFormatShell}},
+		{"no output", ScriptOptions{Target: ScriptTargetISO}},
+		{"bad format", ScriptOptions{Format: "bat", DevicePath: "/dev/sr0"}},
+		{"bad target", ScriptOptions{Target: "usb", DevicePath: "/dev/sr0"}},
+	}
+	for _, tt := range tests {
+		if _, err := svc.ExportScript(project, models.BurnOptions{}, tt.scriptOpts); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+
+	project.Entries = append(project.Entries, models.FileEntry{SourcePath: "/src/a\nb", DestPath: "/a\nb"})
+	if _, err := svc.ExportScript(project, models.BurnOptions{}, ScriptOptions{
+		Format: ScriptFormatOptions, DevicePath: "/dev/sr0",
+	}); err == nil {
+		t.Error("expected error for line break in options file")
+	}
+}
+
+func TestSaveScript_Executable(t *testing.T) {
+	svc := NewBurnService(&mockRunner{})
+	path := filepath.Join(t.TempDir(), "burn.sh")
+
+	err := svc.SaveScript(newScriptTestProject(), models.BurnOptions{}, ScriptOptions{DevicePath: "/dev/sr0"}, path)
+	if err != nil {
+		t.Fatalf("SaveScript: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0100 == 0 {
+		t.Errorf("shell script should be executable, mode = %v", info.Mode())
+	}
+}
@@ -1,9 +1,12 @@
 package services
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -12,7 +15,6 @@ import (
 	"xorriso-ui/pkg/models"
 	"xorriso-ui/pkg/xorriso"
 
-	"github.com/google/uuid"
 	"github.com/wailsapp/wails/v3/pkg/application"
 )
 
@@ -32,12 +34,17 @@ type BurnService struct {
 	currentJob *models.BurnJob
 	cancelFn   context.CancelFunc
 	emitEvent  func(name string, data ...any)
+	// Пути /sys и /proc для проверки USB-накопителя перед записью, переопределяются в тестах
+	sysBlockPath string
+	mountsPath   string
 }
 
 func NewBurnService(executor xorriso.Runner) *BurnService {
 	return &BurnService{
-		executor:  executor,
-		emitEvent: defaultEmitEvent,
+		executor:     executor,
+		emitEvent:    defaultEmitEvent,
+		sysBlockPath: "/sys/block",
+		mountsPath:   "/proc/self/mounts",
 	}
 }
 
@@ -74,28 +81,24 @@ func (s *BurnService) ServiceShutdown() error {
 
 // StartBurn begins the disc burning process
 func (s *BurnService) StartBurn(project *models.Project, devicePath string, opts models.BurnOptions) (string, error) {
-	s.mu.Lock()
-	if s.currentJob != nil && (s.currentJob.State == models.BurnStateWriting || s.currentJob.State == models.BurnStateVerifying) {
-		s.mu.Unlock()
-		return "", fmt.Errorf("burn already in progress")
+	// Проверки до регистрации задачи: отклонённая запись не должна оставлять задачу в Pending
+	if err := validateBurnOptions(opts); err != nil {
+		return "", err
 	}
-
-	jobID := uuid.New().String()
-	s.currentJob = &models.BurnJob{
-		ID:        jobID,
-		State:     models.BurnStatePending,
-		StartedAt: time.Now(),
+	if err := validateLargeFiles(project); err != nil {
+		return "", err
 	}
-	s.mu.Unlock()
-
-	if err := validateBurnOptions(opts); err != nil {
+	cmd, err := s.projectBurnCommand(project, devicePath, opts)
+	if err != nil {
 		return "", err
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	s.cancelFn = cancel
+	jobID, ctx, err := s.startJob()
+	if err != nil {
+		return "", fmt.Errorf("burn already in progress")
+	}
 
-	go s.runBurn(ctx, project, devicePath, opts, jobID)
+	go s.runBurn(ctx, project, devicePath, opts, jobID, cmd)
 
 	return jobID, nil
 }
@@ -129,25 +132,20 @@ func (s *BurnService) GetJobStatus(jobID string) (*models.BurnJob, error) {
 
 // CreateISO создаёт ISO-файл без записи на привод
 func (s *BurnService) CreateISO(project *models.Project, outputPath string) (string, error) {
-	s.mu.Lock()
-	if s.currentJob != nil && (s.currentJob.State == models.BurnStateWriting ||
-		s.currentJob.State == models.BurnStateCreatingISO) {
-		s.mu.Unlock()
-		return "", fmt.Errorf("operation already in progress")
+	if err := validateLargeFiles(project); err != nil {
+		return "", err
 	}
-
-	jobID := uuid.New().String()
-	s.currentJob = &models.BurnJob{
-		ID:        jobID,
-		State:     models.BurnStatePending,
-		StartedAt: time.Now(),
+	cmd, err := s.projectISOCommand(project, outputPath)
+	if err != nil {
+		return "", err
 	}
-	s.mu.Unlock()
 
-	ctx, cancel := context.WithCancel(context.Background())
-	s.cancelFn = cancel
+	jobID, ctx, err := s.startJob()
+	if err != nil {
+		return "", err
+	}
 
-	go s.runCreateISO(ctx, project, outputPath, jobID)
+	go s.runCreateISO(ctx, project, outputPath, jobID, cmd)
 
 	return jobID, nil
 }
@@ -201,20 +199,54 @@ func validateBurnOptions(opts models.BurnOptions) error {
 
 // buildISOCommand формирует общую часть команды xorriso для ISO-опций и файлов проекта
 func (s *BurnService) buildISOCommand(cmd *xorriso.CommandBuilder, project *models.Project) {
-	if project.VolumeID != "" {
-		cmd.VolumeID(project.VolumeID)
+	buildISOOptions(cmd, project)
+
+	// Добавить файлы
+	for _, entry := range project.Entries {
+		switch {
+		case entry.SourcePath == "":
+			// Виртуальная папка, созданная в проекте без источника на диске
+			cmd.Mkdir(entry.DestPath)
+		case entry.IsDir && entry.Listed:
+			// Содержимое каталога перечислено отдельными записями —
+			// переносим только сам каталог, чтобы учесть удаления и переименования
+			cmd.MapSingle(entry.SourcePath, entry.DestPath)
+		default:
+			cmd.Map(entry.SourcePath, entry.DestPath)
+		}
 	}
 
-	cmd.ApplicationID(appApplicationID)
-	cmd.SystemID(appSystemID)
-	if project.ISOOptions.PublisherID != "" {
-		cmd.Publisher(project.ISOOptions.PublisherID)
+	buildLayout(cmd, project)
+}
+
+// buildLayout добавляет переопределения атрибутов и веса сортировки после того, как созданы все узлы
+func buildLayout(cmd *xorriso.CommandBuilder, project *models.Project) {
+	buildMetadataOverrides(cmd, project.Entries)
+
+	// Расположение файлов на диске
+	for _, w := range layoutWeights(project) {
+		cmd.SortWeight(w.weight, w.path)
 	}
+}
+
+// defaultISOLevel — уровень ISO 9660 xorriso по умолчанию; его получают проекты
+// без уровня, например импортированные из сценариев и K3b без -iso_level
+const defaultISOLevel = 3
+
+// isoLevel возвращает уровень ISO 9660, с которым будет записан образ
+func isoLevel(opts models.ISOOptions) int {
+	return cmp.Or(opts.ISOLevel, defaultISOLevel)
+}
 
-	// ISO level
-	if project.ISOOptions.ISOLevel > 0 {
-		cmd.ISOLevel(project.ISOOptions.ISOLevel)
+// buildISOOptions добавляет параметры файловой системы образа
+func buildISOOptions(cmd *xorriso.CommandBuilder, project *models.Project) {
+	if project.VolumeID != "" {
+		cmd.VolumeID(project.VolumeID)
 	}
+	buildVolumeDescriptor(cmd, project.ISOOptions)
+
+	// Уровень задаётся всегда, чтобы проверки проекта и команда исходили из одного значения
+	cmd.ISOLevel(isoLevel(project.ISOOptions))
 
 	cmd.RockRidge(project.ISOOptions.RockRidge)
 	if project.ISOOptions.Joliet {
@@ -240,14 +272,82 @@ func (s *BurnService) buildISOCommand(cmd *xorriso.CommandBuilder, project *mode
 		cmd.ForBackup()
 	}
 
-	// Добавить файлы
-	for _, entry := range project.Entries {
-		cmd.Map(entry.SourcePath, entry.DestPath)
+	if project.ISOOptions.SplitSize > 0 {
+		cmd.SplitSize(project.ISOOptions.SplitSize)
+	}
+
+	if project.ISOOptions.Hardlinks {
+		cmd.Hardlinks(true)
+	}
+
+	// Ссылки уже учтены при добавлении файлов по политике проекта
+	cmd.Follow(followOccasions(project.ISOOptions.LinkPolicy))
+}
+
+// buildMetadataOverrides применяет переопределения атрибутов после того, как все
+// узлы созданы. Родители обрабатываются раньше детей, чтобы настройки потомков имели приоритет.
+func buildMetadataOverrides(cmd *xorriso.CommandBuilder, entries []models.FileEntry) {
+	var withMeta []models.FileEntry
+	for _, e := range entries {
+		if e.Meta != (models.EntryMetadata{}) {
+			withMeta = append(withMeta, e)
+		}
+	}
+	slices.SortStableFunc(withMeta, func(a, b models.FileEntry) int {
+		return cmp.Compare(strings.Count(a.DestPath, "/"), strings.Count(b.DestPath, "/"))
+	})
+
+	for _, e := range withMeta {
+		m, p := e.Meta, e.DestPath
+		if m.Mode != "" {
+			cmd.ChmodR(m.Mode, p)
+		}
+		if m.Owner != "" {
+			cmd.ChownR(m.Owner, p)
+		}
+		if m.Group != "" {
+			cmd.ChgrpR(m.Group, p)
+		}
+		// Типы a и m заодно ставят ctime в текущее время; a-c и m-c его не трогают
+		atime, mtime := "a-c", "m-c"
+		if m.CTime != 0 {
+			atime, mtime = "a", "m"
+		}
+		for _, t := range []struct {
+			kind string
+			ms   int64
+		}{{atime, m.ATime}, {mtime, m.MTime}, {"c", m.CTime}} {
+			if t.ms != 0 {
+				cmd.AlterDateR(t.kind, "="+strconv.FormatInt(t.ms/1000, 10), p)
+			}
+		}
+		if state := hideState(m); state != "" {
+			cmd.Hide(state, p)
+		}
 	}
 }
 
+// hideState формирует аргумент -hide: "on" для всех деревьев или список через ':'
+func hideState(m models.EntryMetadata) string {
+	if m.HideISO && m.HideJoliet && m.HideHFSPlus {
+		return "on"
+	}
+	var trees []string
+	if m.HideISO {
+		trees = append(trees, "iso_rr")
+	}
+	if m.HideJoliet {
+		trees = append(trees, "joliet")
+	}
+	if m.HideHFSPlus {
+		trees = append(trees, "hfsplus")
+	}
+	return strings.Join(trees, ":")
+}
+
 // GetBurnCommand формирует полную строку команды xorriso для записи диска.
-// Результат можно скопировать в буфер обмена и выполнить в терминале.
+// Аргументы экранируются для POSIX shell — результат можно скопировать
+// в буфер обмена и выполнить в терминале.
 func (s *BurnService) GetBurnCommand(project *models.Project, devicePath string, opts models.BurnOptions) (string, error) {
 	if project == nil {
 		return "", fmt.Errorf("project is nil")
@@ -263,6 +363,17 @@ func (s *BurnService) GetBurnCommand(project *models.Project, devicePath string,
 		return "", err
 	}
 
+	if project.BaseImage != nil {
+		cmd, err := s.modifyCommand(project, devicePath, &opts)
+		if err != nil {
+			return "", err
+		}
+		if opts.Eject {
+			cmd.Eject("all")
+		}
+		return "xorriso " + xorriso.ShellJoin(cmd.Build()), nil
+	}
+
 	cmd := xorriso.NewCommand()
 	cmd.Device(devicePath)
 
@@ -270,23 +381,7 @@ func (s *BurnService) GetBurnCommand(project *models.Project, devicePath string,
 	s.buildISOCommand(cmd, project)
 
 	// Опции записи
-	if opts.Speed != "" && opts.Speed != "auto" {
-		cmd.WriteSpeed(opts.Speed)
-	}
-	if opts.BurnMode != "" && opts.BurnMode != "auto" {
-		cmd.WriteType(opts.BurnMode)
-	}
-	if opts.Padding > 0 {
-		cmd.Padding(opts.Padding)
-	}
-
-	cmd.Dummy(opts.DummyMode)
-	if opts.Multisession {
-		cmd.Close(false)
-	} else {
-		cmd.Close(opts.CloseDisc)
-	}
-	cmd.StreamRecording(opts.StreamRecording)
+	buildWriteOptions(cmd, opts)
 
 	cmd.Commit()
 
@@ -294,6 +389,5 @@ func (s *BurnService) GetBurnCommand(project *models.Project, devicePath string,
 		cmd.Eject("all")
 	}
 
-	args := cmd.Build()
-	return "xorriso " + strings.Join(args, " "), nil
+	return "xorriso " + xorriso.ShellJoin(cmd.Build()), nil
 }
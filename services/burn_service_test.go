@@ -63,6 +63,38 @@ func TestStartBurn_AlreadyInProgress(t *testing.T) {
 	}
 }
 
+func TestStartBurnAndCreateISO_OtherJobActive(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "a.txt")
+	os.WriteFile(src, []byte("a"), 0644)
+	project := &models.Project{
+		VolumeID: "TEST",
+		Entries:  []models.FileEntry{{SourcePath: src, DestPath: "/a.txt", Size: 1}},
+	}
+
+	// Извлечение или запись на USB только зарегистрированы либо уже идут
+	for _, state := range []models.BurnState{models.BurnStatePending, models.BurnStateExtracting,
+		models.BurnStateCreatingISO, models.BurnStateVerifying} {
+		svc := NewBurnService(&mockRunner{})
+		svc.emitEvent = noopEmit
+		jobID, ctx, err := svc.startJob()
+		if err != nil {
+			t.Fatal(err)
+		}
+		svc.currentJob.State = state
+
+		if _, err := svc.StartBurn(project, "/dev/sr0", models.BurnOptions{}); err == nil {
+			t.Errorf("%s: StartBurn must be refused", state)
+		}
+		if _, err := svc.CreateISO(project, filepath.Join(t.TempDir(), "out.iso")); err == nil {
+			t.Errorf("%s: CreateISO must be refused", state)
+		}
+		// Идущую задачу по-прежнему можно отменить
+		if err := svc.CancelBurn(jobID); err != nil || ctx.Err() == nil {
+			t.Errorf("%s: running job cannot be cancelled: %v", state, err)
+		}
+	}
+}
+
 func TestCancelBurn_NoJob(t *testing.T) {
 	svc := NewBurnService(&mockRunner{})
 	svc.emitEvent = noopEmit
@@ -180,6 +212,58 @@ func TestBuildISOCommand(t *testing.T) {
 	}
 }
 
+func TestBuildISOCommand_VirtualAndExplicitDirs(t *testing.T) {
+	svc := NewBurnService(&mockRunner{})
+	svc.emitEvent = noopEmit
+
+	project := &models.Project{
+		Entries: []models.FileEntry{
+			{SourcePath: "/home/user/docs", DestPath: "/docs", IsDir: true, Listed: true},
+			{SourcePath: "/home/user/docs/a.txt", DestPath: "/docs/renamed.txt"},
+			{DestPath: "/empty", IsDir: true},
+		},
+	}
+
+	cmd := xorriso.NewCommand()
+	svc.buildISOCommand(cmd, project)
+	args := cmd.Build()
+
+	if !containsSequence(args, "-map_single", "/home/user/docs", "/docs") {
+		t.Errorf("expected -map_single for directory with explicit children, got: %s", joinArgs(args))
+	}
+	if !containsSequence(args, "-map", "/home/user/docs/a.txt", "/docs/renamed.txt") {
+		t.Errorf("expected -map for child entry, got: %s", joinArgs(args))
+	}
+	if !containsSequence(args, "-mkdir", "/empty", "--") {
+		t.Errorf("expected -mkdir for virtual folder, got: %s", joinArgs(args))
+	}
+}
+
+func TestBuildISOCommand_EmptiedFolder(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "docs")
+	os.MkdirAll(src, 0755)
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("aaaa"), 0644)
+
+	projects := NewProjectService()
+	project := projects.NewProject("Test", "VOL")
+	projects.AddFiles(project, []string{src}, "/")
+	if _, err := projects.MoveEntries(project, []string{"/docs/a.txt"}, "/other"); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := xorriso.NewCommand()
+	NewBurnService(&mockRunner{}).buildISOCommand(cmd, project)
+	args := cmd.Build()
+
+	// Папка без оставшихся записей не переносится целиком — файл не попадает на диск дважды
+	if !containsSequence(args, "-map_single", src, "/docs") || containsSequence(args, "-map", src, "/docs") {
+		t.Errorf("emptied folder must be mapped alone: %s", joinArgs(args))
+	}
+	if total, _ := projects.CalculateSize(project); total != 4 {
+		t.Errorf("size = %d, want 4", total)
+	}
+}
+
 func TestBuildISOCommand_Minimal(t *testing.T) {
 	svc := NewBurnService(&mockRunner{})
 	svc.emitEvent = noopEmit
@@ -311,8 +395,8 @@ func TestGetBurnCommand_Full(t *testing.T) {
 		"-iso_level 3",
 		"-rockridge on",
 		"-joliet on",
-		"-publisher Test Publisher",
-		"-application_id XORRISO-UI (C) Evgeniy Medvedev",
+		"-publisher 'Test Publisher'",
+		"-application_id 'XORRISO-UI (C) Evgeniy Medvedev'",
 		"-system_id LINUX",
 		"-speed 4x",
 		"-write_type SAO",
@@ -464,11 +548,53 @@ func containsSubstring(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
 
-func containsSequence(args []string, a, b string) bool {
-	for i := 0; i < len(args)-1; i++ {
-		if args[i] == a && args[i+1] == b {
+func containsSequence(args []string, seq ...string) bool {
+	for i := 0; i+len(seq) <= len(args); i++ {
+		if slices.Equal(args[i:i+len(seq)], seq) {
 			return true
 		}
 	}
 	return false
 }
+
+func TestBuildISOCommand_MetadataOverrides(t *testing.T) {
+	svc := NewBurnService(&mockRunner{})
+	project := &models.Project{
+		Entries: []models.FileEntry{
+			{SourcePath: "/src/docs/a.txt", DestPath: "/docs/a.txt", Meta: models.EntryMetadata{Mode: "0600"}},
+			{SourcePath: "/src/docs", DestPath: "/docs", IsDir: true, Listed: true,
+				Meta: models.EntryMetadata{Mode: "go-w", Owner: "0", Group: "0", MTime: 1700000000500}},
+			{SourcePath: "/src/autorun.inf", DestPath: "/autorun.inf",
+				Meta: models.EntryMetadata{HideISO: true, HideJoliet: true, HideHFSPlus: true}},
+			{SourcePath: "/src/mac", DestPath: "/mac", Meta: models.EntryMetadata{HideJoliet: true}},
+			{SourcePath: "/src/log", DestPath: "/log", Meta: models.EntryMetadata{ATime: 1600000000000, CTime: 1650000000000}},
+		},
+	}
+
+	cmd := xorriso.NewCommand()
+	svc.buildISOCommand(cmd, project)
+	args := cmd.Build()
+
+	for _, seq := range [][]string{
+		{"-chmod_r", "go-w", "/docs", "--"},
+		{"-chown_r", "0", "/docs", "--"},
+		{"-chgrp_r", "0", "/docs", "--"},
+		{"-alter_date_r", "m-c", "=1700000000", "/docs", "--"},
+		{"-alter_date_r", "a", "=1600000000", "/log", "--"},
+		{"-alter_date_r", "c", "=1650000000", "/log", "--"},
+		{"-chmod_r", "0600", "/docs/a.txt", "--"},
+		{"-hide", "on", "/autorun.inf", "--"},
+		{"-hide", "joliet", "/mac", "--"},
+	} {
+		if !containsSequence(args, seq...) {
+			t.Errorf("expected %v in %v", seq, args)
+		}
+	}
+
+	// Переопределения идут после всех -map, а каталог — раньше вложенного файла
+	lastMap := slices.Index(args, "-chmod_r") > slices.Index(args, "/mac")
+	dirFirst := slices.Index(args, "go-w") < slices.Index(args, "0600")
+	if !lastMap || !dirFirst {
+		t.Errorf("unexpected override order: %v", args)
+	}
+}
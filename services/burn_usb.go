@@ -0,0 +1,312 @@
+package services
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+
+	"xorriso-ui/pkg/models"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// Размер одной прямой записи; кратен логическому сектору любого накопителя
+	usbWriteChunk = 4 << 20
+	// Начало устройства, которое затирается перед записью через xorriso, как -blank fast
+	usbInvalidateSize = 64 << 10
+	// Выравнивание буфера в памяти для O_DIRECT
+	directIOAlign = 4096
+)
+
+// USBTarget is a USB device selected for writing. Identity is the USBDevice.Identity
+// the user confirmed; writing is refused if the device at DevicePath no longer matches it.
+type USBTarget struct {
+	DevicePath string `json:"devicePath"`
+	Identity   string `json:"identity"`
+	Verify     bool   `json:"verify"` // прочитать записанное обратно и сравнить
+}
+
+// WriteImageToUSB copies an ISO image file onto a USB device with direct block
+// writes (O_DIRECT), bypassing the page cache. With Verify set the written data is
+// read back and its MD5 compared with the image. Everything on the device is overwritten.
+func (s *BurnService) WriteImageToUSB(imagePath string, target USBTarget) (string, error) {
+	info, err := os.Stat(imagePath)
+	if err != nil {
+		return "", fmt.Errorf("cannot open image: %w", err)
+	}
+	if !info.Mode().IsRegular() || info.Size() == 0 {
+		return "", fmt.Errorf("not an image file: %s", imagePath)
+	}
+	dev, err := s.checkUSBTarget(target)
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > dev.Size {
+		return "", fmt.Errorf("image of %d bytes does not fit on %s (%d bytes)", info.Size(), dev.Path, dev.Size)
+	}
+	blockSize := s.logicalBlockSize(dev.Path)
+
+	jobID, ctx, err := s.startJob()
+	if err != nil {
+		return "", err
+	}
+	go s.runUSBImageWrite(ctx, imagePath, info.Size(), target, blockSize, jobID)
+	return jobID, nil
+}
+
+// WriteProjectToUSB builds the project image with xorriso straight onto a USB device
+// through a stdio: pseudo-drive. A project based on an image with kept boot records
+// (an isohybrid image) stays bootable from the stick. Everything on the device is overwritten.
+func (s *BurnService) WriteProjectToUSB(project *models.Project, target USBTarget) (string, error) {
+	if len(project.Entries) == 0 {
+		return "", fmt.Errorf("project has no entries")
+	}
+	if err := validateLargeFiles(project); err != nil {
+		return "", err
+	}
+	dev, err := s.checkUSBTarget(target)
+	if err != nil {
+		return "", err
+	}
+	if size := estimateImageSize(project.Entries, project.ISOOptions); size > dev.Size {
+		return "", fmt.Errorf("image of about %d bytes does not fit on %s (%d bytes)", size, dev.Path, dev.Size)
+	}
+	cmd, err := s.projectISOCommand(project, dev.Path)
+	if err != nil {
+		return "", err
+	}
+
+	jobID, ctx, err := s.startJob()
+	if err != nil {
+		return "", err
+	}
+	go func() {
+		// Без ISO в начале xorriso пишет образ с нулевого адреса, а не дописывает сессию
+		if err := invalidateDeviceStart(dev.Path); err != nil {
+			s.finishJob(jobID, models.BurnStateError, nil, err.Error())
+			return
+		}
+		s.runBurn(ctx, project, "stdio:"+dev.Path, models.BurnOptions{Verify: target.Verify}, jobID, cmd)
+	}()
+	return jobID, nil
+}
+
+// checkUSBTarget заново читает устройство из sysfs и проверяет, что это выбранный
+// пользователем съёмный USB-накопитель без смонтированных разделов
+func (s *BurnService) checkUSBTarget(target USBTarget) (models.USBDevice, error) {
+	name, ok := strings.CutPrefix(target.DevicePath, "/dev/")
+	if !ok || name == "" || strings.Contains(name, "/") {
+		return models.USBDevice{}, fmt.Errorf("not a block device path: %s", target.DevicePath)
+	}
+	dev, ok := readUSBDevice(s.sysBlockPath, name, readMounts(s.mountsPath))
+	if !ok {
+		return models.USBDevice{}, fmt.Errorf("%s is not a removable USB device", target.DevicePath)
+	}
+	if target.Identity == "" {
+		return models.USBDevice{}, fmt.Errorf("device identity must be confirmed before writing")
+	}
+	if dev.Identity != target.Identity {
+		return models.USBDevice{}, fmt.Errorf("device %s is now %q, not the confirmed %q", dev.Path, dev.Identity, target.Identity)
+	}
+	if dev.Busy {
+		return models.USBDevice{}, fmt.Errorf("device %s is in use: %s", dev.Path, usbBusyReason(dev))
+	}
+	return dev, nil
+}
+
+// logicalBlockSize возвращает размер логического сектора устройства, по умолчанию 512
+func (s *BurnService) logicalBlockSize(devicePath string) int {
+	text := readSysFile(filepath.Join(s.sysBlockPath, filepath.Base(devicePath), "queue", "logical_block_size"))
+	if size, err := strconv.Atoi(text); err == nil && size > 0 && usbWriteChunk%size == 0 {
+		return size
+	}
+	return sysfsSectorSize
+}
+
+func (s *BurnService) runUSBImageWrite(ctx context.Context, imagePath string, size int64, target USBTarget, blockSize int, jobID string) {
+	startTime := time.Now()
+	s.updateState(jobID, models.BurnStateWriting)
+
+	sum, err := writeBlockDevice(ctx, imagePath, target.DevicePath, blockSize, true, func(done int64) {
+		s.setJobProgress(jobID, "writing", done, size, transferSpeed(done, startTime))
+	})
+	if ctx.Err() != nil {
+		// Отменено через CancelBurn — состояние уже выставлено
+		return
+	}
+	if err != nil {
+		s.finishJob(jobID, models.BurnStateError, nil, err.Error())
+		return
+	}
+
+	duration := time.Since(startTime)
+	result := &models.BurnResult{
+		Success:      true,
+		BytesWritten: size,
+		Duration:     duration.String(),
+		AverageSpeed: transferSpeed(size, startTime),
+	}
+
+	if target.Verify {
+		s.updateState(jobID, models.BurnStateVerifying)
+		verifyStart := time.Now()
+		readSum, err := readBlockDeviceMD5(ctx, target.DevicePath, size, blockSize, true, func(done int64) {
+			s.setJobProgress(jobID, "verifying", done, size, transferSpeed(done, verifyStart))
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			s.finishJob(jobID, models.BurnStateError, nil, fmt.Sprintf("verification failed: %s", err))
+			return
+		}
+		result.MD5Match = readSum == sum
+		if !result.MD5Match {
+			result.Success = false
+			result.VerifyErrors = 1
+			s.finishJob(jobID, models.BurnStateError, result, fmt.Sprintf("data read back from %s does not match the image", target.DevicePath))
+			return
+		}
+	}
+
+	s.finishJob(jobID, models.BurnStateDone, result, "")
+}
+
+// transferSpeed — средняя скорость с начала операции
+func transferSpeed(bytes int64, since time.Time) string {
+	seconds := time.Since(since).Seconds()
+	if seconds <= 0 || bytes <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.2f MB/s", float64(bytes)/1024.0/1024.0/seconds)
+}
+
+// openBlockDevice открывает устройство монопольно: O_EXCL на блочном устройстве
+// не даёт открыть его, пока оно смонтировано, и смонтировать, пока открыто
+func openBlockDevice(devicePath string, flag int, direct bool) (*os.File, error) {
+	flag |= unix.O_EXCL
+	if direct {
+		flag |= unix.O_DIRECT
+	}
+	f, err := os.OpenFile(devicePath, flag, 0)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open %s: %w", devicePath, err)
+	}
+	return f, nil
+}
+
+// alignedBuffer выделяет буфер, начало которого выровнено для O_DIRECT
+func alignedBuffer(size int) []byte {
+	buf := make([]byte, size+directIOAlign)
+	offset := 0
+	if rem := int(uintptr(unsafe.Pointer(&buf[0])) % directIOAlign); rem != 0 {
+		offset = directIOAlign - rem
+	}
+	return buf[offset : offset+size]
+}
+
+// writeBlockDevice копирует файл на устройство блоками и возвращает MD5 записанных
+// данных. Последний блок дополняется нулями до размера сектора.
+func writeBlockDevice(ctx context.Context, src, devicePath string, blockSize int, direct bool, progress func(done int64)) (string, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return "", fmt.Errorf("cannot open image: %w", err)
+	}
+	defer func() { _ = in.Close() }()
+	out, err := openBlockDevice(devicePath, os.O_WRONLY, direct)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = out.Close() }()
+
+	h := md5.New()
+	buf := alignedBuffer(usbWriteChunk)
+	var done int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		n, readErr := io.ReadFull(in, buf)
+		if n > 0 {
+			h.Write(buf[:n])
+			size := n
+			if rem := n % blockSize; rem != 0 {
+				size += blockSize - rem
+				clear(buf[n:size])
+			}
+			if _, err := out.Write(buf[:size]); err != nil {
+				return "", fmt.Errorf("write to %s failed: %w", devicePath, err)
+			}
+			done += int64(n)
+			progress(done)
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return "", fmt.Errorf("failed to read image: %w", readErr)
+		}
+	}
+	if err := out.Sync(); err != nil {
+		return "", fmt.Errorf("failed to flush %s: %w", devicePath, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), out.Close()
+}
+
+// readBlockDeviceMD5 читает первые size байт устройства в обход кэша и возвращает их MD5
+func readBlockDeviceMD5(ctx context.Context, devicePath string, size int64, blockSize int, direct bool, progress func(done int64)) (string, error) {
+	in, err := openBlockDevice(devicePath, os.O_RDONLY, direct)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = in.Close() }()
+
+	h := md5.New()
+	buf := alignedBuffer(usbWriteChunk)
+	var done int64
+	for done < size {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		// Читаем целыми секторами, в сумму берём только данные образа
+		want := min(int64(len(buf)), size-done)
+		if rem := want % int64(blockSize); rem != 0 {
+			want += int64(blockSize) - rem
+		}
+		n, err := io.ReadFull(in, buf[:want])
+		data := min(int64(n), size-done)
+		h.Write(buf[:data])
+		done += data
+		progress(done)
+		if err != nil && done < size {
+			return "", fmt.Errorf("read from %s failed: %w", devicePath, err)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// invalidateDeviceStart затирает начало устройства, чтобы на нём не осталось образа,
+// к которому xorriso мог бы дописать сессию
+func invalidateDeviceStart(devicePath string) error {
+	f, err := openBlockDevice(devicePath, os.O_WRONLY, false)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.Write(make([]byte, usbInvalidateSize)); err != nil {
+		return fmt.Errorf("write to %s failed: %w", devicePath, err)
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("failed to flush %s: %w", devicePath, err)
+	}
+	return f.Close()
+}
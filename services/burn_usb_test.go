@@ -0,0 +1,169 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"xorriso-ui/pkg/models"
+)
+
+// newUSBBurnService возвращает сервис с поддельными sysfs и списком монтирования
+func newUSBBurnService(t *testing.T, sectors int, mounts string) *BurnService {
+	t.Helper()
+	svc := NewBurnService(&mockRunner{})
+	svc.emitEvent = noopEmit
+	svc.sysBlockPath, _ = fakeUSBSysfs(t, sectors)
+	svc.mountsPath = writeMounts(t, mounts)
+	return svc
+}
+
+func TestCheckUSBTarget(t *testing.T) {
+	const identity = "SanDisk Cruzer Blade 4C530001 30752000000"
+	svc := newUSBBurnService(t, 60062500, "")
+
+	dev, err := svc.checkUSBTarget(USBTarget{DevicePath: "/dev/sdb", Identity: identity})
+	if err != nil || dev.Path != "/dev/sdb" {
+		t.Fatalf("dev = %+v, err = %v", dev, err)
+	}
+
+	for name, target := range map[string]USBTarget{
+		"not confirmed": {DevicePath: "/dev/sdb"},
+		"other device":  {DevicePath: "/dev/sdb", Identity: "Kingston DataTraveler 1 16000000000"},
+		"system disk":   {DevicePath: "/dev/sda", Identity: identity},
+		"partition":     {DevicePath: "/dev/sdb1", Identity: identity},
+		"not in /dev":   {DevicePath: "/tmp/sdb", Identity: identity},
+		"path escape":   {DevicePath: "/dev/../sdb", Identity: identity},
+	} {
+		if _, err := svc.checkUSBTarget(target); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	busy := newUSBBurnService(t, 60062500, "/dev/sdb1 /media/user/STICK vfat rw 0 0\n")
+	_, err = busy.checkUSBTarget(USBTarget{DevicePath: "/dev/sdb", Identity: identity})
+	if err == nil || !strings.Contains(err.Error(), "/dev/sdb1 mounted on /media/user/STICK") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestWriteImageToUSB_Checks(t *testing.T) {
+	// 8 секторов по 512 байт
+	svc := newUSBBurnService(t, 8, "")
+	target := USBTarget{DevicePath: "/dev/sdb", Identity: "SanDisk Cruzer Blade 4C530001 4096"}
+
+	image := filepath.Join(t.TempDir(), "big.iso")
+	os.WriteFile(image, make([]byte, 5000), 0644)
+	if _, err := svc.WriteImageToUSB(image, target); err == nil || !strings.Contains(err.Error(), "does not fit") {
+		t.Errorf("err = %v", err)
+	}
+	if _, err := svc.WriteImageToUSB(filepath.Join(t.TempDir(), "missing.iso"), target); err == nil {
+		t.Error("expected error for missing image")
+	}
+	if svc.currentJob != nil {
+		t.Errorf("rejected write must not start a job: %+v", svc.currentJob)
+	}
+}
+
+func TestWriteProjectToUSB_Checks(t *testing.T) {
+	svc := newUSBBurnService(t, 8, "")
+	target := USBTarget{DevicePath: "/dev/sdb", Identity: "SanDisk Cruzer Blade 4C530001 4096"}
+
+	project := &models.Project{Entries: []models.FileEntry{{SourcePath: "/src/a", DestPath: "/a", Size: 1 << 20}}}
+	if _, err := svc.WriteProjectToUSB(project, target); err == nil || !strings.Contains(err.Error(), "does not fit") {
+		t.Errorf("err = %v", err)
+	}
+	if _, err := svc.WriteProjectToUSB(&models.Project{}, target); err == nil {
+		t.Error("expected error for empty project")
+	}
+}
+
+func TestWriteBlockDevice(t *testing.T) {
+	dir := t.TempDir()
+	data := bytes.Repeat([]byte("xorriso-ui "), 300) // 3300 байт — не кратно сектору
+	image := filepath.Join(dir, "image.iso")
+	os.WriteFile(image, data, 0644)
+	device := filepath.Join(dir, "device")
+	os.WriteFile(device, bytes.Repeat([]byte{0xff}, 8192), 0644)
+
+	var last int64
+	sum, err := writeBlockDevice(context.Background(), image, device, 512, false, func(done int64) { last = done })
+	if err != nil {
+		t.Fatalf("writeBlockDevice: %v", err)
+	}
+	want := md5.Sum(data)
+	if sum != hex.EncodeToString(want[:]) || last != int64(len(data)) {
+		t.Errorf("sum = %s, progress = %d", sum, last)
+	}
+
+	written, _ := os.ReadFile(device)
+	if !bytes.Equal(written[:len(data)], data) {
+		t.Error("image data was not written")
+	}
+	// Хвост последнего сектора заполнен нулями, дальше данные устройства не тронуты
+	if !bytes.Equal(written[len(data):3584], make([]byte, 3584-len(data))) || written[3584] != 0xff {
+		t.Errorf("sector padding = %v", written[len(data):3590])
+	}
+
+	readSum, err := readBlockDeviceMD5(context.Background(), device, int64(len(data)), 512, false, func(int64) {})
+	if err != nil || readSum != sum {
+		t.Errorf("read back = %s, err = %v", readSum, err)
+	}
+
+	// Испорченный байт обнаруживается при чтении
+	written[100] ^= 1
+	os.WriteFile(device, written, 0644)
+	if readSum, _ := readBlockDeviceMD5(context.Background(), device, int64(len(data)), 512, false, func(int64) {}); readSum == sum {
+		t.Error("corruption was not detected")
+	}
+}
+
+func TestReadBlockDeviceMD5_ShortDevice(t *testing.T) {
+	device := filepath.Join(t.TempDir(), "device")
+	os.WriteFile(device, make([]byte, 1024), 0644)
+	if _, err := readBlockDeviceMD5(context.Background(), device, 4096, 512, false, func(int64) {}); err == nil {
+		t.Error("expected error when the device is shorter than the image")
+	}
+}
+
+func TestWriteBlockDevice_Cancelled(t *testing.T) {
+	dir := t.TempDir()
+	image := filepath.Join(dir, "image.iso")
+	os.WriteFile(image, make([]byte, 4096), 0644)
+	device := filepath.Join(dir, "device")
+	os.WriteFile(device, nil, 0644)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := writeBlockDevice(ctx, image, device, 512, false, func(int64) {}); err == nil {
+		t.Error("expected cancellation error")
+	}
+}
+
+func TestWriteImageToUSB_PendingJobBlocks(t *testing.T) {
+	svc := newUSBBurnService(t, 8, "")
+	target := USBTarget{DevicePath: "/dev/sdb", Identity: "SanDisk Cruzer Blade 4C530001 4096"}
+	image := filepath.Join(t.TempDir(), "small.iso")
+	os.WriteFile(image, make([]byte, 1000), 0644)
+
+	// Задача зарегистрирована, но её горутина ещё не начала запись
+	jobID, _, err := svc.startJob()
+	if err != nil || svc.cancelFn == nil {
+		t.Fatalf("startJob: %v, cancelFn = %v", err, svc.cancelFn)
+	}
+	if _, err := svc.WriteImageToUSB(image, target); err == nil || !strings.Contains(err.Error(), "in progress") {
+		t.Errorf("err = %v", err)
+	}
+	_, err = svc.StartExtract("/dev/sr0", models.ExtractOptions{Paths: []string{"/a"}, DestDir: t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "in progress") {
+		t.Errorf("extract while a job is pending: err = %v", err)
+	}
+	if svc.currentJob.ID != jobID {
+		t.Errorf("pending job replaced by %s", svc.currentJob.ID)
+	}
+}
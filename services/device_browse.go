@@ -0,0 +1,242 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"xorriso-ui/pkg/models"
+	"xorriso-ui/pkg/xorriso"
+)
+
+const (
+	// Сколько прочитанных деревьев хранится для постраничного просмотра
+	imageCacheSize = 4
+	// Размер страницы по умолчанию и наибольший допустимый
+	imagePageSize    = 200
+	imagePageMaxSize = 1000
+)
+
+// ImageQuery selects a page of the file tree of a disc or an ISO image.
+// With Search set, files are searched by name in the whole tree instead of Dir.
+type ImageQuery struct {
+	Session int    `json:"session"` // 0 — последняя сессия
+	Dir     string `json:"dir"`     // пусто — корень образа
+	Search  string `json:"search"`
+	Offset  int    `json:"offset"`
+	Limit   int    `json:"limit"`
+	Reload  bool   `json:"reload"` // перечитать дерево вместо кэша
+}
+
+// ImagePage is a page of the file tree of a disc or an ISO image
+type ImagePage struct {
+	Files  []models.ISOFile `json:"files"`
+	Total  int              `json:"total"`
+	Offset int              `json:"offset"`
+}
+
+// imageTree — прочитанное дерево образа
+type imageTree struct {
+	files  []models.ISOFile // по возрастанию пути
+	byPath map[string]int
+	stamp  string // размер и время изменения ISO-файла; у привода пусто
+	loaded time.Time
+}
+
+// BrowseImage lists the directory tree of the disc in a drive or of an ISO image file
+// page by page. The tree is read once and kept until the media changes, the image
+// file changes or Reload is set.
+func (s *DeviceService) BrowseImage(source string, query ImageQuery) (*ImagePage, error) {
+	if query.Session < 0 {
+		return nil, fmt.Errorf("invalid session number: %d", query.Session)
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open image source: %w", err)
+	}
+	var stamp string
+	if info.Mode().IsRegular() {
+		stamp = fmt.Sprintf("%d:%d", info.Size(), info.ModTime().UnixNano())
+	}
+
+	key := fmt.Sprintf("%s#%d", source, query.Session)
+	s.mu.RLock()
+	tree, ok := s.imageCache[key]
+	s.mu.RUnlock()
+	if !ok || tree.stamp != stamp || query.Reload {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*sessionListTimeout)
+		defer cancel()
+		if tree, err = loadImageTree(ctx, s.executor, source, query.Session); err != nil {
+			return nil, err
+		}
+		tree.stamp = stamp
+		s.storeImageTree(key, tree)
+	}
+
+	files, err := tree.selectFiles(query)
+	if err != nil {
+		return nil, err
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = imagePageSize
+	}
+	limit = min(limit, imagePageMaxSize)
+	offset := min(max(query.Offset, 0), len(files))
+	end := min(offset+limit, len(files))
+
+	return &ImagePage{Files: files[offset:end], Total: len(files), Offset: offset}, nil
+}
+
+// storeImageTree кладёт дерево в кэш, вытесняя самое старое
+func (s *DeviceService) storeImageTree(key string, tree *imageTree) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.imageCache[key]; !ok && len(s.imageCache) >= imageCacheSize {
+		var oldest string
+		for k, t := range s.imageCache {
+			if oldest == "" || t.loaded.Before(s.imageCache[oldest].loaded) {
+				oldest = k
+			}
+		}
+		delete(s.imageCache, oldest)
+	}
+	s.imageCache[key] = tree
+}
+
+// invalidateImageCache забывает деревья всех сессий диска в приводе
+func (s *DeviceService) invalidateImageCache(devicePath string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.imageCache {
+		if strings.HasPrefix(key, devicePath+"#") {
+			delete(s.imageCache, key)
+		}
+	}
+}
+
+// selectFiles возвращает содержимое каталога или найденные по имени файлы
+func (t *imageTree) selectFiles(query ImageQuery) ([]models.ISOFile, error) {
+	if search := strings.ToLower(strings.TrimSpace(query.Search)); search != "" {
+		var found []models.ISOFile
+		for _, f := range t.files {
+			if f.Path != "/" && (strings.Contains(strings.ToLower(f.Name), search) ||
+				strings.Contains(strings.ToLower(f.JolietName), search)) {
+				found = append(found, f)
+			}
+		}
+		return found, nil
+	}
+
+	dir := path.Clean("/" + query.Dir)
+	if i, ok := t.byPath[dir]; dir != "/" && (!ok || !t.files[i].IsDir) {
+		return nil, fmt.Errorf("not a directory in the image: %s", dir)
+	}
+	var children []models.ISOFile
+	for _, f := range t.files {
+		if f.Path != "/" && path.Dir(f.Path) == dir {
+			children = append(children, f)
+		}
+	}
+	// Каталоги первыми, как в проекте
+	slices.SortStableFunc(children, func(a, b models.ISOFile) int {
+		if a.IsDir != b.IsDir {
+			if a.IsDir {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return children, nil
+}
+
+// loadImageTree читает дерево с записанными MD5, затем имена Joliet отдельной загрузкой
+func loadImageTree(ctx context.Context, runner xorriso.Runner, source string, session int) (*imageTree, error) {
+	files, lbas, err := readImageFiles(ctx, runner, source, session)
+	if err != nil {
+		return nil, err
+	}
+	tree := &imageTree{files: files, byPath: make(map[string]int, len(files)), loaded: time.Now()}
+	for i, f := range files {
+		tree.byPath[f.Path] = i
+	}
+
+	// Имена без Rock Ridge: Joliet, а без него — ISO 9660. Их отсутствие не ошибка
+	plain := loadSession(xorriso.NewCommand().ReadFS("norock"), source, session).FindExec("/", "report_lba")
+	if plainResult, err := runner.Run(ctx, plain.Build()...); err == nil {
+		tree.matchJolietNames(lbas, xorriso.ParseReportLBA(plainResult.ResultLines))
+	}
+	return tree, nil
+}
+
+// readImageFiles читает дерево образа по возрастанию пути вместе с записанными MD5
+// и адресами данных файлов
+func readImageFiles(ctx context.Context, runner xorriso.Runner, source string, session int) ([]models.ISOFile, map[string]int64, error) {
+	// -md5 on до загрузки, иначе записанные суммы не читаются
+	cmd := loadSession(xorriso.NewCommand().MD5("on"), source, session)
+	cmd.FindExec("/", "lsdl").FindExec("/", "get_md5").FindExec("/", "report_lba")
+	result, err := runner.Run(ctx, cmd.Build()...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read image tree: %w", err)
+	}
+	files := xorriso.ParseLsdl(result.ResultLines)
+	// Файлы без записанной суммы могут дать ненулевой код — дерево при этом прочитано
+	if len(files) == 0 {
+		errMsg := fmt.Sprintf("xorriso exited with code %d", result.ExitCode)
+		if len(result.InfoLines) > 0 {
+			errMsg = result.InfoLines[len(result.InfoLines)-1]
+		}
+		return nil, nil, fmt.Errorf("failed to read image tree: %s", errMsg)
+	}
+	slices.SortFunc(files, func(a, b models.ISOFile) int { return cmp.Compare(a.Path, b.Path) })
+
+	sums := xorriso.ParseGetMD5(result.ResultLines)
+	for i := range files {
+		files[i].MD5 = sums[files[i].Path]
+	}
+	return files, xorriso.ParseReportLBA(result.ResultLines), nil
+}
+
+// matchJolietNames сопоставляет пути двух деревьев по адресу данных файла и
+// переносит имена на файлы и их родительские каталоги
+func (t *imageTree) matchJolietNames(rockLBAs, plainLBAs map[string]int64) {
+	// Общие данные (жёсткие ссылки, дубликаты) не позволяют однозначно сопоставить пути
+	uniqueByLBA := func(lbas map[string]int64) map[int64]string {
+		byLBA := make(map[int64]string, len(lbas))
+		shared := make(map[int64]bool)
+		for p, lba := range lbas {
+			if _, ok := byLBA[lba]; ok {
+				shared[lba] = true
+			}
+			byLBA[lba] = p
+		}
+		for lba := range shared {
+			delete(byLBA, lba)
+		}
+		return byLBA
+	}
+	rock := uniqueByLBA(rockLBAs)
+
+	for lba, plainPath := range uniqueByLBA(plainLBAs) {
+		rockPath, ok := rock[lba]
+		if !ok {
+			continue
+		}
+		for rockPath != "/" && plainPath != "/" && rockPath != "." && plainPath != "." {
+			i, ok := t.byPath[rockPath]
+			if !ok {
+				break
+			}
+			if name := path.Base(plainPath); name != t.files[i].Name {
+				t.files[i].JolietName = name
+			}
+			rockPath, plainPath = path.Dir(rockPath), path.Dir(plainPath)
+		}
+	}
+}
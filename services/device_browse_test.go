@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"xorriso-ui/pkg/xorriso"
+)
+
+var browseTree = []string{
+	"drwxr-xr-x    1 0        0               0 Oct 18 12:00 '/'",
+	"drwxr-xr-x    1 1000     1000            0 Oct 18 12:00 '/Holiday Photos'",
+	"-rw-r--r--    1 1000     1000           10 Oct 18 12:00 '/Holiday Photos/beach.jpg'",
+	"-rw-r--r--    1 1000     1000           20 Oct 18 12:00 '/Holiday Photos/a-very-long-file-name-for-joliet.jpg'",
+	"-rw-r--r--    1 1000     1000            5 Oct 18 12:00 '/readme.txt'",
+	"drwxr-xr-x    1 1000     1000            0 Oct 18 12:00 '/zeta'",
+	"0123456789abcdef0123456789abcdef  '/readme.txt'",
+	"File data lba:  0 ,       40 ,        1 ,       10 , '/Holiday Photos/beach.jpg'",
+	"File data lba:  0 ,       41 ,        1 ,       20 , '/Holiday Photos/a-very-long-file-name-for-joliet.jpg'",
+	"File data lba:  0 ,       42 ,        1 ,        5 , '/readme.txt'",
+}
+
+var browsePlainTree = []string{
+	"File data lba:  0 ,       40 ,        1 ,       10 , '/HOLIDAY_/BEACH.JPG'",
+	"File data lba:  0 ,       41 ,        1 ,       20 , '/HOLIDAY_/A_VERY_L.JPG'",
+	"File data lba:  0 ,       42 ,        1 ,        5 , '/readme.txt'",
+}
+
+func newBrowseService(t *testing.T, calls *[][]string) (*DeviceService, string) {
+	t.Helper()
+	iso := filepath.Join(t.TempDir(), "disc.iso")
+	os.WriteFile(iso, []byte("iso"), 0644)
+	svc := NewDeviceService(&mockRunner{
+		RunFn: func(ctx context.Context, args ...string) (*xorriso.CmdResult, error) {
+			*calls = append(*calls, args)
+			if slices.Contains(args, "-read_fs") {
+				return &xorriso.CmdResult{ResultLines: browsePlainTree}, nil
+			}
+			return &xorriso.CmdResult{ResultLines: browseTree}, nil
+		},
+	})
+	return svc, iso
+}
+
+func TestBrowseImage_Directory(t *testing.T) {
+	var calls [][]string
+	svc, iso := newBrowseService(t, &calls)
+
+	page, err := svc.BrowseImage(iso, ImageQuery{})
+	if err != nil {
+		t.Fatalf("BrowseImage: %v", err)
+	}
+	var names []string
+	for _, f := range page.Files {
+		names = append(names, f.Name)
+	}
+	// Каталоги первыми
+	if want := []string{"Holiday Photos", "zeta", "readme.txt"}; !slices.Equal(names, want) || page.Total != 3 {
+		t.Errorf("root = %v (total %d), want %v", names, page.Total, want)
+	}
+	if f := page.Files[2]; f.MD5 != "0123456789abcdef0123456789abcdef" || f.JolietName != "" || f.Owner != "1000" {
+		t.Errorf("readme = %+v", f)
+	}
+	if f := page.Files[0]; f.JolietName != "HOLIDAY_" {
+		t.Errorf("folder Joliet name = %q", f.JolietName)
+	}
+
+	want := []string{"-md5", "on", "-indev", iso, "-find", "/", "-exec", "lsdl", "--",
+		"-find", "/", "-exec", "get_md5", "--", "-find", "/", "-exec", "report_lba", "--"}
+	if !slices.Equal(calls[0], want) {
+		t.Errorf("args = %v", calls[0])
+	}
+	if !containsSequence(calls[1], "-read_fs", "norock", "-indev", iso) {
+		t.Errorf("plain names args = %v", calls[1])
+	}
+
+	page, err = svc.BrowseImage(iso, ImageQuery{Dir: "/Holiday Photos", Offset: 1, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 2 || len(page.Files) != 1 || page.Files[0].Name != "beach.jpg" || page.Files[0].JolietName != "BEACH.JPG" {
+		t.Errorf("page = %+v", page)
+	}
+	if len(calls) != 2 {
+		t.Errorf("tree must be read once, got %d runs", len(calls))
+	}
+
+	if _, err := svc.BrowseImage(iso, ImageQuery{Dir: "/readme.txt"}); err == nil {
+		t.Error("expected an error for a file used as a directory")
+	}
+}
+
+func TestBrowseImage_SearchAndReload(t *testing.T) {
+	var calls [][]string
+	svc, iso := newBrowseService(t, &calls)
+
+	page, err := svc.BrowseImage(iso, ImageQuery{Search: "a_very"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 1 || page.Files[0].Path != "/Holiday Photos/a-very-long-file-name-for-joliet.jpg" {
+		t.Errorf("search = %+v", page)
+	}
+
+	// Изменённый ISO-файл перечитывается
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(iso, later, later)
+	if _, err := svc.BrowseImage(iso, ImageQuery{Search: "readme"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 4 {
+		t.Errorf("changed image must be read again, got %d runs", len(calls))
+	}
+
+	if _, err := svc.BrowseImage(filepath.Join(t.TempDir(), "missing.iso"), ImageQuery{}); err == nil {
+		t.Error("expected an error for a missing image")
+	}
+}
+
+func TestStoreImageTree_Evicts(t *testing.T) {
+	svc := NewDeviceService(&mockRunner{})
+	for i := range imageCacheSize + 1 {
+		svc.storeImageTree(string(rune('a'+i)), &imageTree{loaded: time.Unix(int64(i), 0)})
+	}
+	if _, ok := svc.imageCache["a"]; ok || len(svc.imageCache) != imageCacheSize {
+		t.Errorf("cache = %v", svc.imageCache)
+	}
+	svc.storeImageTree("/dev/sr0#1", &imageTree{})
+	svc.invalidateImageCache("/dev/sr0")
+	if _, ok := svc.imageCache["/dev/sr0#1"]; ok {
+		t.Error("media change must drop the tree")
+	}
+}
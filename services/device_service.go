@@ -32,8 +32,11 @@ type DeviceService struct {
 	// Paths for /proc and /sys, overridable for testing
 	procCdromInfoPath string
 	sysBlockPath      string
+	mountsPath        string
 	// Кэш профилей привода — профили не меняются для одного и того же устройства
 	profileCache map[string][]models.MediaProfile
+	// Прочитанные деревья дисков и ISO-файлов для BrowseImage, ключ — "путь#сессия"
+	imageCache map[string]*imageTree
 }
 
 func NewDeviceService(executor xorriso.Runner) *DeviceService {
@@ -43,7 +46,9 @@ func NewDeviceService(executor xorriso.Runner) *DeviceService {
 		emitEvent:         defaultEmitEvent,
 		procCdromInfoPath: "/proc/sys/dev/cdrom/info",
 		sysBlockPath:      "/sys/block",
+		mountsPath:        "/proc/self/mounts",
 		profileCache:      make(map[string][]models.MediaProfile),
+		imageCache:        make(map[string]*imageTree),
 	}
 }
 
@@ -297,6 +302,17 @@ func (s *DeviceService) GetMediaInfo(devicePath string) (*models.MediaInfo, erro
 			info.CreationTime = extractAfterColon(line)
 		case strings.Contains(line, "Modif. Time  :"):
 			info.ModifyTime = extractAfterColon(line)
+			info.VolumeUUID = volumeUUIDFromTime(info.ModifyTime)
+		case strings.Contains(line, "Expir. Time  :"):
+			info.ExpireTime = extractAfterColon(line)
+		case strings.Contains(line, "Eff. Time    :"):
+			info.EffectiveTime = extractAfterColon(line)
+		case strings.Contains(line, "Abstract File:"):
+			info.AbstractFile = extractAfterColon(line)
+		case strings.Contains(line, "Biblio File  :"):
+			info.BiblioFile = extractAfterColon(line)
+		case strings.Contains(line, "CopyrightFile:"):
+			info.CopyrightFile = extractAfterColon(line)
 		}
 	}
 
@@ -331,6 +347,7 @@ func (s *DeviceService) LoadTray(devicePath string) error {
 	}
 
 	s.invalidateProfileCache(devicePath)
+	s.invalidateImageCache(devicePath)
 	return nil
 }
 
@@ -346,6 +363,7 @@ func (s *DeviceService) EjectDisc(devicePath string) error {
 	}
 
 	s.invalidateProfileCache(devicePath)
+	s.invalidateImageCache(devicePath)
 	return nil
 }
 
@@ -384,10 +402,11 @@ func extractMediaProduct(line string) string {
 	return val
 }
 
-// watchMediaChanges слушает udev netlink события для оптических приводов.
+// watchMediaChanges слушает udev netlink события для оптических приводов и USB.
 // При обнаружении change-события (вставка/извлечение диска) отправляет
-// событие device:media-changed во фронтенд. Использует debounce 2с для
-// предотвращения дублирования событий.
+// событие device:media-changed во фронтенд, при подключении или извлечении
+// USB-накопителя — новый список device:usb-list-updated. Для событий приводов
+// использует debounce 2с для предотвращения дублирования.
 func (s *DeviceService) watchMediaChanges() {
 	mon, err := udev.New()
 	if err != nil {
@@ -414,6 +433,15 @@ func (s *DeviceService) watchMediaChanges() {
 	debounceInterval := 2 * time.Second
 
 	for ev := range events {
+		if !ev.Optical() {
+			// Подключение и извлечение USB-накопителей и их разделов
+			if ev.Action == "add" || ev.Action == "remove" {
+				if devices, err := s.ListUSBDevices(); err == nil {
+					s.emitEvent(models.EventUSBListUpdated, devices)
+				}
+			}
+			continue
+		}
 		if ev.Action != "change" {
 			continue
 		}
@@ -424,6 +452,7 @@ func (s *DeviceService) watchMediaChanges() {
 		}
 		lastEvent[devPath] = now
 		s.invalidateProfileCache(devPath)
+		s.invalidateImageCache(devPath)
 		s.emitEvent(models.EventDeviceMediaChanged, map[string]string{
 			"devicePath": devPath,
 		})
@@ -292,8 +292,13 @@ func TestGetMediaInfo(t *testing.T) {
 					"Preparer Id  : XORRISO",
 					"App Id       : XORRISO",
 					"System Id    : LINUX",
-					"Creation Time: 2024-01-15 10:00:00",
-					"Modif. Time  : 2024-01-15 10:00:00",
+					"CopyrightFile: COPYING",
+					"Abstract File: ",
+					"Biblio File  : ",
+					"Creation Time: 2024011510000000",
+					"Modif. Time  : 2024011510000000",
+					"Expir. Time  : 0000000000000000",
+					"Eff. Time    : 2024020100000000",
 					"Media summary: 1 session, 100000 blocks, 195.3m",
 				},
 			}, nil
@@ -332,4 +337,10 @@ func TestGetMediaInfo(t *testing.T) {
 	if info.SystemID != "LINUX" {
 		t.Errorf("SystemID = %q, want LINUX", info.SystemID)
 	}
+	if info.CopyrightFile != "COPYING" || info.AbstractFile != "" {
+		t.Errorf("file references = %q, %q", info.CopyrightFile, info.AbstractFile)
+	}
+	if info.VolumeUUID != "2024011510000000" || info.EffectiveTime != "2024020100000000" {
+		t.Errorf("VolumeUUID = %q, EffectiveTime = %q", info.VolumeUUID, info.EffectiveTime)
+	}
 }
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"xorriso-ui/pkg/models"
+	"xorriso-ui/pkg/xorriso"
+)
+
+// Загрузка дерева сессии с оптического диска включает раскрутку и чтение каталогов
+const sessionListTimeout = 5 * time.Minute
+
+// SessionChange is a difference between two sessions of a multisession disc
+type SessionChange struct {
+	Kind    string `json:"kind"` // SourceAdded, SourceModified, SourceRemoved
+	Path    string `json:"path"`
+	IsDir   bool   `json:"isDir"`
+	OldSize int64  `json:"oldSize"`
+	NewSize int64  `json:"newSize"`
+}
+
+// ListSessionFiles returns the file tree of a session of the disc as it was at the
+// time the session was written. Session numbers are those of MediaInfo.SessionList;
+// 0 loads the last session.
+func (s *DeviceService) ListSessionFiles(devicePath string, session int) ([]models.ISOFile, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sessionListTimeout)
+	defer cancel()
+	return listSessionFiles(ctx, s.executor, devicePath, session)
+}
+
+// CompareSessions lists the files and folders that were added, removed, or changed
+// in size or type between two sessions of the disc
+func (s *DeviceService) CompareSessions(devicePath string, from, to int) ([]SessionChange, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*sessionListTimeout)
+	defer cancel()
+
+	older, err := listSessionFiles(ctx, s.executor, devicePath, from)
+	if err != nil {
+		return nil, err
+	}
+	newer, err := listSessionFiles(ctx, s.executor, devicePath, to)
+	if err != nil {
+		return nil, err
+	}
+	return diffSessions(older, newer), nil
+}
+
+// loadSession добавляет загрузку выбранной сессии диска или образа
+func loadSession(cmd *xorriso.CommandBuilder, devicePath string, session int) *xorriso.CommandBuilder {
+	if session > 0 {
+		// -load действует на следующий -indev
+		cmd.Load("session", strconv.Itoa(session))
+	}
+	return cmd.InDevice(devicePath)
+}
+
+// listSessionFiles читает дерево сессии через -find / -exec lsdl
+func listSessionFiles(ctx context.Context, runner xorriso.Runner, devicePath string, session int) ([]models.ISOFile, error) {
+	if session < 0 {
+		return nil, fmt.Errorf("invalid session number: %d", session)
+	}
+	cmd := loadSession(xorriso.NewCommand(), devicePath, session).FindExec("/", "lsdl")
+	result, err := runner.Run(ctx, cmd.Build()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session %d: %w", session, err)
+	}
+	if result.ExitCode != 0 {
+		errMsg := fmt.Sprintf("xorriso exited with code %d", result.ExitCode)
+		if len(result.InfoLines) > 0 {
+			errMsg = result.InfoLines[len(result.InfoLines)-1]
+		}
+		return nil, fmt.Errorf("failed to read session %d: %s", session, errMsg)
+	}
+	return xorriso.ParseLsdl(result.ResultLines), nil
+}
+
+// diffSessions сравнивает два листинга по пути, типу и размеру
+func diffSessions(older, newer []models.ISOFile) []SessionChange {
+	before := make(map[string]models.ISOFile, len(older))
+	for _, f := range older {
+		before[f.Path] = f
+	}
+	after := make(map[string]bool, len(newer))
+
+	changes := []SessionChange{}
+	for _, f := range newer {
+		after[f.Path] = true
+		old, ok := before[f.Path]
+		switch {
+		case !ok:
+			changes = append(changes, SessionChange{Kind: SourceAdded, Path: f.Path, IsDir: f.IsDir, NewSize: f.Size})
+		case old.IsDir != f.IsDir || old.Type != f.Type || old.Size != f.Size:
+			changes = append(changes, SessionChange{
+				Kind: SourceModified, Path: f.Path, IsDir: f.IsDir, OldSize: old.Size, NewSize: f.Size,
+			})
+		}
+	}
+	for _, f := range older {
+		if !after[f.Path] {
+			changes = append(changes, SessionChange{Kind: SourceRemoved, Path: f.Path, IsDir: f.IsDir, OldSize: f.Size})
+		}
+	}
+
+	slices.SortStableFunc(changes, func(a, b SessionChange) int { return cmp.Compare(a.Path, b.Path) })
+	return changes
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"xorriso-ui/pkg/models"
+	"xorriso-ui/pkg/xorriso"
+)
+
+// sessionRunner отдаёт листинг сессии по номеру из -load session N (0 — последняя)
+func sessionRunner(sessions map[string][]string, calls *[][]string) *mockRunner {
+	return &mockRunner{
+		RunFn: func(ctx context.Context, args ...string) (*xorriso.CmdResult, error) {
+			*calls = append(*calls, args)
+			number := "0"
+			if i := slices.Index(args, "-load"); i >= 0 {
+				number = args[i+2]
+			}
+			lines, ok := sessions[number]
+			if !ok {
+				return &xorriso.CmdResult{ExitCode: 5, InfoLines: []string{"xorriso : FAILURE : No session " + number}}, nil
+			}
+			return &xorriso.CmdResult{ResultLines: lines}, nil
+		},
+	}
+}
+
+var sessionListings = map[string][]string{
+	"1": {
+		"drwxr-xr-x    1 0        0               0 Oct 18 12:00 '/'",
+		"drwxr-xr-x    1 1000     1000            0 Oct 18 12:00 '/docs'",
+		"-rw-r--r--    1 1000     1000           10 Oct 18 12:00 '/docs/a.txt'",
+		"-rw-r--r--    1 1000     1000            5 Oct 18 12:00 '/docs/b.txt'",
+	},
+	"2": {
+		"drwxr-xr-x    1 0        0               0 Oct 18 12:00 '/'",
+		"drwxr-xr-x    1 1000     1000            0 Oct 18 12:00 '/docs'",
+		"-rw-r--r--    1 1000     1000           12 Oct 18 12:00 '/docs/a.txt'",
+		"-rw-r--r--    1 1000     1000            3 Oct 18 12:00 '/docs/c.txt'",
+	},
+}
+
+func TestListSessionFiles(t *testing.T) {
+	var calls [][]string
+	svc := NewDeviceService(sessionRunner(sessionListings, &calls))
+
+	files, err := svc.ListSessionFiles("/dev/sr0", 1)
+	if err != nil {
+		t.Fatalf("ListSessionFiles: %v", err)
+	}
+	if len(files) != 4 || files[2].Path != "/docs/a.txt" || files[2].Size != 10 {
+		t.Errorf("files = %+v", files)
+	}
+	want := []string{"-load", "session", "1", "-indev", "/dev/sr0", "-find", "/", "-exec", "lsdl", "--"}
+	if !slices.Equal(calls[0], want) {
+		t.Errorf("args = %v, want %v", calls[0], want)
+	}
+
+	if _, err := svc.ListSessionFiles("/dev/sr0", 3); err == nil {
+		t.Error("expected an error for a missing session")
+	}
+	if _, err := svc.ListSessionFiles("/dev/sr0", -1); err == nil {
+		t.Error("expected an error for a negative session")
+	}
+}
+
+func TestListSessionFiles_LastSession(t *testing.T) {
+	var calls [][]string
+	svc := NewDeviceService(sessionRunner(map[string][]string{"0": sessionListings["2"]}, &calls))
+
+	if _, err := svc.ListSessionFiles("/dev/sr0", 0); err != nil {
+		t.Fatalf("ListSessionFiles: %v", err)
+	}
+	if slices.Contains(calls[0], "-load") {
+		t.Errorf("last session must be loaded without -load: %v", calls[0])
+	}
+}
+
+func TestCompareSessions(t *testing.T) {
+	var calls [][]string
+	svc := NewDeviceService(sessionRunner(sessionListings, &calls))
+
+	changes, err := svc.CompareSessions("/dev/sr0", 1, 2)
+	if err != nil {
+		t.Fatalf("CompareSessions: %v", err)
+	}
+	want := []SessionChange{
+		{Kind: SourceModified, Path: "/docs/a.txt", OldSize: 10, NewSize: 12},
+		{Kind: SourceRemoved, Path: "/docs/b.txt", OldSize: 5},
+		{Kind: SourceAdded, Path: "/docs/c.txt", NewSize: 3},
+	}
+	if !slices.Equal(changes, want) {
+		t.Errorf("changes = %+v, want %+v", changes, want)
+	}
+}
+
+func TestDiffSessions_TypeChange(t *testing.T) {
+	older := []models.ISOFile{{Path: "/x", Name: "x"}}
+	newer := []models.ISOFile{{Path: "/x", Name: "x", IsDir: true}}
+	changes := diffSessions(older, newer)
+	if len(changes) != 1 || changes[0].Kind != SourceModified || !changes[0].IsDir {
+		t.Errorf("changes = %+v", changes)
+	}
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"xorriso-ui/pkg/models"
+)
+
+// Размер сектора в /sys/block/*/size не зависит от устройства
+const sysfsSectorSize = 512
+
+// ListUSBDevices returns removable USB block devices that can receive an ISO image,
+// with their partitions, mount points and the identity to confirm before writing
+func (s *DeviceService) ListUSBDevices() ([]models.USBDevice, error) {
+	devices, err := discoverUSBDevices(s.sysBlockPath, s.mountsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover USB devices: %w", err)
+	}
+	return devices, nil
+}
+
+// discoverUSBDevices ищет в /sys/block съёмные устройства на шине USB
+func discoverUSBDevices(sysBlockPath, mountsPath string) ([]models.USBDevice, error) {
+	entries, err := os.ReadDir(sysBlockPath)
+	if err != nil {
+		return nil, err
+	}
+	mounts := readMounts(mountsPath)
+
+	devices := []models.USBDevice{}
+	for _, e := range entries {
+		if dev, ok := readUSBDevice(sysBlockPath, e.Name(), mounts); ok {
+			devices = append(devices, dev)
+		}
+	}
+	return devices, nil
+}
+
+// readUSBDevice читает описание устройства из sysfs. ok = false, если это не
+// съёмное USB-устройство или в нём нет носителя (кардридер без карты)
+func readUSBDevice(sysBlockPath, name string, mounts map[string]string) (models.USBDevice, bool) {
+	sysDir := filepath.Join(sysBlockPath, name)
+	realDir, err := filepath.EvalSymlinks(sysDir)
+	if err != nil || !strings.Contains(realDir, "/usb") {
+		return models.USBDevice{}, false
+	}
+	if readSysFile(filepath.Join(sysDir, "removable")) != "1" {
+		return models.USBDevice{}, false
+	}
+	sectors, _ := strconv.ParseInt(readSysFile(filepath.Join(sysDir, "size")), 10, 64)
+	if sectors <= 0 {
+		return models.USBDevice{}, false
+	}
+
+	devPath := "/dev/" + name
+	dev := models.USBDevice{
+		Path:       devPath,
+		Vendor:     readSysFile(filepath.Join(sysDir, "device", "vendor")),
+		Model:      readSysFile(filepath.Join(sysDir, "device", "model")),
+		Serial:     usbSerial(realDir),
+		Size:       sectors * sysfsSectorSize,
+		MountPoint: mounts[devPath],
+		Holders:    sysHolders(sysDir),
+		Partitions: []models.USBPartition{},
+	}
+	dev.Busy = dev.MountPoint != "" || dev.Holders != ""
+
+	children, _ := os.ReadDir(sysDir)
+	for _, c := range children {
+		partDir := filepath.Join(sysDir, c.Name())
+		if !strings.HasPrefix(c.Name(), name) || readSysFile(filepath.Join(partDir, "partition")) == "" {
+			continue
+		}
+		part := models.USBPartition{
+			Path:       "/dev/" + c.Name(),
+			MountPoint: mounts["/dev/"+c.Name()],
+			Holders:    sysHolders(partDir),
+		}
+		dev.Busy = dev.Busy || part.MountPoint != "" || part.Holders != ""
+		dev.Partitions = append(dev.Partitions, part)
+	}
+
+	dev.Identity = strings.Join(strings.Fields(fmt.Sprintf("%s %s %s %d", dev.Vendor, dev.Model, dev.Serial, dev.Size)), " ")
+	return dev, true
+}
+
+// usbSerial ищет серийный номер в каталоге USB-устройства выше блочного
+func usbSerial(realDir string) string {
+	for dir := realDir; dir != "/" && dir != "."; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, "idVendor")); err == nil {
+			return readSysFile(filepath.Join(dir, "serial"))
+		}
+	}
+	return ""
+}
+
+// sysHolders перечисляет устройства, построенные поверх блочного (LVM, dm-crypt, md)
+func sysHolders(sysDir string) string {
+	entries, err := os.ReadDir(filepath.Join(sysDir, "holders"))
+	if err != nil {
+		return ""
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return strings.Join(names, ",")
+}
+
+// readMounts возвращает точки монтирования по пути устройства из /proc/self/mounts.
+// Ссылки вида /dev/disk/by-uuid/... раскрываются до самого устройства.
+func readMounts(mountsPath string) map[string]string {
+	mounts := make(map[string]string)
+	f, err := os.Open(mountsPath)
+	if err != nil {
+		return mounts
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.HasPrefix(fields[0], "/dev/") {
+			continue
+		}
+		dev := resolveSymlink(unescapeMountField(fields[0]))
+		if _, ok := mounts[dev]; !ok {
+			mounts[dev] = unescapeMountField(fields[1])
+		}
+	}
+	return mounts
+}
+
+// unescapeMountField раскрывает восьмеричные escape-последовательности (\040 — пробел)
+func unescapeMountField(field string) string {
+	if !strings.Contains(field, `\`) {
+		return field
+	}
+	var b strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] == '\\' && i+4 <= len(field) {
+			if c, err := strconv.ParseUint(field[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(field[i])
+	}
+	return b.String()
+}
+
+// usbBusyReason описывает, чем занято устройство, для сообщения об ошибке
+func usbBusyReason(dev models.USBDevice) string {
+	var reasons []string
+	if dev.MountPoint != "" {
+		reasons = append(reasons, fmt.Sprintf("%s mounted on %s", dev.Path, dev.MountPoint))
+	}
+	if dev.Holders != "" {
+		reasons = append(reasons, fmt.Sprintf("%s used by %s", dev.Path, dev.Holders))
+	}
+	for _, p := range dev.Partitions {
+		if p.MountPoint != "" {
+			reasons = append(reasons, fmt.Sprintf("%s mounted on %s", p.Path, p.MountPoint))
+		}
+		if p.Holders != "" {
+			reasons = append(reasons, fmt.Sprintf("%s used by %s", p.Path, p.Holders))
+		}
+	}
+	return strings.Join(reasons, ", ")
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// fakeUSBSysfs создаёт /sys/block с USB-накопителем sdb (раздел sdb1) размером
+// sectors секторов и системным диском sda на SATA. Возвращает /sys/block и каталог sdb.
+func fakeUSBSysfs(t *testing.T, sectors int) (string, string) {
+	t.Helper()
+	root := t.TempDir()
+	write := func(p, content string) {
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := os.WriteFile(p, []byte(content+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	usbDev := filepath.Join(root, "devices/pci0000:00/0000:00:14.0/usb2/2-1")
+	write(filepath.Join(usbDev, "idVendor"), "0781")
+	write(filepath.Join(usbDev, "serial"), "4C530001")
+	sdb := filepath.Join(usbDev, "2-1:1.0/host6/target6:0:0/6:0:0:0/block/sdb")
+	write(filepath.Join(sdb, "removable"), "1")
+	write(filepath.Join(sdb, "size"), strconv.Itoa(sectors))
+	write(filepath.Join(sdb, "device/vendor"), "SanDisk ")
+	write(filepath.Join(sdb, "device/model"), "Cruzer Blade")
+	write(filepath.Join(sdb, "queue/logical_block_size"), "512")
+	write(filepath.Join(sdb, "sdb1/partition"), "1")
+	os.MkdirAll(filepath.Join(sdb, "holders"), 0755)
+	os.MkdirAll(filepath.Join(sdb, "sdb1/holders"), 0755)
+
+	sda := filepath.Join(root, "devices/pci0000:00/0000:00:17.0/ata1/host0/target0:0:0/0:0:0:0/block/sda")
+	write(filepath.Join(sda, "removable"), "0")
+	write(filepath.Join(sda, "size"), "1000215216")
+
+	sysBlock := filepath.Join(root, "sys/block")
+	os.MkdirAll(sysBlock, 0755)
+	os.Symlink(sdb, filepath.Join(sysBlock, "sdb"))
+	os.Symlink(sda, filepath.Join(sysBlock, "sda"))
+	return sysBlock, sdb
+}
+
+func writeMounts(t *testing.T, content string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "mounts")
+	os.WriteFile(p, []byte(content), 0644)
+	return p
+}
+
+func TestDiscoverUSBDevices(t *testing.T) {
+	sysBlock, _ := fakeUSBSysfs(t, 60062500)
+	mounts := writeMounts(t, "/dev/sda1 / ext4 rw,relatime 0 0\nproc /proc proc rw 0 0\n")
+
+	devices, err := discoverUSBDevices(sysBlock, mounts)
+	if err != nil {
+		t.Fatalf("discoverUSBDevices: %v", err)
+	}
+	if len(devices) != 1 {
+		t.Fatalf("devices = %+v", devices)
+	}
+	dev := devices[0]
+	if dev.Path != "/dev/sdb" || dev.Vendor != "SanDisk" || dev.Model != "Cruzer Blade" ||
+		dev.Serial != "4C530001" || dev.Size != 60062500*512 || dev.Busy {
+		t.Errorf("device = %+v", dev)
+	}
+	if dev.Identity != "SanDisk Cruzer Blade 4C530001 30752000000" {
+		t.Errorf("Identity = %q", dev.Identity)
+	}
+	if len(dev.Partitions) != 1 || dev.Partitions[0].Path != "/dev/sdb1" || dev.Partitions[0].MountPoint != "" {
+		t.Errorf("partitions = %+v", dev.Partitions)
+	}
+}
+
+func TestDiscoverUSBDevices_Busy(t *testing.T) {
+	sysBlock, sdb := fakeUSBSysfs(t, 60062500)
+	mounts := writeMounts(t, "/dev/sdb1 /media/user/MY\\040STICK vfat rw 0 0\n")
+
+	devices, _ := discoverUSBDevices(sysBlock, mounts)
+	if len(devices) != 1 || !devices[0].Busy || devices[0].Partitions[0].MountPoint != "/media/user/MY STICK" {
+		t.Fatalf("devices = %+v", devices)
+	}
+	if got := usbBusyReason(devices[0]); got != "/dev/sdb1 mounted on /media/user/MY STICK" {
+		t.Errorf("busy reason = %q", got)
+	}
+
+	// Раздел под dm-crypt без монтирования тоже занят
+	os.MkdirAll(filepath.Join(sdb, "sdb1/holders/dm-0"), 0755)
+	devices, _ = discoverUSBDevices(sysBlock, writeMounts(t, ""))
+	if !devices[0].Busy || devices[0].Partitions[0].Holders != "dm-0" {
+		t.Errorf("devices = %+v", devices)
+	}
+}
+
+func TestDiscoverUSBDevices_NoMedium(t *testing.T) {
+	sysBlock, _ := fakeUSBSysfs(t, 0)
+	devices, err := discoverUSBDevices(sysBlock, writeMounts(t, ""))
+	if err != nil || len(devices) != 0 {
+		t.Errorf("devices = %+v, err = %v", devices, err)
+	}
+}
+
+func TestUnescapeMountField(t *testing.T) {
+	tests := map[string]string{
+		"/media/user/disk":         "/media/user/disk",
+		`/media/user/My\040Disk`:   "/media/user/My Disk",
+		`/mnt/tab\011and\134slash`: "/mnt/tab\tand\\slash",
+		`/mnt/trailing\04`:         `/mnt/trailing\04`,
+		`/mnt/not\999octal`:        `/mnt/not\999octal`,
+	}
+	for in, want := range tests {
+		if got := unescapeMountField(in); got != want {
+			t.Errorf("unescapeMountField(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"xorriso-ui/pkg/models"
+
+	"github.com/google/uuid"
+	"github.com/wailsapp/wails/v3/pkg/application"
+	"golang.org/x/sys/unix"
+)
+
+// autosaveInterval — период фонового сохранения изменённых проектов
+const autosaveInterval = time.Minute
+
+// RecoverableProject is an autosave left by a session that was not closed cleanly
+type RecoverableProject struct {
+	ID       string    `json:"id"` // ID сеанса, в котором проект был открыт
+	Name     string    `json:"name"`
+	FilePath string    `json:"filePath"` // пусто у несохранённого проекта
+	SavedAt  time.Time `json:"savedAt"`
+	Entries  int       `json:"entries"`
+}
+
+// errAutosaveInUse — автосохранение принадлежит работающему сеансу
+var errAutosaveInUse = errors.New("autosave is in use by a running session")
+
+// openProject — последнее известное состояние открытого проекта для автосохранения
+type openProject struct {
+	project models.Project
+	dirty   bool
+}
+
+func (s *ProjectService) ServiceName() string {
+	return "ProjectService"
+}
+
+// ServiceStartup starts the periodic autosave of open projects
+func (s *ProjectService) ServiceStartup(ctx context.Context, options application.ServiceOptions) error {
+	ctx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
+	s.stopAutosave = cancel
+	s.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(autosaveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.autosave()
+			}
+		}
+	}()
+	return nil
+}
+
+// ServiceShutdown stops the autosave and source watches. The application exits
+// normally, so the autosaves of this session are removed and not offered for recovery.
+func (s *ProjectService) ServiceShutdown() error {
+	s.autosaveMu.Lock()
+	defer s.autosaveMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopAutosave != nil {
+		s.stopAutosave()
+	}
+	for id, w := range s.watches {
+		w.stop()
+		delete(s.watches, id)
+	}
+	for id := range s.open {
+		_ = os.Remove(autosavePath(id))
+	}
+	clear(s.open)
+	for id, lock := range s.autosaveLocks {
+		unlockAutosave(lock)
+		delete(s.autosaveLocks, id)
+	}
+	return nil
+}
+
+// autosaveDir — каталог автосохранений в XDG state dir
+func autosaveDir() string {
+	stateDir := os.Getenv("XDG_STATE_HOME")
+	if stateDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return filepath.Join(os.TempDir(), "xorriso-ui", "autosave")
+		}
+		stateDir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(stateDir, "xorriso-ui", "autosave")
+}
+
+func autosavePath(projectID string) string {
+	return filepath.Join(autosaveDir(), projectID+".json")
+}
+
+func autosaveLockPath(projectID string) string {
+	return filepath.Join(autosaveDir(), projectID+".lock")
+}
+
+// lockAutosave берёт блокировку автосохранения проекта. Ядро снимает её при
+// завершении процесса, в том числе аварийном, поэтому автосохранение без
+// блокировки осталось от упавшего сеанса.
+func lockAutosave(projectID string) (*os.File, error) {
+	f, err := os.OpenFile(autosaveLockPath(projectID), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.Flock(int(f.Fd()), unix.LOCK_EX|unix.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, unix.EWOULDBLOCK) {
+			return nil, errAutosaveInUse
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+// unlockAutosave удаляет файл блокировки и снимает её
+func unlockAutosave(lock *os.File) {
+	_ = os.Remove(lock.Name())
+	_ = lock.Close()
+}
+
+// trackLocked запоминает состояние проекта после изменения. Вызывается под s.mu.
+func (s *ProjectService) trackLocked(project *models.Project) {
+	if project.ID == "" {
+		return
+	}
+	snapshot := *project
+	snapshot.Entries = slices.Clone(project.Entries)
+	snapshot.SortRules = slices.Clone(project.SortRules)
+	snapshot.Exclude = slices.Clone(project.Exclude)
+	snapshot.Removed = slices.Clone(project.Removed)
+	s.open[project.ID] = &openProject{project: snapshot, dirty: true}
+}
+
+// autosave записывает изменённые с прошлого раза проекты
+func (s *ProjectService) autosave() {
+	s.autosaveMu.Lock()
+	defer s.autosaveMu.Unlock()
+
+	s.mu.Lock()
+	var pending []models.Project
+	for _, p := range s.open {
+		if p.dirty {
+			pending = append(pending, p.project)
+			p.dirty = false
+		}
+	}
+	s.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	if err := os.MkdirAll(autosaveDir(), 0700); err != nil {
+		return
+	}
+	for _, p := range pending {
+		// Блокировка берётся до записи, чтобы другой сеанс не принял файл за брошенный
+		if s.autosaveLocks[p.ID] == nil {
+			lock, err := lockAutosave(p.ID)
+			if err != nil {
+				continue
+			}
+			s.autosaveLocks[p.ID] = lock
+		}
+		data, err := json.Marshal(p)
+		if err != nil {
+			continue
+		}
+		_ = writeFileAtomic(autosavePath(p.ID), data, 0600)
+	}
+}
+
+// forgetAutosave удаляет автосохранение проекта, изменения которого больше не нужно восстанавливать
+func (s *ProjectService) forgetAutosave(projectID string, closed bool) {
+	s.autosaveMu.Lock()
+	defer s.autosaveMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if closed {
+		delete(s.open, projectID)
+	} else if p, ok := s.open[projectID]; ok {
+		p.dirty = false
+	}
+	_ = os.Remove(autosavePath(projectID))
+	if lock, ok := s.autosaveLocks[projectID]; ok {
+		unlockAutosave(lock)
+		delete(s.autosaveLocks, projectID)
+	}
+}
+
+// ListRecoverableProjects returns autosaves of projects that were not closed
+// cleanly in a previous session, newest first. Autosaves of projects open in
+// this or another running instance are skipped.
+func (s *ProjectService) ListRecoverableProjects() ([]RecoverableProject, error) {
+	files, err := filepath.Glob(filepath.Join(autosaveDir(), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	open := make(map[string]bool, len(s.open))
+	for id := range s.open {
+		open[id] = true
+	}
+	s.mu.Unlock()
+
+	list := []RecoverableProject{}
+	for _, f := range files {
+		id := strings.TrimSuffix(filepath.Base(f), ".json")
+		if open[id] {
+			continue
+		}
+		// Пока другой экземпляр приложения держит блокировку, его проект не брошен
+		lock, err := lockRecoverable(id)
+		if err != nil {
+			continue
+		}
+		project, err := readAutosave(id)
+		// файл блокировки брошенного автосохранения создан проверкой — не оставляем его
+		unlockAutosave(lock)
+		if err != nil {
+			continue
+		}
+		info, err := os.Stat(f)
+		if err != nil {
+			continue
+		}
+		list = append(list, RecoverableProject{
+			ID:       id,
+			Name:     project.Name,
+			FilePath: project.FilePath,
+			SavedAt:  info.ModTime(),
+			Entries:  len(project.Entries),
+		})
+	}
+	slices.SortFunc(list, func(a, b RecoverableProject) int { return b.SavedAt.Compare(a.SavedAt) })
+	return list, nil
+}
+
+// RecoverProject opens an autosaved project in a new editing session. The project
+// keeps its file path, so saving it overwrites the original file.
+func (s *ProjectService) RecoverProject(id string) (*models.Project, error) {
+	lock, err := lockRecoverable(id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlockAutosave(lock)
+	project, err := readAutosave(id)
+	if err != nil {
+		return nil, err
+	}
+	project.ID = uuid.New().String()
+
+	s.mu.Lock()
+	// Восстановленные изменения ещё не сохранены — новый сеанс сразу автосохраняет их
+	s.trackLocked(project)
+	s.mu.Unlock()
+	_ = os.Remove(autosavePath(id))
+	return project, nil
+}
+
+// DiscardRecoverableProject deletes an autosave without restoring it
+func (s *ProjectService) DiscardRecoverableProject(id string) error {
+	lock, err := lockRecoverable(id)
+	if err != nil {
+		return err
+	}
+	defer unlockAutosave(lock)
+	if err := os.Remove(autosavePath(id)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("autosave not found: %s", id)
+		}
+		return err
+	}
+	return nil
+}
+
+// lockRecoverable проверяет ID автосохранения и берёт его блокировку
+func lockRecoverable(id string) (*os.File, error) {
+	// ID сеанса — UUID; прочие имена не должны выходить за пределы каталога
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("autosave not found: %s", id)
+	}
+	lock, err := lockAutosave(id)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("autosave not found: %s", id)
+	}
+	return lock, err
+}
+
+func readAutosave(id string) (*models.Project, error) {
+	// ID сеанса — UUID; прочие имена не должны выходить за пределы каталога
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("autosave not found: %s", id)
+	}
+	data, err := os.ReadFile(autosavePath(id))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("autosave not found: %s", id)
+		}
+		return nil, err
+	}
+	migrated, _, err := migrateProject(data)
+	if err != nil {
+		return nil, err
+	}
+	var project models.Project
+	if err := json.Unmarshal(migrated, &project); err != nil {
+		return nil, err
+	}
+	project.Name = cmp.Or(project.Name, "Untitled")
+	return &project, nil
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"xorriso-ui/pkg/models"
+)
+
+func TestSaveProject_AtomicWithBackup(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+	svc := NewProjectService()
+	project := svc.NewProject("Backup", "BACKUP")
+	project.FilePath = filepath.Join(dir, "backup.xorriso-project")
+
+	if err := svc.SaveProject(project); err != nil {
+		t.Fatalf("SaveProject: %v", err)
+	}
+	if _, err := os.Stat(project.FilePath + ".bak"); !os.IsNotExist(err) {
+		t.Errorf("first save must not create a backup: %v", err)
+	}
+
+	project.VolumeID = "BACKUP_2"
+	if err := svc.SaveProject(project); err != nil {
+		t.Fatalf("SaveProject: %v", err)
+	}
+	var previous models.Project
+	data, err := os.ReadFile(project.FilePath + ".bak")
+	if err != nil {
+		t.Fatalf("backup not written: %v", err)
+	}
+	if err := json.Unmarshal(data, &previous); err != nil || previous.VolumeID != "BACKUP" {
+		t.Errorf("backup holds %q, want the previous version (%v)", previous.VolumeID, err)
+	}
+
+	// Временные файлы не остаются рядом с проектом
+	files, _ := os.ReadDir(dir)
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), ".tmp") {
+			t.Errorf("leftover temp file %s", f.Name())
+		}
+	}
+}
+
+func TestAutosave_RecoverAfterCrash(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	src := t.TempDir()
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("hello"), 0644)
+
+	crashed := NewProjectService()
+	crashed.emitEvent = noopEmit
+	untitled := crashed.NewProject("Untitled", "DATA")
+	if _, err := crashed.AddFiles(untitled, []string{filepath.Join(src, "a.txt")}, "/"); err != nil {
+		t.Fatal(err)
+	}
+	crashed.autosave()
+	if _, err := os.Stat(autosavePath(untitled.ID)); err != nil {
+		t.Fatalf("autosave not written: %v", err)
+	}
+
+	// Сеанс, в котором проект открыт, не предлагает его восстановить
+	list, err := crashed.ListRecoverableProjects()
+	if err != nil || len(list) != 0 {
+		t.Fatalf("open project listed as recoverable: %+v %v", list, err)
+	}
+
+	// Другой работающий экземпляр приложения тоже не трогает чужой открытый проект
+	svc := NewProjectService()
+	if list, _ := svc.ListRecoverableProjects(); len(list) != 0 {
+		t.Fatalf("live autosave of another instance listed: %+v", list)
+	}
+	if _, err := svc.RecoverProject(untitled.ID); err == nil {
+		t.Fatal("RecoverProject must not take a live autosave")
+	}
+
+	// Приложение упало — ServiceShutdown не вызывался
+	simulateCrash(crashed)
+	list, err = svc.ListRecoverableProjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != untitled.ID || list[0].Name != "Untitled" || list[0].Entries != 1 {
+		t.Fatalf("ListRecoverableProjects = %+v", list)
+	}
+	if _, err := os.Stat(autosaveLockPath(untitled.ID)); !os.IsNotExist(err) {
+		t.Errorf("probing an abandoned autosave must not leave its lock file")
+	}
+
+	recovered, err := svc.RecoverProject(untitled.ID)
+	if err != nil {
+		t.Fatalf("RecoverProject: %v", err)
+	}
+	if recovered.ID == untitled.ID || len(recovered.Entries) != 1 || recovered.Entries[0].DestPath != "/a.txt" {
+		t.Errorf("recovered project = %+v", recovered)
+	}
+	if _, err := os.Stat(autosavePath(untitled.ID)); !os.IsNotExist(err) {
+		t.Errorf("old autosave must be removed after recovery")
+	}
+	// Восстановленные изменения не сохранены и снова попадают в автосохранение
+	svc.autosave()
+	if _, err := os.Stat(autosavePath(recovered.ID)); err != nil {
+		t.Errorf("recovered project not autosaved: %v", err)
+	}
+}
+
+func TestAutosave_CleanCloseLeavesNothing(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+	svc := NewProjectService()
+
+	saved := svc.NewProject("Saved", "SAVED")
+	saved.FilePath = filepath.Join(dir, "saved.xorriso-project")
+	if _, err := svc.UpdateISOOptions(saved, models.ISOOptions{RockRidge: true}); err != nil {
+		t.Fatal(err)
+	}
+	closed := svc.NewProject("Closed", "CLOSED")
+	if _, err := svc.UpdateISOOptions(closed, models.ISOOptions{RockRidge: true}); err != nil {
+		t.Fatal(err)
+	}
+	open := svc.NewProject("Open", "OPEN")
+	if _, err := svc.UpdateISOOptions(open, models.ISOOptions{RockRidge: true}); err != nil {
+		t.Fatal(err)
+	}
+	svc.autosave()
+
+	if err := svc.SaveProject(saved); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(autosavePath(saved.ID)); !os.IsNotExist(err) {
+		t.Errorf("autosave must be removed after SaveProject")
+	}
+	svc.CloseProject(closed.ID)
+	if _, err := os.Stat(autosavePath(closed.ID)); !os.IsNotExist(err) {
+		t.Errorf("autosave must be removed after CloseProject")
+	}
+
+	// Сохранённый проект без новых изменений не перезаписывает автосохранение
+	svc.autosave()
+	if _, err := os.Stat(autosavePath(saved.ID)); !os.IsNotExist(err) {
+		t.Errorf("saved project autosaved again without changes")
+	}
+
+	if err := svc.ServiceShutdown(); err != nil {
+		t.Fatal(err)
+	}
+	list, err := NewProjectService().ListRecoverableProjects()
+	if err != nil || len(list) != 0 {
+		t.Errorf("clean shutdown left recoverable projects: %+v %v", list, err)
+	}
+	if files, _ := os.ReadDir(autosaveDir()); len(files) != 0 {
+		t.Errorf("clean shutdown left files in the autosave dir: %v", files)
+	}
+}
+
+// simulateCrash снимает блокировки автосохранений, как ядро при аварийном завершении процесса
+func simulateCrash(svc *ProjectService) {
+	for _, lock := range svc.autosaveLocks {
+		lock.Close()
+	}
+}
+
+func TestDiscardRecoverableProject(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	crashed := NewProjectService()
+	project := crashed.NewProject("Lost", "LOST")
+	if _, err := crashed.UpdateISOOptions(project, models.ISOOptions{RockRidge: true}); err != nil {
+		t.Fatal(err)
+	}
+	crashed.autosave()
+	simulateCrash(crashed)
+
+	svc := NewProjectService()
+	if err := svc.DiscardRecoverableProject(project.ID); err != nil {
+		t.Fatalf("DiscardRecoverableProject: %v", err)
+	}
+	if err := svc.DiscardRecoverableProject(project.ID); err == nil {
+		t.Error("discarding a missing autosave must fail")
+	}
+	if _, err := svc.RecoverProject("../settings"); err == nil {
+		t.Error("RecoverProject must reject ids outside the autosave dir")
+	}
+}
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"time"
+
+	"xorriso-ui/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+// DuplicateGroup is a set of project files with identical content
+type DuplicateGroup struct {
+	Hash      string   `json:"hash"`
+	Size      int64    `json:"size"`      // размер одной копии
+	DestPaths []string `json:"destPaths"` // первый путь остаётся при удалении дубликатов
+	Sources   []string `json:"sources"`   // различные исходные файлы
+}
+
+// DuplicateReport is the result of a duplicate scan.
+// SavedBytes is how much smaller the image gets if the duplicates are removed or linked.
+type DuplicateReport struct {
+	ScanID     string           `json:"scanId"`
+	Done       bool             `json:"done"`
+	Error      string           `json:"error,omitempty"`
+	Groups     []DuplicateGroup `json:"groups"`
+	SavedBytes int64            `json:"savedBytes"`
+}
+
+// DuplicateScanProgress is emitted while source files are hashed
+type DuplicateScanProgress struct {
+	ScanID      string `json:"scanId"`
+	BytesHashed int64  `json:"bytesHashed"`
+	BytesTotal  int64  `json:"bytesTotal"`
+	FilesHashed int    `json:"filesHashed"`
+	FilesTotal  int    `json:"filesTotal"`
+}
+
+type duplicateScan struct {
+	cancel context.CancelFunc
+	report *DuplicateReport
+}
+
+// StartDuplicateScan hashes the project's source files in the background.
+// Progress is reported with EventDuplicateScanProgress, the report with EventDuplicateScanComplete.
+func (s *ProjectService) StartDuplicateScan(project *models.Project) (string, error) {
+	if project == nil {
+		return "", fmt.Errorf("project is nil")
+	}
+
+	scanID := uuid.New().String()
+	ctx, cancel := context.WithCancel(context.Background())
+	scan := &duplicateScan{cancel: cancel, report: &DuplicateReport{ScanID: scanID, Groups: []DuplicateGroup{}}}
+
+	s.mu.Lock()
+	s.scans[scanID] = scan
+	s.mu.Unlock()
+
+	entries := slices.Clone(project.Entries)
+	go func() {
+		defer cancel()
+		groups, err := findDuplicates(ctx, entries, func(p DuplicateScanProgress) {
+			p.ScanID = scanID
+			s.emitEvent(models.EventDuplicateScanProgress, p)
+		})
+
+		s.mu.Lock()
+		report := &DuplicateReport{ScanID: scanID, Done: true, Groups: groups}
+		if err != nil {
+			report.Error = err.Error()
+			report.Groups = []DuplicateGroup{}
+		}
+		report.SavedBytes = duplicateSavings(report.Groups)
+		scan.report = report
+		s.mu.Unlock()
+
+		s.emitEvent(models.EventDuplicateScanComplete, *report)
+	}()
+
+	return scanID, nil
+}
+
+// GetDuplicateScan returns the current state of a duplicate scan.
+// A finished scan is forgotten once its report has been returned.
+func (s *ProjectService) GetDuplicateScan(scanID string) (*DuplicateReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scan, ok := s.scans[scanID]
+	if !ok {
+		return nil, fmt.Errorf("duplicate scan not found")
+	}
+	report := *scan.report
+	if report.Done {
+		delete(s.scans, scanID)
+	}
+	return &report, nil
+}
+
+// CancelDuplicateScan stops a running scan and forgets its result
+func (s *ProjectService) CancelDuplicateScan(scanID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scan, ok := s.scans[scanID]
+	if !ok {
+		return fmt.Errorf("duplicate scan not found")
+	}
+	scan.cancel()
+	delete(s.scans, scanID)
+	return nil
+}
+
+// RemoveDuplicates keeps the first destination of every group and removes the other copies
+func (s *ProjectService) RemoveDuplicates(project *models.Project, groups []DuplicateGroup) (*models.Project, error) {
+	drop := make(map[string]bool)
+	for _, g := range groups {
+		for _, p := range g.DestPaths[min(1, len(g.DestPaths)):] {
+			drop[p] = true
+		}
+	}
+
+	_ = s.recordEdit(project, "Remove duplicates", func() error {
+		var dropped []models.FileEntry
+		project.Entries = slices.DeleteFunc(project.Entries, func(e models.FileEntry) bool {
+			if !e.IsDir && drop[e.DestPath] {
+				dropped = append(dropped, e)
+				return true
+			}
+			return false
+		})
+		rememberRemoved(project, dropped)
+		return nil
+	})
+	project.UpdatedAt = time.Now()
+	return project, nil
+}
+
+// LinkDuplicates points every copy of a group to the same source file and enables
+// hard links, so the content is stored in the image once while all paths stay.
+func (s *ProjectService) LinkDuplicates(project *models.Project, groups []DuplicateGroup) (*models.Project, error) {
+	canonical := make(map[string]string)
+	for _, g := range groups {
+		for _, src := range g.Sources[min(1, len(g.Sources)):] {
+			canonical[src] = g.Sources[0]
+		}
+	}
+
+	_ = s.recordEdit(project, "Link duplicates", func() error {
+		for i, e := range project.Entries {
+			if src, ok := canonical[e.SourcePath]; ok && !e.IsDir {
+				project.Entries[i].SourcePath = src
+			}
+		}
+		if len(canonical) > 0 {
+			project.ISOOptions.Hardlinks = true
+		}
+		return nil
+	})
+	project.UpdatedAt = time.Now()
+	return project, nil
+}
+
+// findDuplicates группирует файлы по размеру и хэширует только совпадающие по размеру источники
+func findDuplicates(ctx context.Context, entries []models.FileEntry, progress func(DuplicateScanProgress)) ([]DuplicateGroup, error) {
+	destsBySource := make(map[string][]string)
+	sizeBySource := make(map[string]int64)
+	var sources []string
+	for _, e := range entries {
+		if e.IsDir || e.SourcePath == "" || e.Size == 0 {
+			continue
+		}
+		if _, ok := destsBySource[e.SourcePath]; !ok {
+			sources = append(sources, e.SourcePath)
+			sizeBySource[e.SourcePath] = e.Size
+		}
+		destsBySource[e.SourcePath] = append(destsBySource[e.SourcePath], e.DestPath)
+	}
+
+	sourcesBySize := make(map[int64][]string)
+	for _, src := range sources {
+		sourcesBySize[sizeBySource[src]] = append(sourcesBySize[sizeBySource[src]], src)
+	}
+
+	var candidates []string
+	var state DuplicateScanProgress
+	for _, src := range sources {
+		if len(sourcesBySize[sizeBySource[src]]) > 1 {
+			candidates = append(candidates, src)
+			state.BytesTotal += sizeBySource[src]
+		}
+	}
+	state.FilesTotal = len(candidates)
+	progress(state)
+
+	type contentKey struct {
+		size int64
+		sum  string
+	}
+	bySum := make(map[contentKey][]string)
+	var sums []contentKey
+	for _, src := range candidates {
+		sum, err := hashFile(ctx, src)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			// Недоступный файл просто не участвует в поиске
+			continue
+		}
+		key := contentKey{size: sizeBySource[src], sum: sum}
+		if _, ok := bySum[key]; !ok {
+			sums = append(sums, key)
+		}
+		bySum[key] = append(bySum[key], src)
+
+		state.FilesHashed++
+		state.BytesHashed += sizeBySource[src]
+		progress(state)
+	}
+
+	groups := []DuplicateGroup{}
+	for _, key := range sums {
+		srcs := bySum[key]
+		if len(srcs) < 2 {
+			continue
+		}
+		g := DuplicateGroup{Hash: key.sum, Size: key.size, Sources: srcs}
+		for _, src := range srcs {
+			g.DestPaths = append(g.DestPaths, destsBySource[src]...)
+		}
+		groups = append(groups, g)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Size*int64(len(groups[i].Sources)) > groups[j].Size*int64(len(groups[j].Sources))
+	})
+	return groups, nil
+}
+
+// duplicateSavings — сколько байт займут лишние копии содержимого
+func duplicateSavings(groups []DuplicateGroup) int64 {
+	var saved int64
+	for _, g := range groups {
+		saved += g.Size * int64(len(g.Sources)-1)
+	}
+	return saved
+}
+
+func hashFile(ctx context.Context, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, &ctxReader{ctx: ctx, r: f}); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ctxReader прерывает чтение при отмене контекста
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
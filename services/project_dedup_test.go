@@ -0,0 +1,149 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"xorriso-ui/pkg/models"
+	"xorriso-ui/pkg/xorriso"
+)
+
+// newDedupTestProject создаёт проект с двумя одинаковыми фото и одним отличающимся файлом того же размера
+func newDedupTestProject(t *testing.T, svc *ProjectService) *models.Project {
+	t.Helper()
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "2023"), 0755)
+	os.MkdirAll(filepath.Join(dir, "best"), 0755)
+	os.WriteFile(filepath.Join(dir, "2023", "img.jpg"), []byte("same photo"), 0644)
+	os.WriteFile(filepath.Join(dir, "best", "img.jpg"), []byte("same photo"), 0644)
+	os.WriteFile(filepath.Join(dir, "2023", "other.jpg"), []byte("diff photo"), 0644)
+
+	project := svc.NewProject("Photos", "PHOTOS")
+	svc.AddFiles(project, []string{filepath.Join(dir, "2023"), filepath.Join(dir, "best")}, "/")
+	return project
+}
+
+func TestFindDuplicates(t *testing.T) {
+	svc := NewProjectService()
+	project := newDedupTestProject(t, svc)
+
+	var last DuplicateScanProgress
+	groups, err := findDuplicates(t.Context(), project.Entries, func(p DuplicateScanProgress) { last = p })
+	if err != nil {
+		t.Fatalf("findDuplicates: %v", err)
+	}
+	if len(groups) != 1 {
+		t.Fatalf("got %d groups, want 1: %+v", len(groups), groups)
+	}
+	g := groups[0]
+	if g.Size != 10 || len(g.Sources) != 2 || g.DestPaths[0] != "/2023/img.jpg" || g.DestPaths[1] != "/best/img.jpg" {
+		t.Errorf("unexpected group: %+v", g)
+	}
+	if last.FilesHashed != 3 || last.BytesHashed != last.BytesTotal {
+		t.Errorf("unexpected final progress: %+v", last)
+	}
+	if duplicateSavings(groups) != 10 {
+		t.Errorf("savings = %d, want 10", duplicateSavings(groups))
+	}
+}
+
+func TestStartDuplicateScan(t *testing.T) {
+	svc := NewProjectService()
+	svc.emitEvent = noopEmit
+	project := newDedupTestProject(t, svc)
+
+	scanID, err := svc.StartDuplicateScan(project)
+	if err != nil {
+		t.Fatalf("StartDuplicateScan: %v", err)
+	}
+
+	var report *DuplicateReport
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if report, err = svc.GetDuplicateScan(scanID); err != nil {
+			t.Fatal(err)
+		}
+		if report.Done {
+			break
+		}
+	}
+	if !report.Done || len(report.Groups) != 1 || report.SavedBytes != 10 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	if _, err := svc.GetDuplicateScan(scanID); err == nil {
+		t.Error("expected error for a collected scan")
+	}
+	if err := svc.CancelDuplicateScan(scanID); err == nil {
+		t.Error("expected error cancelling a collected scan")
+	}
+
+	scanID, err = svc.StartDuplicateScan(project)
+	if err != nil {
+		t.Fatalf("StartDuplicateScan: %v", err)
+	}
+	if err := svc.CancelDuplicateScan(scanID); err != nil {
+		t.Errorf("CancelDuplicateScan: %v", err)
+	}
+	if _, err := svc.GetDuplicateScan(scanID); err == nil {
+		t.Error("expected error for cancelled scan")
+	}
+	svc.mu.Lock()
+	left := len(svc.scans)
+	svc.mu.Unlock()
+	if left != 0 {
+		t.Errorf("%d scans left in the service", left)
+	}
+}
+
+func TestRemoveDuplicates(t *testing.T) {
+	svc := NewProjectService()
+	project := newDedupTestProject(t, svc)
+	groups, _ := findDuplicates(t.Context(), project.Entries, func(DuplicateScanProgress) {})
+
+	svc.RemoveDuplicates(project, groups)
+	paths := destPaths(project)
+	if _, ok := paths["/best/img.jpg"]; ok {
+		t.Error("duplicate copy should be removed")
+	}
+	if _, ok := paths["/2023/img.jpg"]; !ok {
+		t.Error("first copy should stay")
+	}
+
+	svc.Undo(project)
+	if _, ok := destPaths(project)["/best/img.jpg"]; !ok {
+		t.Error("undo should restore the removed copy")
+	}
+}
+
+func TestLinkDuplicates(t *testing.T) {
+	svc := NewProjectService()
+	project := newDedupTestProject(t, svc)
+	groups, _ := findDuplicates(t.Context(), project.Entries, func(DuplicateScanProgress) {})
+	before, _ := svc.CalculateSize(project)
+
+	svc.LinkDuplicates(project, groups)
+	paths := destPaths(project)
+	if paths["/best/img.jpg"] != paths["/2023/img.jpg"] {
+		t.Errorf("copies should share a source: %q vs %q", paths["/best/img.jpg"], paths["/2023/img.jpg"])
+	}
+	if !project.ISOOptions.Hardlinks {
+		t.Error("hard links should be enabled")
+	}
+	after, _ := svc.CalculateSize(project)
+	if before-after != 10 {
+		t.Errorf("size should shrink by 10 bytes, got %d -> %d", before, after)
+	}
+
+	cmd := xorriso.NewCommand()
+	NewBurnService(&mockRunner{}).buildISOCommand(cmd, project)
+	if !containsSequence(cmd.Build(), "-hardlinks", "on") {
+		t.Errorf("expected -hardlinks on in %v", cmd.Build())
+	}
+
+	svc.Undo(project)
+	if project.ISOOptions.Hardlinks || destPaths(project)["/best/img.jpg"] == destPaths(project)["/2023/img.jpg"] {
+		t.Error("undo should restore sources and options in one step")
+	}
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"xorriso-ui/pkg/models"
+)
+
+// CreateFolder creates an empty virtual folder inside the ISO layout.
+// The folder has no source on disk and is emitted as -mkdir when burning.
+func (s *ProjectService) CreateFolder(project *models.Project, parentDir string, name string) (*models.Project, error) {
+	if err := validateEntryName(name); err != nil {
+		return nil, err
+	}
+	destPath := filepath.Join(isoDir(parentDir), name)
+	if isoPathsInUse(project.Entries, nil)[destPath] {
+		return nil, fmt.Errorf("path already exists in image: %s", destPath)
+	}
+
+	_ = s.recordEdit(project, "New folder "+name, func() error {
+		project.Entries = append(project.Entries, models.FileEntry{
+			DestPath: destPath,
+			Name:     name,
+			IsDir:    true,
+			Listed:   true,
+			ModTime:  time.Now().UnixMilli(),
+		})
+		return nil
+	})
+	project.UpdatedAt = time.Now()
+	return project, nil
+}
+
+// RenameEntry renames a file or folder in the ISO layout.
+// For folders the whole subtree is moved under the new name.
+func (s *ProjectService) RenameEntry(project *models.Project, destPath string, newName string) (*models.Project, error) {
+	if err := validateEntryName(newName); err != nil {
+		return nil, err
+	}
+	// Путь нормализуется так же, как каталог назначения при добавлении
+	destPath = isoDir(destPath)
+	if destPath == "/" {
+		return nil, fmt.Errorf("cannot rename the image root")
+	}
+	newPath := filepath.Join(filepath.Dir(destPath), newName)
+	if newPath == destPath {
+		return project, nil
+	}
+	err := s.recordEdit(project, "Rename "+filepath.Base(destPath), func() error {
+		return relocateSubtrees(project, []string{destPath}, func(string) string { return newPath })
+	})
+	if err != nil {
+		return nil, err
+	}
+	project.UpdatedAt = time.Now()
+	return project, nil
+}
+
+// MoveEntries moves files or folders (with their subtrees) into targetDir in the ISO layout
+func (s *ProjectService) MoveEntries(project *models.Project, destPaths []string, targetDir string) (*models.Project, error) {
+	targetDir = isoDir(targetDir)
+	err := s.recordEdit(project, "Move entries", func() error {
+		return relocateSubtrees(project, destPaths, func(p string) string {
+			return filepath.Join(targetDir, filepath.Base(p))
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	project.UpdatedAt = time.Now()
+	return project, nil
+}
+
+// CopyEntries duplicates files or folders (with their subtrees) into targetDir.
+// The copies keep the original source paths, so the data is read from the same files.
+func (s *ProjectService) CopyEntries(project *models.Project, destPaths []string, targetDir string) (*models.Project, error) {
+	targetDir = isoDir(targetDir)
+	roots, err := subtreeRoots(project.Entries, destPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	inUse := isoPathsInUse(project.Entries, nil)
+	var copies []models.FileEntry
+	for _, root := range roots {
+		newRoot := filepath.Join(targetDir, filepath.Base(root))
+		if isUnderPath(newRoot, root) {
+			return nil, fmt.Errorf("cannot copy %s into itself", root)
+		}
+		for _, e := range project.Entries {
+			if !isUnderPath(e.DestPath, root) {
+				continue
+			}
+			e.DestPath = newRoot + strings.TrimPrefix(e.DestPath, root)
+			if inUse[e.DestPath] {
+				return nil, fmt.Errorf("path already exists in image: %s", e.DestPath)
+			}
+			if e.DestPath == newRoot {
+				e.Name = filepath.Base(newRoot)
+			}
+			inUse[e.DestPath] = true
+			copies = append(copies, e)
+		}
+	}
+
+	_ = s.recordEdit(project, "Copy entries", func() error {
+		project.Entries = append(project.Entries, copies...)
+		return nil
+	})
+	project.UpdatedAt = time.Now()
+	return project, nil
+}
+
+// relocateSubtrees rewrites DestPath of every entry under the given roots.
+// newRootFn maps an old root path to its new location. Source paths are not touched.
+func relocateSubtrees(project *models.Project, destPaths []string, newRootFn func(string) string) error {
+	roots, err := subtreeRoots(project.Entries, destPaths)
+	if err != nil {
+		return err
+	}
+
+	newRoots := make(map[string]string, len(roots))
+	for _, root := range roots {
+		newRoot := newRootFn(root)
+		if isUnderPath(newRoot, root) && newRoot != root {
+			return fmt.Errorf("cannot move %s into itself", root)
+		}
+		newRoots[root] = newRoot
+	}
+
+	rootOf := func(p string) string {
+		for _, root := range roots {
+			if isUnderPath(p, root) {
+				return root
+			}
+		}
+		return ""
+	}
+
+	// Занятые пути считаем без перемещаемых записей, чтобы перемещение
+	// на собственное место или обмен именами внутри выборки не считались коллизией
+	inUse := isoPathsInUse(project.Entries, func(e models.FileEntry) bool {
+		return rootOf(e.DestPath) != ""
+	})
+	updated := make([]models.FileEntry, len(project.Entries))
+	copy(updated, project.Entries)
+	for i, e := range updated {
+		root := rootOf(e.DestPath)
+		if root == "" {
+			continue
+		}
+		newRoot := newRoots[root]
+		e.DestPath = newRoot + strings.TrimPrefix(e.DestPath, root)
+		if inUse[e.DestPath] {
+			return fmt.Errorf("path already exists in image: %s", e.DestPath)
+		}
+		if e.DestPath == newRoot {
+			e.Name = filepath.Base(newRoot)
+		}
+		inUse[e.DestPath] = true
+		updated[i] = e
+	}
+
+	project.Entries = updated
+	return nil
+}
+
+// subtreeRoots validates destPaths and drops paths nested inside other selected paths
+func subtreeRoots(entries []models.FileEntry, destPaths []string) ([]string, error) {
+	if len(destPaths) == 0 {
+		return nil, fmt.Errorf("no entries selected")
+	}
+	existing := isoPathsInUse(entries, nil)
+	var roots []string
+	for _, p := range destPaths {
+		p = filepath.Clean(p)
+		if p == "/" {
+			return nil, fmt.Errorf("cannot modify the image root")
+		}
+		if !existing[p] {
+			return nil, fmt.Errorf("entry not found: %s", p)
+		}
+		nested := false
+		for _, other := range destPaths {
+			other = filepath.Clean(other)
+			if other != p && isUnderPath(p, other) {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			roots = append(roots, p)
+		}
+	}
+	return roots, nil
+}
+
+// isoPathsInUse returns all occupied ISO paths, including implicit parent folders.
+// Entries for which skip returns true are ignored.
+func isoPathsInUse(entries []models.FileEntry, skip func(models.FileEntry) bool) map[string]bool {
+	used := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		if skip != nil && skip(e) {
+			continue
+		}
+		for p := e.DestPath; p != "/" && p != "." && !used[p]; p = filepath.Dir(p) {
+			used[p] = true
+		}
+	}
+	return used
+}
+
+// isUnderPath reports whether p equals root or lies inside it
+func isUnderPath(p, root string) bool {
+	return p == root || strings.HasPrefix(p, root+"/")
+}
+
+// isoDir normalizes a directory path inside the ISO layout
+func isoDir(dir string) string {
+	return filepath.Join("/", dir)
+}
+
+// validateEntryName checks that a name is usable as a single ISO path component
+func validateEntryName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("name cannot be empty")
+	case name == "." || name == "..":
+		return fmt.Errorf("invalid name: %s", name)
+	case strings.Contains(name, "/"):
+		return fmt.Errorf("name cannot contain '/': %s", name)
+	}
+	return nil
+}
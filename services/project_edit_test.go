@@ -0,0 +1,161 @@
+package services
+
+import (
+	"testing"
+
+	"xorriso-ui/pkg/models"
+)
+
+func newEditTestProject() *models.Project {
+	return &models.Project{
+		Entries: []models.FileEntry{
+			{SourcePath: "/src/photos", DestPath: "/photos", Name: "photos", IsDir: true, Listed: true},
+			{SourcePath: "/src/photos/a.jpg", DestPath: "/photos/a.jpg", Name: "a.jpg"},
+			{SourcePath: "/src/photos/2024", DestPath: "/photos/2024", Name: "2024", IsDir: true, Listed: true},
+			{SourcePath: "/src/photos/2024/b.jpg", DestPath: "/photos/2024/b.jpg", Name: "b.jpg"},
+			{SourcePath: "/src/readme.txt", DestPath: "/readme.txt", Name: "readme.txt"},
+		},
+	}
+}
+
+func destPaths(project *models.Project) map[string]string {
+	m := make(map[string]string, len(project.Entries))
+	for _, e := range project.Entries {
+		m[e.DestPath] = e.SourcePath
+	}
+	return m
+}
+
+func TestCreateFolder(t *testing.T) {
+	svc := NewProjectService()
+	project := newEditTestProject()
+
+	if _, err := svc.CreateFolder(project, "/photos", "empty"); err != nil {
+		t.Fatalf("CreateFolder: %v", err)
+	}
+	last := project.Entries[len(project.Entries)-1]
+	if last.DestPath != "/photos/empty" || !last.IsDir || last.SourcePath != "" {
+		t.Errorf("unexpected folder entry: %+v", last)
+	}
+
+	if _, err := svc.CreateFolder(project, "/", "photos"); err == nil {
+		t.Error("expected collision error for existing folder")
+	}
+	if _, err := svc.CreateFolder(project, "/", "a/b"); err == nil {
+		t.Error("expected error for name with slash")
+	}
+}
+
+func TestRenameEntry_Subtree(t *testing.T) {
+	svc := NewProjectService()
+	project := newEditTestProject()
+
+	if _, err := svc.RenameEntry(project, "/photos", "pictures"); err != nil {
+		t.Fatalf("RenameEntry: %v", err)
+	}
+
+	paths := destPaths(project)
+	want := map[string]string{
+		"/pictures":            "/src/photos",
+		"/pictures/a.jpg":      "/src/photos/a.jpg",
+		"/pictures/2024":       "/src/photos/2024",
+		"/pictures/2024/b.jpg": "/src/photos/2024/b.jpg",
+		"/readme.txt":          "/src/readme.txt",
+	}
+	for dest, src := range want {
+		if paths[dest] != src {
+			t.Errorf("entry %s: source = %q, want %q", dest, paths[dest], src)
+		}
+	}
+	if project.Entries[0].Name != "pictures" {
+		t.Errorf("Name = %q, want pictures", project.Entries[0].Name)
+	}
+}
+
+func TestRenameEntry_Collision(t *testing.T) {
+	svc := NewProjectService()
+	project := newEditTestProject()
+
+	if _, err := svc.RenameEntry(project, "/readme.txt", "photos"); err == nil {
+		t.Fatal("expected collision error")
+	}
+	if destPaths(project)["/readme.txt"] != "/src/readme.txt" {
+		t.Error("project must stay unchanged after failed rename")
+	}
+}
+
+func TestRenameEntry_NormalizesPath(t *testing.T) {
+	svc := NewProjectService()
+	project := newEditTestProject()
+
+	if _, err := svc.RenameEntry(project, "/photos/", "pictures"); err != nil {
+		t.Fatalf("RenameEntry: %v", err)
+	}
+	if _, ok := destPaths(project)["/pictures/a.jpg"]; !ok {
+		t.Errorf("trailing slash: entries = %v", destPaths(project))
+	}
+
+	for _, p := range []string{"", "/", "..", "/photos/../.."} {
+		if _, err := svc.RenameEntry(project, p, "root"); err == nil {
+			t.Errorf("RenameEntry(%q): expected error", p)
+		}
+	}
+}
+
+func TestMoveEntries(t *testing.T) {
+	svc := NewProjectService()
+	project := newEditTestProject()
+
+	if _, err := svc.MoveEntries(project, []string{"/photos/2024", "/readme.txt"}, "/archive"); err != nil {
+		t.Fatalf("MoveEntries: %v", err)
+	}
+
+	paths := destPaths(project)
+	for _, p := range []string{"/archive/2024", "/archive/2024/b.jpg", "/archive/readme.txt", "/photos/a.jpg"} {
+		if _, ok := paths[p]; !ok {
+			t.Errorf("expected entry %s after move", p)
+		}
+	}
+	if _, ok := paths["/photos/2024/b.jpg"]; ok {
+		t.Error("old path should be gone after move")
+	}
+}
+
+func TestMoveEntries_IntoItself(t *testing.T) {
+	svc := NewProjectService()
+	project := newEditTestProject()
+
+	if _, err := svc.MoveEntries(project, []string{"/photos"}, "/photos/2024"); err == nil {
+		t.Fatal("expected error when moving folder into itself")
+	}
+}
+
+func TestMoveEntries_NotFound(t *testing.T) {
+	svc := NewProjectService()
+	project := newEditTestProject()
+
+	if _, err := svc.MoveEntries(project, []string{"/missing"}, "/"); err == nil {
+		t.Fatal("expected error for missing entry")
+	}
+}
+
+func TestCopyEntries(t *testing.T) {
+	svc := NewProjectService()
+	project := newEditTestProject()
+
+	if _, err := svc.CopyEntries(project, []string{"/photos/2024"}, "/backup"); err != nil {
+		t.Fatalf("CopyEntries: %v", err)
+	}
+
+	paths := destPaths(project)
+	if paths["/backup/2024/b.jpg"] != "/src/photos/2024/b.jpg" {
+		t.Errorf("copy should keep source path, got %q", paths["/backup/2024/b.jpg"])
+	}
+	if _, ok := paths["/photos/2024/b.jpg"]; !ok {
+		t.Error("original entry must remain after copy")
+	}
+
+	if _, err := svc.CopyEntries(project, []string{"/photos/2024"}, "/backup"); err == nil {
+		t.Error("expected collision error on second copy")
+	}
+}
@@ -0,0 +1,330 @@
+package services
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"xorriso-ui/pkg/models"
+)
+
+const (
+	FillPrioritySize  = "size"  // наилучшее заполнение (рюкзак)
+	FillPriorityAge   = "age"   // сначала самые старые файлы
+	FillPriorityOrder = "order" // в порядке папок проекта
+)
+
+const (
+	// Оценка накладных расходов ISO 9660: системная область, дескрипторы, таблицы путей
+	imageFixedOverhead = 1 << 20
+	// Записи каталогов во всех деревьях (ISO, Joliet, UDF) на один элемент проекта
+	imageEntryOverhead = 512
+	// Число ячеек таблицы в задаче о рюкзаке и предел числа элементов для неё
+	fillKnapsackSlots    = 32768
+	fillKnapsackMaxUnits = 4096
+)
+
+// FillOptions selects the target medium for AutoFill.
+// Capacity overrides MediaType when set.
+type FillOptions struct {
+	MediaType string `json:"mediaType"`
+	Capacity  int64  `json:"capacity"`
+	Priority  string `json:"priority"`
+}
+
+// FillResult is the outcome of AutoFill. Project keeps the files chosen for the
+// disc, Remainder is a new unsaved project with the files that did not fit.
+type FillResult struct {
+	Project        *models.Project `json:"project"`
+	Remainder      *models.Project `json:"remainder"`
+	Capacity       int64           `json:"capacity"`
+	EstimatedBytes int64           `json:"estimatedBytes"` // оценка размера образа выбранных файлов
+	RemainderBytes int64           `json:"remainderBytes"`
+	SplitDirs      []string        `json:"splitDirs"` // каталоги, не поместившиеся на диск целиком
+}
+
+// GetMediaCapacities returns the known blank media for AutoFill
+func (s *ProjectService) GetMediaCapacities() []models.MediaCapacity {
+	return slices.Clone(models.KnownMediaCapacities)
+}
+
+// AutoFill keeps the set of project files that best fills the chosen medium and
+// moves the rest into a "next disc" project. Directories are kept together unless
+// they alone are larger than the disc. The change is recorded in the edit history.
+func (s *ProjectService) AutoFill(project *models.Project, opts FillOptions) (*FillResult, error) {
+	capacity := opts.Capacity
+	if capacity <= 0 {
+		for _, m := range models.KnownMediaCapacities {
+			if m.ID == opts.MediaType {
+				capacity = m.Capacity
+			}
+		}
+	}
+	if capacity <= 0 {
+		return nil, fmt.Errorf("unknown media type: %s", opts.MediaType)
+	}
+	budget := capacity - imageFixedOverhead
+	if budget <= 0 {
+		return nil, fmt.Errorf("capacity too small: %d bytes", capacity)
+	}
+
+	units, splitDirs := fillUnits(project.Entries, project.ISOOptions, budget)
+	var chosen []int
+	switch opts.Priority {
+	case FillPrioritySize, "":
+		chosen = fillKnapsack(units, budget)
+	case FillPriorityAge:
+		slices.SortStableFunc(units, func(a, b fillUnit) int { return cmp.Compare(a.oldest, b.oldest) })
+		chosen = fillGreedy(units, budget)
+	case FillPriorityOrder:
+		chosen = fillGreedy(units, budget)
+	default:
+		return nil, fmt.Errorf("unknown fill priority: %s", opts.Priority)
+	}
+
+	keep := make(map[string]bool)
+	for _, i := range chosen {
+		for _, p := range units[i].paths {
+			keep[p] = true
+		}
+	}
+	// Разделённые каталоги остаются в том проекте, куда попало их содержимое
+	rest := complement(project.Entries, keep, splitDirs)
+	var keepEntries, restEntries []models.FileEntry
+	for _, e := range project.Entries {
+		if isSplitDir(e.DestPath, splitDirs) {
+			if hasPathUnder(keep, e.DestPath) {
+				keepEntries = append(keepEntries, e)
+			}
+			if hasPathUnder(rest, e.DestPath) {
+				restEntries = append(restEntries, e)
+			}
+			continue
+		}
+		if keep[e.DestPath] {
+			keepEntries = append(keepEntries, e)
+		} else {
+			restEntries = append(restEntries, e)
+		}
+	}
+
+	remainder := s.NewProject(project.Name+" (next disc)", project.VolumeID)
+	remainder.ISOOptions = project.ISOOptions
+	remainder.BurnOptions = project.BurnOptions
+	remainder.Entries = append([]models.FileEntry{}, restEntries...)
+	// Части разделённых папок не должны вернуться при обновлении источников
+	remainder.Removed = slices.Clone(project.Removed)
+	rememberRemoved(remainder, keepEntries)
+
+	_ = s.recordEdit(project, "Auto-fill disc", func() error {
+		project.Entries = append([]models.FileEntry{}, keepEntries...)
+		rememberRemoved(project, restEntries)
+		return nil
+	})
+	project.UpdatedAt = time.Now()
+
+	return &FillResult{
+		Project:        project,
+		Remainder:      remainder,
+		Capacity:       capacity,
+		EstimatedBytes: estimateImageSize(project.Entries, project.ISOOptions),
+		RemainderBytes: estimateImageSize(remainder.Entries, remainder.ISOOptions),
+		SplitDirs:      splitDirs,
+	}, nil
+}
+
+// estimateImageSize оценивает размер образа: данные с округлением до блока и записи каталогов
+func estimateImageSize(entries []models.FileEntry, opts models.ISOOptions) int64 {
+	return imageFixedOverhead + entriesImageSize(entries, opts)
+}
+
+func entriesImageSize(entries []models.FileEntry, opts models.ISOOptions) int64 {
+	var total int64
+	counted := make(map[string]bool)
+	for _, e := range entries {
+		total += imageEntryOverhead
+		if e.IsDir && e.Listed || e.Type != "" {
+			continue
+		}
+		// Каждая часть разделённого файла и каждый экстент — отдельная запись каталога
+		pieces, pieceSize := filePieces(e, opts)
+		total += int64(pieces-1) * imageEntryOverhead
+		if pieces > 1 && largeFileHandling(opts) == LargeFileSplit {
+			total += imageEntryOverhead // каталог, в котором лежат части
+		}
+		// Данные одного источника записываются в образ один раз, как в entriesDataSize
+		if e.SourcePath != "" {
+			if counted[e.SourcePath] {
+				continue
+			}
+			counted[e.SourcePath] = true
+		}
+		total += int64(pieces-1) * roundToBlock(pieceSize)
+		total += roundToBlock(e.Size - int64(pieces-1)*pieceSize)
+	}
+	return total
+}
+
+func roundToBlock(size int64) int64 {
+	return (size + models.BlockSizeBytes - 1) / models.BlockSizeBytes * models.BlockSizeBytes
+}
+
+// fillUnit — поддерево, которое помещается на диск только целиком
+type fillUnit struct {
+	paths  []string
+	size   int64
+	oldest int64
+}
+
+// fillUnits делит проект на поддеревья верхнего уровня. Поддерево больше budget
+// делится на дочерние, а сам каталог попадает в splitDirs.
+func fillUnits(entries []models.FileEntry, opts models.ISOOptions, budget int64) ([]fillUnit, []string) {
+	children, _ := isoTree(entries)
+
+	// Записи поддерева в порядке проекта
+	subtree := func(root string) []models.FileEntry {
+		var out []models.FileEntry
+		for _, e := range entries {
+			if isUnderPath(e.DestPath, root) {
+				out = append(out, e)
+			}
+		}
+		return out
+	}
+
+	var units []fillUnit
+	var splitDirs []string
+	var visit func(root string)
+	visit = func(root string) {
+		sub := subtree(root)
+		size := entriesImageSize(sub, opts)
+		if size > budget && len(children[root]) > 0 {
+			splitDirs = append(splitDirs, root)
+			for _, child := range firstAppearance(entries, children[root]) {
+				visit(child)
+			}
+			return
+		}
+		u := fillUnit{size: size, oldest: -1}
+		for _, e := range sub {
+			u.paths = append(u.paths, e.DestPath)
+			if !e.IsDir && (u.oldest < 0 || e.ModTime < u.oldest) {
+				u.oldest = e.ModTime
+			}
+		}
+		if len(u.paths) > 0 {
+			units = append(units, u)
+		}
+	}
+	for _, top := range firstAppearance(entries, children["/"]) {
+		visit(top)
+	}
+	return units, splitDirs
+}
+
+// firstAppearance упорядочивает пути по первому появлению их поддерева в проекте
+func firstAppearance(entries []models.FileEntry, paths []string) []string {
+	pos := make(map[string]int, len(paths))
+	for i := len(entries) - 1; i >= 0; i-- {
+		for _, root := range paths {
+			if isUnderPath(entries[i].DestPath, root) {
+				pos[root] = i
+			}
+		}
+	}
+	ordered := slices.Clone(paths)
+	slices.SortStableFunc(ordered, func(a, b string) int { return cmp.Compare(pos[a], pos[b]) })
+	return ordered
+}
+
+// fillGreedy берёт элементы по порядку, пропуская не поместившиеся
+func fillGreedy(units []fillUnit, budget int64) []int {
+	var chosen []int
+	var used int64
+	for i, u := range units {
+		if used+u.size <= budget {
+			used += u.size
+			chosen = append(chosen, i)
+		}
+	}
+	return chosen
+}
+
+// fillKnapsack выбирает элементы с максимальным суммарным размером не больше budget.
+// Размеры округляются вверх до ячейки таблицы, поэтому выбор всегда помещается.
+func fillKnapsack(units []fillUnit, budget int64) []int {
+	if len(units) > fillKnapsackMaxUnits {
+		order := make([]int, len(units))
+		for i := range order {
+			order[i] = i
+		}
+		slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(units[b].size, units[a].size) })
+		sorted := make([]fillUnit, len(units))
+		for i, idx := range order {
+			sorted[i] = units[idx]
+		}
+		var chosen []int
+		for _, i := range fillGreedy(sorted, budget) {
+			chosen = append(chosen, order[i])
+		}
+		slices.Sort(chosen)
+		return chosen
+	}
+
+	quantum := max((budget+fillKnapsackSlots-1)/fillKnapsackSlots, 1)
+	slots := int(budget / quantum)
+	// reached[w] — элемент, которым впервые достигнута сумма w (-1 — недостижима)
+	reached := make([]int, slots+1)
+	for i := range reached {
+		reached[i] = -1
+	}
+	weight := make([]int, len(units))
+	for i, u := range units {
+		weight[i] = int((u.size + quantum - 1) / quantum)
+		if weight[i] > slots {
+			continue
+		}
+		for w := slots; w >= weight[i]; w-- {
+			if reached[w] < 0 && (w == weight[i] || reached[w-weight[i]] >= 0) {
+				reached[w] = i
+			}
+		}
+	}
+
+	best := slots
+	for best > 0 && reached[best] < 0 {
+		best--
+	}
+	var chosen []int
+	for w := best; w > 0; w -= weight[reached[w]] {
+		chosen = append(chosen, reached[w])
+	}
+	slices.Sort(chosen)
+	return chosen
+}
+
+func isSplitDir(p string, splitDirs []string) bool {
+	return slices.Contains(splitDirs, p)
+}
+
+// hasPathUnder сообщает, есть ли в множестве путь внутри каталога root (не сам root)
+func hasPathUnder(set map[string]bool, root string) bool {
+	for p := range set {
+		if p != root && isUnderPath(p, root) {
+			return true
+		}
+	}
+	return false
+}
+
+// complement возвращает пути записей, не вошедших в keep, без разделённых каталогов
+func complement(entries []models.FileEntry, keep map[string]bool, splitDirs []string) map[string]bool {
+	rest := make(map[string]bool)
+	for _, e := range entries {
+		if !keep[e.DestPath] && !isSplitDir(e.DestPath, splitDirs) {
+			rest[e.DestPath] = true
+		}
+	}
+	return rest
+}
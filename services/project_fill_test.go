@@ -0,0 +1,111 @@
+package services
+
+import (
+	"slices"
+	"testing"
+
+	"xorriso-ui/pkg/models"
+)
+
+func newFillTestProject(svc *ProjectService) *models.Project {
+	project := svc.NewProject("Archive", "ARCHIVE")
+	project.Entries = []models.FileEntry{
+		{SourcePath: "/src/a", DestPath: "/a", IsDir: true, Listed: true, Size: 61440},
+		{SourcePath: "/src/a/x", DestPath: "/a/x", Size: 40960, ModTime: 300},
+		{SourcePath: "/src/a/y", DestPath: "/a/y", Size: 20480, ModTime: 400},
+		{SourcePath: "/src/b", DestPath: "/b", Size: 51200, ModTime: 100},
+		{SourcePath: "/src/c", DestPath: "/c", Size: 46080, ModTime: 200},
+		{SourcePath: "/src/d", DestPath: "/d", Size: 10240, ModTime: 500},
+	}
+	return project
+}
+
+func sortedDestPaths(entries []models.FileEntry) []string {
+	var paths []string
+	for _, e := range entries {
+		paths = append(paths, e.DestPath)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+func TestAutoFill_Priorities(t *testing.T) {
+	tests := []struct {
+		priority string
+		keep     []string
+	}{
+		{FillPrioritySize, []string{"/b", "/c"}},
+		{FillPriorityOrder, []string{"/a", "/a/x", "/a/y", "/d"}},
+		{FillPriorityAge, []string{"/b", "/c"}},
+	}
+	for _, tt := range tests {
+		svc := NewProjectService()
+		project := newFillTestProject(svc)
+		result, err := svc.AutoFill(project, FillOptions{Capacity: imageFixedOverhead + 100*1024, Priority: tt.priority})
+		if err != nil {
+			t.Fatalf("%s: AutoFill: %v", tt.priority, err)
+		}
+
+		if got := sortedDestPaths(project.Entries); !slices.Equal(got, tt.keep) {
+			t.Errorf("%s: kept %v, want %v", tt.priority, got, tt.keep)
+		}
+		if len(result.Remainder.Entries)+len(project.Entries) != 6 {
+			t.Errorf("%s: entries lost: %d kept, %d remainder", tt.priority, len(project.Entries), len(result.Remainder.Entries))
+		}
+		if result.EstimatedBytes > result.Capacity {
+			t.Errorf("%s: estimate %d exceeds capacity %d", tt.priority, result.EstimatedBytes, result.Capacity)
+		}
+	}
+}
+
+func TestAutoFill_SplitsOversizedDir(t *testing.T) {
+	svc := NewProjectService()
+	project := newFillTestProject(svc)
+
+	result, err := svc.AutoFill(project, FillOptions{Capacity: imageFixedOverhead + 60000})
+	if err != nil {
+		t.Fatalf("AutoFill: %v", err)
+	}
+	if !slices.Equal(result.SplitDirs, []string{"/a"}) {
+		t.Errorf("SplitDirs = %v, want [/a]", result.SplitDirs)
+	}
+	if got := sortedDestPaths(project.Entries); !slices.Equal(got, []string{"/c", "/d"}) {
+		t.Errorf("kept %v, want [/c /d]", got)
+	}
+	if got := sortedDestPaths(result.Remainder.Entries); !slices.Equal(got, []string{"/a", "/a/x", "/a/y", "/b"}) {
+		t.Errorf("remainder %v", got)
+	}
+
+	svc.Undo(project)
+	if len(project.Entries) != 6 {
+		t.Errorf("undo should restore all entries, got %d", len(project.Entries))
+	}
+}
+
+func TestAutoFill_MediaType(t *testing.T) {
+	svc := NewProjectService()
+	project := newFillTestProject(svc)
+
+	result, err := svc.AutoFill(project, FillOptions{MediaType: "cd-80"})
+	if err != nil {
+		t.Fatalf("AutoFill: %v", err)
+	}
+	if result.Capacity != 360000*models.BlockSizeBytes || len(result.Remainder.Entries) != 0 {
+		t.Errorf("everything should fit on a CD: capacity=%d remainder=%d", result.Capacity, len(result.Remainder.Entries))
+	}
+
+	if _, err := svc.AutoFill(project, FillOptions{MediaType: "floppy"}); err == nil {
+		t.Error("expected error for unknown media type")
+	}
+}
+
+func TestEstimateImageSize_CountsSourceOnce(t *testing.T) {
+	single := []models.FileEntry{{SourcePath: "/src/big", DestPath: "/big", Size: 1 << 20}}
+	double := append(single, models.FileEntry{SourcePath: "/src/big", DestPath: "/copy/big", Size: 1 << 20})
+
+	got := estimateImageSize(double, models.ISOOptions{})
+	want := estimateImageSize(single, models.ISOOptions{}) + imageEntryOverhead
+	if got != want {
+		t.Errorf("estimate = %d, want %d: the shared source must be counted once", got, want)
+	}
+}
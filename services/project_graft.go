@@ -0,0 +1,208 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"xorriso-ui/pkg/models"
+	"xorriso-ui/pkg/xorriso"
+)
+
+const ImportFormatGraftPoints = "graft-points"
+
+// GraftExport is the result of exporting a project as an mkisofs path list
+type GraftExport struct {
+	ListPath string   `json:"listPath"`
+	Command  string   `json:"command"`
+	Warnings []string `json:"warnings"`
+}
+
+// ImportGraftList adds entries from an mkisofs -path-list file to the project.
+// Lines in graft-point form (/dest=/src) place the source at dest; plain paths
+// follow mkisofs rules: a file goes to the root, a directory's contents are merged into the root.
+func (s *ProjectService) ImportGraftList(project *models.Project, listPath string) (*ImportResult, error) {
+	f, err := os.Open(listPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	result := &ImportResult{Project: project, Format: ImportFormatGraftPoints}
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimRight(scanner.Text(), "\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	err = s.recordEdit(project, "Import path list", func() error {
+		for _, line := range lines {
+			importGraftLine(project, line, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func importGraftLine(project *models.Project, line string, result *ImportResult) {
+	dest, src, isGraft := xorriso.SplitGraftPoint(line)
+
+	info, err := os.Stat(src)
+	if err != nil {
+		result.warnf("source not found, skipped: %s", src)
+		return
+	}
+
+	switch {
+	case isGraft:
+		dirTarget := strings.HasSuffix(dest, "/")
+		dest = filepath.Join("/", dest)
+		if dirTarget && dest != "/" {
+			// "/dir/=/src/file" — целевой путь задаёт каталог
+			dest = filepath.Join(dest, filepath.Base(src))
+		}
+		if dest == "/" && info.IsDir() {
+			addDirContents(project, src, "/", result)
+			return
+		}
+		skipped, _ := addSourceAt(project, src, dest)
+		result.warnSkipped(skipped)
+	case info.IsDir():
+		addDirContents(project, src, "/", result)
+	default:
+		skipped, _ := addSourceAt(project, src, filepath.Join("/", filepath.Base(src)))
+		result.warnSkipped(skipped)
+	}
+}
+
+// addDirContents adds every item of a source directory directly under destDir
+func addDirContents(project *models.Project, src, destDir string, result *ImportResult) {
+	items, err := os.ReadDir(src)
+	if err != nil {
+		result.warnf("cannot read %s: %v", src, err)
+		return
+	}
+	for _, item := range items {
+		skipped, _ := addSourceAt(project, filepath.Join(src, item.Name()), filepath.Join(destDir, item.Name()))
+		result.warnSkipped(skipped)
+	}
+}
+
+// ExportGraftList writes the project as an mkisofs graft-point path list and returns
+// the matching "xorriso -as mkisofs" command line that builds outputPath from it.
+func (s *ProjectService) ExportGraftList(project *models.Project, listPath string, outputPath string) (*GraftExport, error) {
+	if len(project.Entries) == 0 {
+		return nil, fmt.Errorf("project has no entries")
+	}
+	if err := validateVolumeDescriptor(project.VolumeID, project.ISOOptions); err != nil {
+		return nil, err
+	}
+
+	export := &GraftExport{ListPath: listPath}
+	var b strings.Builder
+	parents := parentDirs(project.Entries)
+	for _, e := range project.Entries {
+		if e.Meta != (models.EntryMetadata{}) {
+			export.Warnings = append(export.Warnings,
+				fmt.Sprintf("attribute overrides of %s cannot be expressed in a path list and were omitted", e.DestPath))
+		}
+		switch {
+		case e.ImagePath != "" && !e.IsDir:
+			export.Warnings = append(export.Warnings,
+				fmt.Sprintf("%s comes from the base image and has no source on disk", e.DestPath))
+			continue
+		case e.SourcePath == "" || e.IsDir && e.Listed:
+			// Содержимое перечислено отдельными строками, каталог появится сам.
+			// Строка с источником перенесла бы всё его содержимое.
+			if !parents[e.DestPath] {
+				export.Warnings = append(export.Warnings,
+					fmt.Sprintf("empty folder %s cannot be expressed as a graft point", e.DestPath))
+			}
+			continue
+		case strings.ContainsAny(e.DestPath+e.SourcePath, "\n\r"):
+			export.Warnings = append(export.Warnings,
+				fmt.Sprintf("path with a line break cannot be written to a path list: %s", e.DestPath))
+			continue
+		}
+		b.WriteString(xorriso.EscapeGraftPath(e.DestPath))
+		b.WriteByte('=')
+		b.WriteString(xorriso.EscapeGraftPath(e.SourcePath))
+		b.WriteByte('\n')
+	}
+
+	if err := os.WriteFile(listPath, []byte(b.String()), 0644); err != nil {
+		return nil, err
+	}
+
+	args, warnings := mkisofsArgs(project)
+	export.Warnings = append(export.Warnings, warnings...)
+	args = append(args, "-graft-points", "-path-list", listPath, "-o", outputPath)
+	export.Command = "xorriso -as mkisofs " + xorriso.ShellJoin(args)
+	return export, nil
+}
+
+// parentDirs возвращает множество destPath каталогов, у которых в проекте
+// есть собственные дочерние записи
+func parentDirs(entries []models.FileEntry) map[string]bool {
+	dirs := make(map[string]bool)
+	for _, e := range entries {
+		dirs[filepath.Dir(e.DestPath)] = true
+	}
+	return dirs
+}
+
+// mkisofsArgs translates the project's ISO options into mkisofs emulation options
+func mkisofsArgs(project *models.Project) ([]string, []string) {
+	opts := project.ISOOptions
+	var args, warnings []string
+
+	if project.VolumeID != "" {
+		args = append(args, "-V", project.VolumeID)
+	}
+	volumeArgs, volumeWarnings := mkisofsVolumeArgs(opts)
+	args = append(args, volumeArgs...)
+	warnings = append(warnings, volumeWarnings...)
+	args = append(args, "-iso-level", strconv.Itoa(isoLevel(opts)))
+	if opts.RockRidge {
+		args = append(args, "-R")
+	}
+	if opts.Joliet {
+		args = append(args, "-J")
+	}
+	if opts.UDF {
+		args = append(args, "-udf")
+	}
+	if opts.HFSPlus {
+		args = append(args, "-hfsplus")
+	}
+	if opts.MD5 {
+		args = append(args, "--md5")
+	}
+	if opts.BackupMode {
+		args = append(args, "--for_backup")
+	}
+	if opts.Hardlinks {
+		args = append(args, "--hardlinks")
+	}
+	if opts.LinkPolicy == models.LinkPolicyFollow {
+		args = append(args, "-follow-links")
+	}
+	if opts.Zisofs {
+		warnings = append(warnings, "zisofs compression has no mkisofs equivalent and was omitted")
+	}
+	if len(layoutWeights(project)) > 0 {
+		warnings = append(warnings, "file layout sort weights need an mkisofs -sort file and were omitted")
+	}
+	return args, warnings
+}
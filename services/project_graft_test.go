@@ -0,0 +1,144 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"xorriso-ui/pkg/models"
+	"xorriso-ui/pkg/xorriso"
+)
+
+func TestImportGraftList(t *testing.T) {
+	dir := t.TempDir()
+	docs := filepath.Join(dir, "docs")
+	os.MkdirAll(docs, 0755)
+	os.WriteFile(filepath.Join(docs, "a.txt"), []byte("a"), 0644)
+	file := filepath.Join(dir, "notes.txt")
+	os.WriteFile(file, []byte("n"), 0644)
+	rootDir := filepath.Join(dir, "root")
+	os.MkdirAll(rootDir, 0755)
+	os.WriteFile(filepath.Join(rootDir, "top.txt"), []byte("t"), 0644)
+
+	list := strings.Join([]string{
+		"/documents=" + docs,
+		"/misc/=" + file,
+		file,
+		rootDir,
+		"/missing=/nonexistent/file",
+	}, "\n")
+	listPath := filepath.Join(dir, "list.txt")
+	os.WriteFile(listPath, []byte(list+"\n"), 0644)
+
+	svc := NewProjectService()
+	project := svc.NewProject("Test", "TEST")
+	result, err := svc.ImportGraftList(project, listPath)
+	if err != nil {
+		t.Fatalf("ImportGraftList: %v", err)
+	}
+
+	paths := destPaths(project)
+	want := map[string]string{
+		"/documents":       docs,
+		"/documents/a.txt": filepath.Join(docs, "a.txt"),
+		"/misc/notes.txt":  file,
+		"/notes.txt":       file,
+		"/top.txt":         filepath.Join(rootDir, "top.txt"),
+	}
+	for dest, src := range want {
+		if paths[dest] != src {
+			t.Errorf("entry %s: source = %q, want %q", dest, paths[dest], src)
+		}
+	}
+	if !hasWarning(result.Warnings, "/nonexistent/file") {
+		t.Errorf("expected warning for missing source, got %v", result.Warnings)
+	}
+}
+
+func TestImportGraftList_EscapedPlainPath(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, `a=b\c.txt`)
+	os.WriteFile(file, []byte("a"), 0644)
+
+	listPath := filepath.Join(dir, "list.txt")
+	os.WriteFile(listPath, []byte(xorriso.EscapeGraftPath(file)+"\n"), 0644)
+
+	svc := NewProjectService()
+	project := svc.NewProject("Test", "TEST")
+	result, err := svc.ImportGraftList(project, listPath)
+	if err != nil {
+		t.Fatalf("ImportGraftList: %v", err)
+	}
+	if src := destPaths(project)[`/a=b\c.txt`]; src != file || len(result.Warnings) != 0 {
+		t.Errorf("source = %q, want %q; warnings %v", src, file, result.Warnings)
+	}
+}
+
+func TestExportGraftList(t *testing.T) {
+	dir := t.TempDir()
+	project := &models.Project{
+		VolumeID: "My Disc",
+		Entries: []models.FileEntry{
+			{SourcePath: "/src/docs", DestPath: "/docs", IsDir: true, Listed: true},
+			{SourcePath: "/src/docs/a=b.txt", DestPath: "/docs/a=b.txt"},
+			{SourcePath: "/src/photos", DestPath: "/photos", IsDir: true},
+			{DestPath: "/empty", IsDir: true},
+		},
+		ISOOptions: models.ISOOptions{RockRidge: true, Joliet: true, ISOLevel: 3, MD5: true},
+	}
+
+	svc := NewProjectService()
+	listPath := filepath.Join(dir, "graft list.txt")
+	export, err := svc.ExportGraftList(project, listPath, "/tmp/out.iso")
+	if err != nil {
+		t.Fatalf("ExportGraftList: %v", err)
+	}
+
+	data, _ := os.ReadFile(listPath)
+	wantList := "/docs/a\\=b.txt=/src/docs/a\\=b.txt\n/photos=/src/photos\n"
+	if string(data) != wantList {
+		t.Errorf("list =\n%s\nwant:\n%s", data, wantList)
+	}
+
+	for _, exp := range []string{
+		"xorriso -as mkisofs ",
+		"-V 'My Disc'",
+		"-iso-level 3",
+		" -R ",
+		" -J ",
+		"--md5",
+		"-graft-points -path-list '" + listPath + "'",
+		"-o /tmp/out.iso",
+	} {
+		if !strings.Contains(export.Command, exp) {
+			t.Errorf("expected %q in command: %s", exp, export.Command)
+		}
+	}
+	if !hasWarning(export.Warnings, "/empty") {
+		t.Errorf("expected warning for sourceless folder, got %v", export.Warnings)
+	}
+}
+
+func TestGraftList_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "with = sign.txt")
+	os.WriteFile(src, []byte("x"), 0644)
+
+	svc := NewProjectService()
+	project := svc.NewProject("Test", "TEST")
+	project.Entries = []models.FileEntry{{SourcePath: src, DestPath: "/renamed = too.txt"}}
+
+	listPath := filepath.Join(dir, "list")
+	if _, err := svc.ExportGraftList(project, listPath, "out.iso"); err != nil {
+		t.Fatal(err)
+	}
+
+	imported := svc.NewProject("Imported", "")
+	if _, err := svc.ImportGraftList(imported, listPath); err != nil {
+		t.Fatal(err)
+	}
+	if destPaths(imported)["/renamed = too.txt"] != src {
+		t.Errorf("round trip failed: %+v", imported.Entries)
+	}
+}
@@ -0,0 +1,400 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"xorriso-ui/pkg/models"
+)
+
+const (
+	// historyMaxSteps — максимальное число шагов истории на один проект
+	historyMaxSteps = 100
+	// historyMaxCost — суммарный лимит записей FileEntry, хранимых в истории одного проекта
+	historyMaxCost = 200_000
+)
+
+// HistoryItem describes a single undoable step for display in the UI
+type HistoryItem struct {
+	Label string    `json:"label"`
+	Time  time.Time `json:"time"`
+}
+
+// HistoryInfo is the edit history of a project.
+// Items before Position can be undone, items from Position onward can be redone.
+type HistoryInfo struct {
+	Items    []HistoryItem `json:"items"`
+	Position int           `json:"position"`
+	CanUndo  bool          `json:"canUndo"`
+	CanRedo  bool          `json:"canRedo"`
+}
+
+// editCommand is a reversible change of a project
+type editCommand interface {
+	apply(p *models.Project) error
+	revert(p *models.Project) error
+	cost() int
+}
+
+// spliceCommand replaces a contiguous range of entries.
+// Covers adds, removals and in-place path rewrites.
+type spliceCommand struct {
+	start  int
+	before []models.FileEntry
+	after  []models.FileEntry
+}
+
+func (c *spliceCommand) apply(p *models.Project) error {
+	return spliceEntries(p, c.start, c.before, c.after)
+}
+
+func (c *spliceCommand) revert(p *models.Project) error {
+	return spliceEntries(p, c.start, c.after, c.before)
+}
+
+func (c *spliceCommand) cost() int {
+	return len(c.before) + len(c.after) + 1
+}
+
+// spliceEntries replaces entries[start:start+len(old)] with repl
+func spliceEntries(p *models.Project, start int, old, repl []models.FileEntry) error {
+	end := start + len(old)
+	if end > len(p.Entries) || !slices.Equal(p.Entries[start:end], old) {
+		return fmt.Errorf("project entries do not match edit history")
+	}
+	entries := make([]models.FileEntry, 0, len(p.Entries)-len(old)+len(repl))
+	entries = append(entries, p.Entries[:start]...)
+	entries = append(entries, repl...)
+	entries = append(entries, p.Entries[end:]...)
+	p.Entries = entries
+	return nil
+}
+
+// optionsCommand switches project-level settings between two states
+type optionsCommand struct {
+	before projectOptions
+	after  projectOptions
+}
+
+// projectOptions — настройки проекта, которые отслеживаются историей
+type projectOptions struct {
+	VolumeID    string
+	ISOOptions  models.ISOOptions
+	BurnOptions models.BurnOptions
+	SortRules   []models.SortRule
+	Exclude     []string
+	Removed     []string
+}
+
+func captureOptions(p *models.Project) projectOptions {
+	return projectOptions{
+		VolumeID:    p.VolumeID,
+		ISOOptions:  p.ISOOptions,
+		BurnOptions: p.BurnOptions,
+		SortRules:   slices.Clone(p.SortRules),
+		Exclude:     slices.Clone(p.Exclude),
+		Removed:     slices.Clone(p.Removed),
+	}
+}
+
+func (o projectOptions) restore(p *models.Project) {
+	p.VolumeID = o.VolumeID
+	p.ISOOptions = o.ISOOptions
+	p.BurnOptions = o.BurnOptions
+	p.SortRules = slices.Clone(o.SortRules)
+	p.Exclude = slices.Clone(o.Exclude)
+	p.Removed = slices.Clone(o.Removed)
+}
+
+func (o projectOptions) equal(other projectOptions) bool {
+	return o.VolumeID == other.VolumeID && o.ISOOptions == other.ISOOptions &&
+		o.BurnOptions == other.BurnOptions && slices.Equal(o.SortRules, other.SortRules) &&
+		slices.Equal(o.Exclude, other.Exclude) && slices.Equal(o.Removed, other.Removed)
+}
+
+func (c *optionsCommand) apply(p *models.Project) error {
+	c.after.restore(p)
+	return nil
+}
+
+func (c *optionsCommand) revert(p *models.Project) error {
+	c.before.restore(p)
+	return nil
+}
+
+func (c *optionsCommand) cost() int { return 1 }
+
+// groupCommand combines several commands into one undo step
+type groupCommand []editCommand
+
+func (g groupCommand) apply(p *models.Project) error {
+	for _, c := range g {
+		if err := c.apply(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g groupCommand) revert(p *models.Project) error {
+	for i := len(g) - 1; i >= 0; i-- {
+		if err := g[i].revert(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g groupCommand) cost() int {
+	total := 0
+	for _, c := range g {
+		total += c.cost()
+	}
+	return total
+}
+
+type historyStep struct {
+	item HistoryItem
+	cmd  editCommand
+}
+
+// editHistory хранит историю изменений одного открытого проекта
+type editHistory struct {
+	steps    []historyStep
+	position int
+	cost     int
+	// Открытая группа: команды накапливаются и попадают в историю одним шагом
+	groupLabel string
+	group      groupCommand
+	grouping   bool
+}
+
+func (h *editHistory) push(label string, cmd editCommand) {
+	if h.grouping {
+		h.group = append(h.group, cmd)
+		return
+	}
+
+	// Новое изменение отменяет возможность redo
+	for _, st := range h.steps[h.position:] {
+		h.cost -= st.cmd.cost()
+	}
+	h.steps = append(h.steps[:h.position], historyStep{
+		item: HistoryItem{Label: label, Time: time.Now()},
+		cmd:  cmd,
+	})
+	h.cost += cmd.cost()
+	h.position = len(h.steps)
+
+	// Вытесняем самые старые шаги при превышении лимитов, последний шаг сохраняем всегда
+	for len(h.steps) > 1 && (len(h.steps) > historyMaxSteps || h.cost > historyMaxCost) {
+		h.cost -= h.steps[0].cmd.cost()
+		h.steps = h.steps[1:]
+		h.position--
+	}
+}
+
+func (h *editHistory) info() HistoryInfo {
+	items := make([]HistoryItem, len(h.steps))
+	for i, st := range h.steps {
+		items[i] = st.item
+	}
+	return HistoryInfo{
+		Items:    items,
+		Position: h.position,
+		CanUndo:  h.position > 0,
+		CanRedo:  h.position < len(h.steps),
+	}
+}
+
+// historyFor returns the edit history of a project, creating it on first use
+func (s *ProjectService) historyFor(projectID string) *editHistory {
+	h, ok := s.histories[projectID]
+	if !ok {
+		h = &editHistory{}
+		s.histories[projectID] = h
+	}
+	return h
+}
+
+// recordEdit runs an entry or option mutation and stores its reverse in the project history.
+// Nothing is recorded if the mutation fails or leaves the project unchanged.
+func (s *ProjectService) recordEdit(project *models.Project, label string, mutate func() error) error {
+	beforeEntries := slices.Clone(project.Entries)
+	beforeOpts := captureOptions(project)
+
+	if err := mutate(); err != nil {
+		return err
+	}
+
+	var cmds groupCommand
+	if cmd := diffEntries(beforeEntries, project.Entries); cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+	if afterOpts := captureOptions(project); !afterOpts.equal(beforeOpts) {
+		cmds = append(cmds, &optionsCommand{before: beforeOpts, after: afterOpts})
+	}
+	if len(cmds) == 0 || project.ID == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(cmds) == 1 {
+		s.historyFor(project.ID).push(label, cmds[0])
+	} else {
+		s.historyFor(project.ID).push(label, cmds)
+	}
+	s.trackLocked(project)
+	return nil
+}
+
+// diffEntries returns a splice command covering the changed range, or nil if nothing changed
+func diffEntries(before, after []models.FileEntry) *spliceCommand {
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix &&
+		before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+	if prefix == len(before) && prefix == len(after) {
+		return nil
+	}
+	return &spliceCommand{
+		start:  prefix,
+		before: slices.Clone(before[prefix : len(before)-suffix]),
+		after:  slices.Clone(after[prefix : len(after)-suffix]),
+	}
+}
+
+// BeginEditGroup starts grouping subsequent edits of a project into a single undo step
+func (s *ProjectService) BeginEditGroup(projectID string, label string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h := s.historyFor(projectID)
+	h.grouping = true
+	h.groupLabel = label
+	h.group = nil
+}
+
+// EndEditGroup closes the current edit group and stores it as one undo step
+func (s *ProjectService) EndEditGroup(projectID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h := s.historyFor(projectID)
+	if !h.grouping {
+		return
+	}
+	h.grouping = false
+	if len(h.group) > 0 {
+		h.push(h.groupLabel, h.group)
+	}
+	h.group = nil
+}
+
+// Undo reverts the last recorded edit of the project
+func (s *ProjectService) Undo(project *models.Project) (*models.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h := s.historyFor(project.ID)
+	if h.position == 0 {
+		return nil, fmt.Errorf("nothing to undo")
+	}
+	if err := h.steps[h.position-1].cmd.revert(project); err != nil {
+		return nil, err
+	}
+	h.position--
+	project.UpdatedAt = time.Now()
+	s.trackLocked(project)
+	return project, nil
+}
+
+// Redo re-applies the last undone edit of the project
+func (s *ProjectService) Redo(project *models.Project) (*models.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h := s.historyFor(project.ID)
+	if h.position == len(h.steps) {
+		return nil, fmt.Errorf("nothing to redo")
+	}
+	if err := h.steps[h.position].cmd.apply(project); err != nil {
+		return nil, err
+	}
+	h.position++
+	project.UpdatedAt = time.Now()
+	s.trackLocked(project)
+	return project, nil
+}
+
+// GetHistory returns the edit history of a project for display
+func (s *ProjectService) GetHistory(projectID string) HistoryInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.historyFor(projectID).info()
+}
+
+// CloseProject drops the edit history, the autosave and the source watch of a
+// project that is no longer open
+func (s *ProjectService) CloseProject(projectID string) {
+	s.mu.Lock()
+	delete(s.histories, projectID)
+	s.mu.Unlock()
+
+	s.UnwatchSources(projectID)
+	s.forgetAutosave(projectID, true)
+}
+
+// UpdateISOOptions replaces the ISO options of the project as an undoable edit
+func (s *ProjectService) UpdateISOOptions(project *models.Project, opts models.ISOOptions) (*models.Project, error) {
+	// Метку тома проверяет SetVolumeID
+	if err := validateVolumeDescriptor("", opts); err != nil {
+		return nil, err
+	}
+	err := s.recordEdit(project, "ISO options", func() error {
+		project.ISOOptions = opts
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	project.UpdatedAt = time.Now()
+	return project, nil
+}
+
+// UpdateBurnOptions replaces the burn options of the project as an undoable edit
+func (s *ProjectService) UpdateBurnOptions(project *models.Project, opts models.BurnOptions) (*models.Project, error) {
+	err := s.recordEdit(project, "Burn options", func() error {
+		project.BurnOptions = opts
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	project.UpdatedAt = time.Now()
+	return project, nil
+}
+
+// SetVolumeID changes the volume ID of the project as an undoable edit
+func (s *ProjectService) SetVolumeID(project *models.Project, volumeID string) (*models.Project, error) {
+	if err := validateVolumeDescriptor(volumeID, models.ISOOptions{}); err != nil {
+		return nil, err
+	}
+	err := s.recordEdit(project, "Volume ID", func() error {
+		project.VolumeID = volumeID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	project.UpdatedAt = time.Now()
+	return project, nil
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"xorriso-ui/pkg/models"
+)
+
+func TestUndoRedo_RemoveEntries(t *testing.T) {
+	svc := NewProjectService()
+	project := newEditTestProject()
+	project.ID = "p1"
+	original := len(project.Entries)
+
+	svc.RemoveEntries(project, []string{"/photos/a.jpg", "/readme.txt"})
+	if len(project.Entries) != original-2 {
+		t.Fatalf("entries = %d, want %d", len(project.Entries), original-2)
+	}
+
+	if _, err := svc.Undo(project); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if len(project.Entries) != original {
+		t.Fatalf("after undo entries = %d, want %d", len(project.Entries), original)
+	}
+	if project.Entries[4].DestPath != "/readme.txt" {
+		t.Errorf("entry order not restored: %+v", project.Entries)
+	}
+
+	if _, err := svc.Redo(project); err != nil {
+		t.Fatalf("Redo: %v", err)
+	}
+	if len(project.Entries) != original-2 {
+		t.Errorf("after redo entries = %d, want %d", len(project.Entries), original-2)
+	}
+
+	if _, err := svc.Redo(project); err == nil {
+		t.Error("expected error when nothing to redo")
+	}
+}
+
+func TestUndo_Rename(t *testing.T) {
+	svc := NewProjectService()
+	project := newEditTestProject()
+	project.ID = "p1"
+
+	svc.RenameEntry(project, "/photos", "pictures")
+	svc.Undo(project)
+
+	if project.Entries[3].DestPath != "/photos/2024/b.jpg" || project.Entries[0].Name != "photos" {
+		t.Errorf("rename not undone: %+v", project.Entries)
+	}
+}
+
+func TestUndo_Options(t *testing.T) {
+	svc := NewProjectService()
+	project := svc.NewProject("Test", "VOL")
+
+	opts := project.ISOOptions
+	opts.Joliet = true
+	svc.UpdateISOOptions(project, opts)
+	svc.SetVolumeID(project, "NEW_VOL")
+
+	svc.Undo(project)
+	if project.VolumeID != "VOL" {
+		t.Errorf("VolumeID = %q, want VOL", project.VolumeID)
+	}
+	svc.Undo(project)
+	if project.ISOOptions.Joliet {
+		t.Error("Joliet change should be undone")
+	}
+}
+
+func TestEditGroup(t *testing.T) {
+	svc := NewProjectService()
+	project := newEditTestProject()
+	project.ID = "p1"
+
+	svc.BeginEditGroup(project.ID, "Cleanup")
+	svc.RemoveEntry(project, "/readme.txt")
+	svc.CreateFolder(project, "/", "docs")
+	svc.EndEditGroup(project.ID)
+
+	info := svc.GetHistory(project.ID)
+	if len(info.Items) != 1 || info.Items[0].Label != "Cleanup" {
+		t.Fatalf("expected single grouped step, got %+v", info.Items)
+	}
+
+	svc.Undo(project)
+	paths := destPaths(project)
+	if _, ok := paths["/readme.txt"]; !ok {
+		t.Error("grouped removal should be undone")
+	}
+	if _, ok := paths["/docs"]; ok {
+		t.Error("grouped folder creation should be undone")
+	}
+}
+
+func TestHistory_NewEditClearsRedo(t *testing.T) {
+	svc := NewProjectService()
+	project := newEditTestProject()
+	project.ID = "p1"
+
+	svc.RemoveEntry(project, "/readme.txt")
+	svc.Undo(project)
+	svc.CreateFolder(project, "/", "docs")
+
+	info := svc.GetHistory(project.ID)
+	if info.CanRedo {
+		t.Error("new edit should discard redo steps")
+	}
+	if len(info.Items) != 1 || info.Position != 1 {
+		t.Errorf("unexpected history: %+v", info)
+	}
+}
+
+func TestHistory_StepLimit(t *testing.T) {
+	svc := NewProjectService()
+	project := &models.Project{ID: "p1"}
+
+	for i := 0; i < historyMaxSteps+10; i++ {
+		svc.CreateFolder(project, "/", fmt.Sprintf("dir%d", i))
+	}
+
+	info := svc.GetHistory(project.ID)
+	if len(info.Items) != historyMaxSteps {
+		t.Errorf("history length = %d, want %d", len(info.Items), historyMaxSteps)
+	}
+}
+
+func TestHistory_EntriesMismatch(t *testing.T) {
+	svc := NewProjectService()
+	project := newEditTestProject()
+	project.ID = "p1"
+
+	svc.RemoveEntry(project, "/readme.txt")
+	project.Entries = nil
+
+	if _, err := svc.Undo(project); err == nil {
+		t.Error("expected error when project diverged from history")
+	}
+}
+
+func TestOpenProject_NewSessionID(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	path := filepath.Join(dir, "p.xorriso-project")
+	os.WriteFile(path, []byte(`{"version":1,"id":"stale"}`), 0644)
+
+	svc := NewProjectService()
+	project, err := svc.OpenProject(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project.ID == "" || project.ID == "stale" {
+		t.Errorf("ID = %q, want fresh session id", project.ID)
+	}
+}
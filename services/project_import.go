@@ -0,0 +1,516 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"xorriso-ui/pkg/models"
+	"xorriso-ui/pkg/xorriso"
+)
+
+// ImportResult is a project converted from another application's format.
+// Warnings lists settings and files that could not be carried over.
+type ImportResult struct {
+	Project  *models.Project `json:"project"`
+	Format   string          `json:"format"`
+	Warnings []string        `json:"warnings"`
+}
+
+const (
+	ImportFormatK3b           = "k3b"
+	ImportFormatBrasero       = "brasero"
+	ImportFormatXorrisoScript = "xorriso-script"
+)
+
+// ImportProject converts a K3b (.k3b) or Brasero (.xml) project file, or an xorriso
+// shell / -options_from_file script into a new project.
+// The result is not saved — FilePath stays empty until the user saves it.
+func (s *ProjectService) ImportProject(filePath string) (*ImportResult, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	project := s.NewProject(name, "")
+
+	// .k3b — zip-архив с maindata.xml; старые версии K3b писали XML напрямую
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		data, err = readK3bMainData(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := &ImportResult{Project: project}
+
+	// Всё, что не похоже на XML, разбираем как сценарий xorriso
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		result.Format = ImportFormatXorrisoScript
+		if err := importXorrisoScript(data, result); err != nil {
+			return nil, err
+		}
+		project.UpdatedAt = time.Now()
+		return result, nil
+	}
+
+	root, err := xmlRootElement(data)
+	if err != nil {
+		return nil, fmt.Errorf("unrecognized project file: %w", err)
+	}
+
+	switch {
+	case root == "k3b_data_project":
+		result.Format = ImportFormatK3b
+		err = importK3b(data, result)
+	case strings.HasPrefix(root, "k3b_"):
+		return nil, fmt.Errorf("unsupported K3b project type: %s (only data projects can be imported)", root)
+	case root == "braseroproject":
+		result.Format = ImportFormatBrasero
+		err = importBrasero(data, result)
+	default:
+		return nil, fmt.Errorf("unsupported project format: <%s>", root)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	project.UpdatedAt = time.Now()
+	return result, nil
+}
+
+func (r *ImportResult) warnf(format string, args ...any) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// warnVolumeDescriptor предупреждает о полях заголовка тома, с которыми запись не начнётся
+func (r *ImportResult) warnVolumeDescriptor(project *models.Project) {
+	if err := validateVolumeDescriptor(project.VolumeID, project.ISOOptions); err != nil {
+		r.warnf("%v; correct it before writing", err)
+	}
+}
+
+// warnSkipped сообщает об источниках, пропущенных политикой ссылок и специальных файлов
+func (r *ImportResult) warnSkipped(skipped []SkippedSource) {
+	for _, s := range skipped {
+		r.warnf("%s skipped: %s", s.Reason, s.Path)
+	}
+}
+
+// readK3bMainData extracts maindata.xml from a .k3b zip archive
+func readK3bMainData(data []byte) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid K3b archive: %w", err)
+	}
+	for _, f := range zr.File {
+		if f.Name != "maindata.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = rc.Close() }()
+		return io.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("invalid K3b archive: maindata.xml not found")
+}
+
+// xmlRootElement returns the name of the first element in an XML document
+func xmlRootElement(data []byte) (string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.CharsetReader = passthroughCharsetReader
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			return se.Name.Local, nil
+		}
+	}
+}
+
+// passthroughCharsetReader accepts UTF-8 spelled differently (Brasero writes encoding="UTF8")
+func passthroughCharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(strings.ReplaceAll(charset, "-", "")) {
+	case "utf8", "usascii", "ascii":
+		return input, nil
+	}
+	return nil, fmt.Errorf("unsupported charset: %s", charset)
+}
+
+func decodeXML(data []byte, v any) error {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.CharsetReader = passthroughCharsetReader
+	return dec.Decode(v)
+}
+
+// --- K3b ---
+
+type k3bProject struct {
+	General struct {
+		WritingMode string    `xml:"writing_mode"`
+		Dummy       k3bToggle `xml:"dummy"`
+		OnlyImages  k3bToggle `xml:"only_create_images"`
+	} `xml:"general"`
+	Options struct {
+		Items []k3bOption `xml:",any"`
+	} `xml:"options"`
+	Header struct {
+		VolumeID      string `xml:"volume_id"`
+		VolumeSetID   string `xml:"volume_set_id"`
+		SystemID      string `xml:"system_id"`
+		ApplicationID string `xml:"application_id"`
+		Publisher     string `xml:"publisher"`
+		Preparer      string `xml:"preparer"`
+	} `xml:"header"`
+	Files      k3bDir     `xml:"files"`
+	BootImages []xml.Name `xml:"boot_images>boot_image"`
+}
+
+type k3bToggle struct {
+	Activated string `xml:"activated,attr"`
+}
+
+func (t k3bToggle) on() bool { return t.Activated == "yes" }
+
+type k3bOption struct {
+	XMLName   xml.Name
+	Activated string `xml:"activated,attr"`
+	Value     string `xml:",chardata"`
+}
+
+type k3bDir struct {
+	Name     string    `xml:"name,attr"`
+	Files    []k3bFile `xml:"file"`
+	Dirs     []k3bDir  `xml:"directory"`
+	Symlinks []k3bFile `xml:"symlink"`
+	Specials []k3bFile `xml:"special"`
+}
+
+type k3bFile struct {
+	Name string `xml:"name,attr"`
+	URL  string `xml:"url"`
+}
+
+// k3bIgnoredOptions — опции K3b без влияния на содержимое диска, о них не предупреждаем
+var k3bIgnoredOptions = map[string]bool{
+	"data_track_mode":           true,
+	"whitespace_treatment":      true,
+	"whitespace_replace_string": true,
+	"remove_images":             true,
+	"volume_set_size":           true,
+	"volume_set_number":         true,
+}
+
+func importK3b(data []byte, result *ImportResult) error {
+	var k k3bProject
+	if err := decodeXML(data, &k); err != nil {
+		return fmt.Errorf("invalid K3b project: %w", err)
+	}
+	project := result.Project
+
+	// Заголовок тома
+	project.VolumeID = strings.TrimSpace(k.Header.VolumeID)
+	project.ISOOptions.PublisherID = strings.TrimSpace(k.Header.Publisher)
+	project.ISOOptions.VolumeSetID = strings.TrimSpace(k.Header.VolumeSetID)
+	project.ISOOptions.PreparerID = strings.TrimSpace(k.Header.Preparer)
+	project.ISOOptions.SystemID = strings.TrimSpace(k.Header.SystemID)
+	// Собственный идентификатор K3b заменяется идентификатором xorriso-ui
+	if v := strings.TrimSpace(k.Header.ApplicationID); !strings.HasPrefix(strings.ToUpper(v), "K3B") {
+		project.ISOOptions.ApplicationID = v
+	}
+	result.warnVolumeDescriptor(project)
+
+	// Параметры записи
+	switch strings.ToLower(k.General.WritingMode) {
+	case "", "auto":
+		project.BurnOptions.BurnMode = "auto"
+	case "dao":
+		project.BurnOptions.BurnMode = "DAO"
+	case "tao":
+		project.BurnOptions.BurnMode = "TAO"
+	default:
+		result.warnf("writing mode %q is not supported, using auto", k.General.WritingMode)
+		project.BurnOptions.BurnMode = "auto"
+	}
+	project.BurnOptions.DummyMode = k.General.Dummy.on()
+	if k.General.OnlyImages.on() {
+		result.warnf("\"only create image\" is not stored in the project, use Create ISO instead")
+	}
+
+	// Параметры файловой системы
+	for _, opt := range k.Options.Items {
+		name := opt.XMLName.Local
+		on := opt.Activated == "yes"
+		switch name {
+		case "rock_ridge":
+			project.ISOOptions.RockRidge = on
+		case "joliet":
+			project.ISOOptions.Joliet = on
+		case "udf":
+			project.ISOOptions.UDF = on
+		case "verify_data":
+			project.BurnOptions.Verify = on
+		case "iso_level":
+			level, err := strconv.Atoi(strings.TrimSpace(opt.Value))
+			if err != nil || level < 1 || level > 4 {
+				result.warnf("invalid ISO level %q", opt.Value)
+				continue
+			}
+			project.ISOOptions.ISOLevel = level
+		case "multisession":
+			switch strings.TrimSpace(opt.Value) {
+			case "start", "continue":
+				project.BurnOptions.Multisession = true
+				project.BurnOptions.CloseDisc = false
+			case "finish":
+				project.BurnOptions.Multisession = false
+				project.BurnOptions.CloseDisc = true
+			}
+		default:
+			if on && !k3bIgnoredOptions[name] {
+				result.warnf("K3b option %s is not supported", name)
+			}
+		}
+	}
+
+	if len(k.BootImages) > 0 {
+		result.warnf("boot images are not supported (%d skipped)", len(k.BootImages))
+	}
+
+	importK3bDir(project, k.Files, "/", result)
+	return nil
+}
+
+// importK3bDir adds K3b directory items under destDir.
+// K3b directories are virtual — each file inside carries its own source URL.
+func importK3bDir(project *models.Project, dir k3bDir, destDir string, result *ImportResult) {
+	for _, f := range dir.Files {
+		destPath := filepath.Join(destDir, f.Name)
+		skipped, err := addSourceAt(project, f.URL, destPath)
+		result.warnSkipped(skipped)
+		if err != nil {
+			result.warnf("source not found, skipped: %s", f.URL)
+		}
+	}
+	for _, f := range dir.Symlinks {
+		result.warnf("symlink skipped: %s", filepath.Join(destDir, f.Name))
+	}
+	for _, f := range dir.Specials {
+		result.warnf("special file skipped: %s", filepath.Join(destDir, f.Name))
+	}
+	for _, sub := range dir.Dirs {
+		destPath := filepath.Join(destDir, sub.Name)
+		project.Entries = append(project.Entries, models.FileEntry{
+			DestPath: destPath,
+			Name:     sub.Name,
+			IsDir:    true,
+			Listed:   true,
+			ModTime:  time.Now().UnixMilli(),
+		})
+		importK3bDir(project, sub, destPath, result)
+	}
+}
+
+// --- Brasero ---
+
+type braseroProject struct {
+	Label  string `xml:"label"`
+	Tracks []struct {
+		Data *struct {
+			Grafts   []braseroGraft `xml:"graft"`
+			Excluded []string       `xml:"excluded"`
+		} `xml:"data"`
+		Audio *struct{} `xml:"audio"`
+		Video *struct{} `xml:"video"`
+	} `xml:"track"`
+}
+
+type braseroGraft struct {
+	Path string `xml:"path"`
+	URI  string `xml:"uri"`
+}
+
+func importBrasero(data []byte, result *ImportResult) error {
+	var b braseroProject
+	if err := decodeXML(data, &b); err != nil {
+		return fmt.Errorf("invalid Brasero project: %w", err)
+	}
+	project := result.Project
+
+	if label := strings.TrimSpace(b.Label); label != "" {
+		project.VolumeID = label
+	}
+	result.warnf("Brasero projects do not store ISO and burn settings, defaults are used")
+
+	var excluded []string
+	for _, track := range b.Tracks {
+		if track.Audio != nil || track.Video != nil {
+			result.warnf("audio/video tracks are not supported and were skipped")
+			continue
+		}
+		if track.Data == nil {
+			continue
+		}
+		for _, uri := range track.Data.Excluded {
+			if p, err := fileURIPath(uri); err == nil {
+				excluded = append(excluded, p)
+			}
+		}
+		for _, g := range track.Data.Grafts {
+			destPath := filepath.Join("/", g.Path)
+			if g.URI == "" {
+				// Графт без источника — пустая папка, созданная в Brasero
+				project.Entries = append(project.Entries, models.FileEntry{
+					DestPath: destPath,
+					Name:     filepath.Base(destPath),
+					IsDir:    true,
+					Listed:   true,
+					ModTime:  time.Now().UnixMilli(),
+				})
+				continue
+			}
+			src, err := fileURIPath(g.URI)
+			if err != nil {
+				result.warnf("unsupported source %s: %v", g.URI, err)
+				continue
+			}
+			skipped, err := addSourceAt(project, src, destPath)
+			result.warnSkipped(skipped)
+			if err != nil {
+				result.warnf("source not found, skipped: %s", src)
+			}
+		}
+	}
+
+	if len(excluded) > 0 {
+		var filtered, dropped []models.FileEntry
+		for _, e := range project.Entries {
+			if !isExcludedSource(e.SourcePath, excluded) {
+				filtered = append(filtered, e)
+			} else {
+				dropped = append(dropped, e)
+			}
+		}
+		project.Entries = filtered
+		rememberRemoved(project, dropped)
+	}
+	return nil
+}
+
+// fileURIPath converts a file:// URI to a local path
+func fileURIPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("only local files are supported")
+	}
+	return u.Path, nil
+}
+
+func isExcludedSource(src string, excluded []string) bool {
+	if src == "" {
+		return false
+	}
+	for _, ex := range excluded {
+		if isUnderPath(src, ex) {
+			return true
+		}
+	}
+	return false
+}
+
+// importXorrisoScript переносит опции и файлы из сценария xorriso.
+// Поздние операции над тем же путём заменяют ранние, как при выполнении сценария.
+func importXorrisoScript(data []byte, result *ImportResult) error {
+	script, err := xorriso.ParseScript(string(data))
+	if err != nil {
+		return err
+	}
+
+	p := result.Project
+	p.VolumeID = script.Project.VolumeID
+	p.ISOOptions = script.Project.ISOOptions
+	p.BurnOptions = script.Project.BurnOptions
+	result.Warnings = append(result.Warnings, script.Warnings...)
+
+	// Значения по умолчанию не закрепляем, чтобы они менялись вместе с приложением
+	if p.ISOOptions.ApplicationID == appApplicationID {
+		p.ISOOptions.ApplicationID = ""
+	}
+	if p.ISOOptions.SystemID == appSystemID {
+		p.ISOOptions.SystemID = ""
+	}
+	result.warnVolumeDescriptor(p)
+
+	for _, m := range script.Mappings {
+		if m.Source == "" {
+			// -mkdir существующего каталога ничего не меняет
+			if !isoPathsInUse(p.Entries, nil)[m.Dest] {
+				p.Entries = append(p.Entries, models.FileEntry{DestPath: m.Dest, Name: filepath.Base(m.Dest), IsDir: true, Listed: true})
+			}
+			continue
+		}
+
+		p.Entries = slices.DeleteFunc(p.Entries, func(e models.FileEntry) bool {
+			return isUnderPath(e.DestPath, m.Dest)
+		})
+		switch {
+		case m.Single:
+			skipped, err := addSingleSourceAt(p, m.Source, m.Dest)
+			result.warnSkipped(skipped)
+			if err != nil {
+				result.warnf("source not found, skipped: %s", m.Source)
+			}
+		default:
+			skipped, err := addSourceAt(p, m.Source, m.Dest)
+			result.warnSkipped(skipped)
+			if err != nil {
+				result.warnf("source not found, skipped: %s", m.Source)
+			}
+		}
+	}
+
+	applied := make(map[string]bool)
+	for i, e := range p.Entries {
+		if meta, ok := script.Metadata[e.DestPath]; ok {
+			p.Entries[i].Meta = meta
+			applied[e.DestPath] = true
+		}
+	}
+	for _, dest := range slices.Sorted(maps.Keys(script.Metadata)) {
+		if !applied[dest] {
+			result.warnf("attributes for %s ignored: no such entry in the project", dest)
+		}
+	}
+
+	weighted := make(map[string]bool)
+	for i, e := range p.Entries {
+		if w, ok := script.SortWeights[e.DestPath]; ok {
+			p.Entries[i].SortWeight = w
+			weighted[e.DestPath] = true
+		}
+	}
+	for _, dest := range slices.Sorted(maps.Keys(script.SortWeights)) {
+		if !weighted[dest] {
+			result.warnf("sort weight for %s ignored: no such entry in the project", dest)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"xorriso-ui/pkg/models"
+)
+
+// writeK3bArchive packs maindata.xml into a .k3b zip file the way K3b does
+func writeK3bArchive(t *testing.T, path, mainData string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create("mimetype")
+	w.Write([]byte("application/x-k3b"))
+	w, _ = zw.Create("maindata.xml")
+	w.Write([]byte(mainData))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func hasWarning(warnings []string, substr string) bool {
+	for _, w := range warnings {
+		if strings.Contains(w, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestImportProject_K3b(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "report.pdf")
+	os.WriteFile(src, []byte("pdf"), 0644)
+
+	mainData := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE k3b_data_project>
+<k3b_data_project>
+ <general>
+  <writing_mode>dao</writing_mode>
+  <dummy activated="yes"/>
+ </general>
+ <options>
+  <rock_ridge activated="yes"/>
+  <joliet activated="yes"/>
+  <udf activated="no"/>
+  <iso_level>2</iso_level>
+  <verify_data activated="yes"/>
+  <multisession>start</multisession>
+  <create_trans_tbl activated="yes"/>
+ </options>
+ <header>
+  <volume_id>ARCHIVE_1</volume_id>
+  <publisher>ACME</publisher>
+  <preparer>John</preparer>
+  <volume_set_id>ARCHIVES</volume_set_id>
+  <system_id>LINUX</system_id>
+  <application_id>K3B THE CD KREATOR (C) 1998-2010 SEBASTIAN TRUEG AND MICHAL MALEK</application_id>
+ </header>
+ <files>
+  <file name="renamed.pdf"><url>` + src + `</url></file>
+  <file name="gone.txt"><url>/nonexistent/gone.txt</url></file>
+  <directory name="docs">
+   <file name="copy.pdf"><url>` + src + `</url></file>
+  </directory>
+ </files>
+</k3b_data_project>`
+
+	path := filepath.Join(dir, "archive.k3b")
+	writeK3bArchive(t, path, mainData)
+
+	svc := NewProjectService()
+	result, err := svc.ImportProject(path)
+	if err != nil {
+		t.Fatalf("ImportProject: %v", err)
+	}
+	p := result.Project
+
+	if result.Format != ImportFormatK3b {
+		t.Errorf("Format = %q, want k3b", result.Format)
+	}
+	if p.Name != "archive" || p.VolumeID != "ARCHIVE_1" || p.ISOOptions.PublisherID != "ACME" {
+		t.Errorf("unexpected header mapping: name=%q volid=%q publisher=%q", p.Name, p.VolumeID, p.ISOOptions.PublisherID)
+	}
+	if p.ISOOptions.PreparerID != "John" || p.ISOOptions.VolumeSetID != "ARCHIVES" ||
+		p.ISOOptions.SystemID != "LINUX" || p.ISOOptions.ApplicationID != "" {
+		t.Errorf("unexpected volume descriptor: %+v", p.ISOOptions)
+	}
+	if !p.ISOOptions.RockRidge || !p.ISOOptions.Joliet || p.ISOOptions.UDF || p.ISOOptions.ISOLevel != 2 {
+		t.Errorf("unexpected ISO options: %+v", p.ISOOptions)
+	}
+	if p.BurnOptions.BurnMode != "DAO" || !p.BurnOptions.DummyMode || !p.BurnOptions.Verify || !p.BurnOptions.Multisession {
+		t.Errorf("unexpected burn options: %+v", p.BurnOptions)
+	}
+
+	paths := destPaths(p)
+	if paths["/renamed.pdf"] != src || paths["/docs/copy.pdf"] != src {
+		t.Errorf("unexpected entries: %+v", paths)
+	}
+	if e, ok := paths["/docs"]; !ok || e != "" {
+		t.Errorf("expected virtual /docs folder, got %q (exists=%v)", e, ok)
+	}
+
+	for _, w := range []string{"create_trans_tbl", "/nonexistent/gone.txt"} {
+		if !hasWarning(result.Warnings, w) {
+			t.Errorf("expected warning about %s, got %v", w, result.Warnings)
+		}
+	}
+}
+
+func TestImportProject_K3bAudioRejected(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audio.k3b")
+	writeK3bArchive(t, path, `<?xml version="1.0" encoding="UTF-8"?><k3b_audio_project></k3b_audio_project>`)
+
+	svc := NewProjectService()
+	if _, err := svc.ImportProject(path); err == nil {
+		t.Fatal("expected error for K3b audio project")
+	}
+}
+
+func TestImportProject_Brasero(t *testing.T) {
+	dir := t.TempDir()
+	photos := filepath.Join(dir, "photos")
+	os.MkdirAll(filepath.Join(photos, "raw"), 0755)
+	os.WriteFile(filepath.Join(photos, "a.jpg"), []byte("a"), 0644)
+	os.WriteFile(filepath.Join(photos, "raw", "a.cr2"), []byte("raw"), 0644)
+
+	xmlData := `<?xml version="1.0" encoding="UTF8"?>
+<braseroproject>
+<version>0.2</version>
+<label>Photos 2024</label>
+<track>
+<data>
+<graft><path>/pictures</path><uri>file://` + photos + `</uri></graft>
+<graft><path>/empty</path></graft>
+<excluded>file://` + filepath.Join(photos, "raw") + `</excluded>
+</data>
+</track>
+</braseroproject>`
+
+	path := filepath.Join(dir, "photos.xml")
+	os.WriteFile(path, []byte(xmlData), 0644)
+
+	svc := NewProjectService()
+	result, err := svc.ImportProject(path)
+	if err != nil {
+		t.Fatalf("ImportProject: %v", err)
+	}
+	p := result.Project
+
+	if result.Format != ImportFormatBrasero || p.VolumeID != "Photos 2024" {
+		t.Errorf("unexpected result: format=%q volid=%q", result.Format, p.VolumeID)
+	}
+
+	paths := destPaths(p)
+	if paths["/pictures"] != photos || paths["/pictures/a.jpg"] != filepath.Join(photos, "a.jpg") {
+		t.Errorf("graft not imported: %+v", paths)
+	}
+	if _, ok := paths["/pictures/raw/a.cr2"]; ok {
+		t.Error("excluded path should not be imported")
+	}
+	if _, ok := paths["/empty"]; !ok {
+		t.Error("graft without uri should become an empty folder")
+	}
+}
+
+func TestImportProject_Unsupported(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "other.xml")
+	os.WriteFile(path, []byte(`<?xml version="1.0"?><something/>`), 0644)
+
+	svc := NewProjectService()
+	if _, err := svc.ImportProject(path); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
+
+func TestImportProject_XorrisoScript(t *testing.T) {
+	dir := t.TempDir()
+	docs := filepath.Join(dir, "my docs")
+	os.MkdirAll(docs, 0755)
+	os.WriteFile(filepath.Join(docs, "a.txt"), []byte("a"), 0644)
+	os.WriteFile(filepath.Join(docs, "b.txt"), []byte("b"), 0644)
+
+	script := "-dev /dev/sr0\n" +
+		"-volid BACKUP -joliet on -md5 on\n" +
+		"-application_id 'XORRISO-UI (C) Evgeniy Medvedev' -system_id GNU -preparer_id Backups\n" +
+		"-map '" + docs + "' /docs\n" +
+		"-map '" + filepath.Join(docs, "b.txt") + "' /docs/renamed.txt\n" +
+		"-map /nonexistent/file /gone\n" +
+		"-mkdir /docs /empty --\n" +
+		"-speed 4 -close on\n" +
+		"-commit\n"
+	path := filepath.Join(dir, "burn.xorriso")
+	os.WriteFile(path, []byte(script), 0644)
+
+	svc := NewProjectService()
+	result, err := svc.ImportProject(path)
+	if err != nil {
+		t.Fatalf("ImportProject: %v", err)
+	}
+	p := result.Project
+
+	if result.Format != ImportFormatXorrisoScript || p.VolumeID != "BACKUP" {
+		t.Errorf("unexpected result: format=%q volid=%q", result.Format, p.VolumeID)
+	}
+	if !p.ISOOptions.Joliet || !p.ISOOptions.MD5 || p.BurnOptions.Speed != "4" || !p.BurnOptions.CloseDisc {
+		t.Errorf("unexpected options: %+v %+v", p.ISOOptions, p.BurnOptions)
+	}
+	// Идентификатор самого приложения не закрепляется в проекте
+	if p.ISOOptions.ApplicationID != "" || p.ISOOptions.SystemID != "GNU" || p.ISOOptions.PreparerID != "Backups" {
+		t.Errorf("unexpected volume descriptor: %+v", p.ISOOptions)
+	}
+
+	paths := destPaths(p)
+	want := map[string]string{
+		"/docs":             docs,
+		"/docs/a.txt":       filepath.Join(docs, "a.txt"),
+		"/docs/b.txt":       filepath.Join(docs, "b.txt"),
+		"/docs/renamed.txt": filepath.Join(docs, "b.txt"),
+		"/empty":            "",
+	}
+	if len(paths) != len(want) {
+		t.Errorf("got %d entries, want %d: %+v", len(paths), len(want), paths)
+	}
+	for dest, src := range want {
+		if got, ok := paths[dest]; !ok || got != src {
+			t.Errorf("entry %s: source = %q (exists=%v), want %q", dest, got, ok, src)
+		}
+	}
+	if !hasWarning(result.Warnings, "/nonexistent/file") {
+		t.Errorf("expected warning for missing source, got %v", result.Warnings)
+	}
+}
+
+func TestImportProject_ExportedScriptRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	docs := filepath.Join(dir, "docs")
+	os.MkdirAll(docs, 0755)
+	os.WriteFile(filepath.Join(docs, "keep.txt"), []byte("k"), 0644)
+	os.WriteFile(filepath.Join(docs, "drop.txt"), []byte("d"), 0644)
+
+	projects := NewProjectService()
+	original := projects.NewProject("Original", "ROUND_TRIP")
+	projects.AddFiles(original, []string{docs}, "/")
+	projects.RemoveEntry(original, "/docs/drop.txt")
+	meta := models.EntryMetadata{Mode: "0600", Owner: "0", MTime: 1700000000000, HideJoliet: true}
+	if _, err := projects.SetEntryMetadata(original, []string{"/docs/keep.txt"}, meta); err != nil {
+		t.Fatal(err)
+	}
+
+	burn := NewBurnService(&mockRunner{})
+	for _, format := range []string{ScriptFormatShell, ScriptFormatOptions} {
+		path := filepath.Join(dir, "script."+format)
+		err := burn.SaveScript(original, original.BurnOptions, ScriptOptions{
+			Format: format, DevicePath: "/dev/sr0", Verify: true, Eject: true,
+		}, path)
+		if err != nil {
+			t.Fatalf("%s: SaveScript: %v", format, err)
+		}
+
+		result, err := projects.ImportProject(path)
+		if err != nil {
+			t.Fatalf("%s: ImportProject: %v", format, err)
+		}
+		imported := result.Project
+		if imported.VolumeID != original.VolumeID || imported.ISOOptions != original.ISOOptions {
+			t.Errorf("%s: options differ: %+v vs %+v", format, imported.ISOOptions, original.ISOOptions)
+		}
+		if !imported.BurnOptions.Verify || !imported.BurnOptions.Eject {
+			t.Errorf("%s: verify/eject steps not recognized: %+v", format, imported.BurnOptions)
+		}
+		if got, want := destPaths(imported), destPaths(original); len(got) != len(want) {
+			t.Errorf("%s: entries = %+v, want %+v", format, got, want)
+		}
+		for _, e := range imported.Entries {
+			if e.DestPath == "/docs/keep.txt" && e.Meta != meta {
+				t.Errorf("%s: metadata = %+v, want %+v", format, e.Meta, meta)
+			}
+		}
+		if _, ok := destPaths(imported)["/docs/drop.txt"]; ok {
+			t.Errorf("%s: removed entry came back", format)
+		}
+		if len(result.Warnings) != 0 {
+			t.Errorf("%s: unexpected warnings: %v", format, result.Warnings)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"xorriso-ui/pkg/models"
+	"xorriso-ui/pkg/xorriso"
+)
+
+const (
+	LargeFileMultiExtent = "multi-extent" // ISO level 3: файл из нескольких экстентов
+	LargeFileSplit       = "split"        // каталог с частями файла (-split_size)
+	LargeFileUnsupported = "unsupported"  // уровень ISO 1–2 без разделения — запись завершится ошибкой
+)
+
+// LargeFile is a project file bigger than one ISO 9660 extent
+type LargeFile struct {
+	DestPath string `json:"destPath"`
+	Size     int64  `json:"size"`
+	Pieces   int    `json:"pieces"` // экстентов или частей при текущих опциях
+}
+
+// LargeFileReport describes how files over 4 GiB will be written with the
+// current ISO options. Handling is empty when the project has no such files.
+type LargeFileReport struct {
+	Files          []LargeFile `json:"files"`
+	Handling       string      `json:"handling"`
+	Warnings       []string    `json:"warnings"` // системы, которые не прочитают результат
+	ReassemblyNote string      `json:"reassemblyNote,omitempty"`
+	ImageBytes     int64       `json:"imageBytes"` // оценка размера образа при текущих опциях
+}
+
+// CheckLargeFiles finds files that do not fit into a single ISO 9660 extent and
+// reports how they will be written and who can read them.
+func (s *ProjectService) CheckLargeFiles(project *models.Project) *LargeFileReport {
+	opts := project.ISOOptions
+	report := &LargeFileReport{
+		Files:      []LargeFile{},
+		Warnings:   []string{},
+		ImageBytes: estimateImageSize(project.Entries, opts),
+	}
+	for _, e := range project.Entries {
+		if isLargeFile(e) {
+			pieces, _ := filePieces(e, opts)
+			report.Files = append(report.Files, LargeFile{DestPath: e.DestPath, Size: e.Size, Pieces: pieces})
+		}
+	}
+	if len(report.Files) == 0 {
+		return report
+	}
+
+	report.Handling = largeFileHandling(opts)
+	switch report.Handling {
+	case LargeFileUnsupported:
+		report.Warnings = append(report.Warnings,
+			fmt.Sprintf("ISO level %d cannot hold files over 4 GiB: raise the level to 3 or split the files", isoLevel(opts)))
+	case LargeFileMultiExtent:
+		report.Warnings = append(report.Warnings,
+			"macOS, older Windows versions and most DVD/Blu-ray players, TVs and car stereos cannot read files over 4 GiB from ISO 9660")
+		if opts.Joliet {
+			report.Warnings = append(report.Warnings,
+				"Windows may show multi-extent files in the Joliet tree truncated to 4 GiB")
+		}
+		if !opts.RockRidge && !opts.Joliet {
+			report.Warnings = append(report.Warnings,
+				"without Rock Ridge or Joliet only the ISO 9660 tree is available, Linux reads multi-extent files from it")
+		}
+	case LargeFileSplit:
+		report.Warnings = append(report.Warnings,
+			"every large file appears as a folder of parts that must be joined before use")
+		report.ReassemblyNote = reassemblyNote
+	}
+	return report
+}
+
+// reassemblyNote объясняет, как собрать файл из частей, созданных -split_size
+const reassemblyNote = `Files larger than the part size are stored as a folder with the file's name
+containing parts named part_<N>_of_<M>_at_<offset>_with_<size>_of_<total>.
+xorriso restores the original file automatically:
+  xorriso -osirrox on -indev /dev/sr0 -extract /path/in/image /target/file
+To join the parts by hand, concatenate them in the order of <N>:
+  Linux, macOS: cat $(ls -v part_*) > file
+  Windows:      copy /b part_1_of_3_... + part_2_of_3_... + part_3_of_3_... file`
+
+// ResolveLargeFiles changes the ISO options so that files over 4 GiB can be
+// written: LargeFileMultiExtent raises the ISO level to 3, LargeFileSplit
+// splits them into parts of xorriso.DefaultSplitSize.
+func (s *ProjectService) ResolveLargeFiles(project *models.Project, mode string) (*models.Project, error) {
+	opts := project.ISOOptions
+	switch mode {
+	case LargeFileMultiExtent:
+		opts.ISOLevel = max(isoLevel(opts), 3)
+		opts.SplitSize = 0
+	case LargeFileSplit:
+		opts.SplitSize = xorriso.DefaultSplitSize
+	default:
+		return nil, fmt.Errorf("unknown large file mode: %s", mode)
+	}
+
+	err := s.recordEdit(project, "Large files", func() error {
+		project.ISOOptions = opts
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	project.UpdatedAt = time.Now()
+	return project, nil
+}
+
+// validateLargeFiles отклоняет проект, который xorriso не сможет записать из-за размера файлов
+func validateLargeFiles(project *models.Project) error {
+	if largeFileHandling(project.ISOOptions) != LargeFileUnsupported {
+		return nil
+	}
+	for _, e := range project.Entries {
+		if isLargeFile(e) {
+			return fmt.Errorf("file over 4 GiB needs ISO level 3 or splitting: %s", e.DestPath)
+		}
+	}
+	return nil
+}
+
+func isLargeFile(e models.FileEntry) bool {
+	return !e.IsDir && e.Type == "" && e.Size > xorriso.ISO9660MaxExtentSize
+}
+
+// largeFileHandling определяет, как xorriso запишет большие файлы проекта
+func largeFileHandling(opts models.ISOOptions) string {
+	if opts.SplitSize > 0 && opts.SplitSize <= xorriso.ISO9660MaxExtentSize {
+		return LargeFileSplit
+	}
+	if isoLevel(opts) >= 3 {
+		return LargeFileMultiExtent
+	}
+	return LargeFileUnsupported
+}
+
+// filePieces возвращает число экстентов или частей файла в образе и размер каждого,
+// кроме последнего
+func filePieces(e models.FileEntry, opts models.ISOOptions) (int, int64) {
+	pieceSize := xorriso.ISO9660MaxExtentSize
+	if opts.SplitSize > 0 && opts.SplitSize < pieceSize {
+		pieceSize = opts.SplitSize
+	}
+	if e.IsDir || e.Type != "" || e.Size <= pieceSize {
+		return 1, pieceSize
+	}
+	return int((e.Size + pieceSize - 1) / pieceSize), pieceSize
+}
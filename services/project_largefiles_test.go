@@ -0,0 +1,122 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"xorriso-ui/pkg/models"
+	"xorriso-ui/pkg/xorriso"
+)
+
+func newLargeFileProject(level int) *models.Project {
+	return &models.Project{
+		ID: "p1",
+		Entries: []models.FileEntry{
+			{SourcePath: "/src/movie.mkv", DestPath: "/movie.mkv", Size: 9 << 30},
+			{SourcePath: "/src/notes.txt", DestPath: "/notes.txt", Size: 100},
+		},
+		ISOOptions: models.ISOOptions{ISOLevel: level, RockRidge: true, Joliet: true},
+	}
+}
+
+func TestCheckLargeFiles_NoLargeFiles(t *testing.T) {
+	project := newLargeFileProject(1)
+	project.Entries = project.Entries[1:]
+
+	report := NewProjectService().CheckLargeFiles(project)
+	if len(report.Files) != 0 || report.Handling != "" || len(report.Warnings) != 0 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
+
+func TestCheckLargeFiles_Handling(t *testing.T) {
+	svc := NewProjectService()
+	project := newLargeFileProject(2)
+
+	report := svc.CheckLargeFiles(project)
+	if report.Handling != LargeFileUnsupported || len(report.Files) != 1 || report.Files[0].DestPath != "/movie.mkv" {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if err := validateLargeFiles(project); err == nil {
+		t.Error("ISO level 2 with a 9 GiB file should be rejected")
+	}
+
+	if _, err := svc.ResolveLargeFiles(project, LargeFileMultiExtent); err != nil {
+		t.Fatal(err)
+	}
+	report = svc.CheckLargeFiles(project)
+	if project.ISOOptions.ISOLevel != 3 || report.Handling != LargeFileMultiExtent || report.Files[0].Pieces != 3 {
+		t.Errorf("multi-extent: level %d, report %+v", project.ISOOptions.ISOLevel, report)
+	}
+	if !hasWarning(report.Warnings, "Joliet") {
+		t.Errorf("expected a Joliet warning, got %v", report.Warnings)
+	}
+	multiBytes := report.ImageBytes
+
+	if _, err := svc.ResolveLargeFiles(project, LargeFileSplit); err != nil {
+		t.Fatal(err)
+	}
+	report = svc.CheckLargeFiles(project)
+	if report.Handling != LargeFileSplit || report.Files[0].Pieces != 3 || report.ReassemblyNote == "" {
+		t.Errorf("split: report %+v", report)
+	}
+	// Части округляются до блока и получают свои записи каталога
+	if report.ImageBytes <= multiBytes {
+		t.Errorf("split image estimate %d should exceed multi-extent estimate %d", report.ImageBytes, multiBytes)
+	}
+
+	if _, err := svc.ResolveLargeFiles(project, "zip"); err == nil {
+		t.Error("unknown mode should be rejected")
+	}
+	if _, err := svc.Undo(project); err != nil {
+		t.Fatal(err)
+	}
+	if project.ISOOptions.SplitSize != 0 || project.ISOOptions.ISOLevel != 3 {
+		t.Errorf("undo should restore multi-extent options, got %+v", project.ISOOptions)
+	}
+}
+
+func TestGetBurnCommand_SplitSize(t *testing.T) {
+	svc := NewBurnService(&mockRunner{})
+	project := newLargeFileProject(2)
+	project.ISOOptions.SplitSize = xorriso.DefaultSplitSize
+
+	cmdline, err := svc.GetBurnCommand(project, "/dev/sr0", models.BurnOptions{Speed: "auto", BurnMode: "auto"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(cmdline, "-split_size 4293918720") {
+		t.Errorf("expected -split_size in %s", cmdline)
+	}
+	if err := validateLargeFiles(project); err != nil {
+		t.Errorf("split project should be accepted: %v", err)
+	}
+}
+
+func TestStartBurn_RejectsLargeFilesAtLowLevel(t *testing.T) {
+	svc := NewBurnService(&mockRunner{})
+	svc.emitEvent = noopEmit
+	_, err := svc.StartBurn(newLargeFileProject(1), "/dev/sr0", models.BurnOptions{Speed: "auto", BurnMode: "auto"})
+	if err == nil || !strings.Contains(err.Error(), "/movie.mkv") {
+		t.Errorf("expected large file error, got %v", err)
+	}
+}
+
+func TestCheckLargeFiles_DefaultLevel(t *testing.T) {
+	// Сценарии и проекты K3b без уровня пишутся с уровнем xorriso по умолчанию
+	project := newLargeFileProject(0)
+	if report := NewProjectService().CheckLargeFiles(project); report.Handling != LargeFileMultiExtent {
+		t.Errorf("handling = %q, want %q", report.Handling, LargeFileMultiExtent)
+	}
+	if err := validateLargeFiles(project); err != nil {
+		t.Errorf("project without a level should be accepted: %v", err)
+	}
+
+	cmdline, err := NewBurnService(&mockRunner{}).GetBurnCommand(project, "/dev/sr0", models.BurnOptions{Speed: "auto", BurnMode: "auto"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(cmdline, "-iso_level 3") {
+		t.Errorf("the level the check assumed must be passed to xorriso: %s", cmdline)
+	}
+}
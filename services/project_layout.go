@@ -0,0 +1,266 @@
+package services
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"math"
+	"math/bits"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"xorriso-ui/pkg/models"
+)
+
+// sortWeight — одна команда -sort_weight; более поздние переопределяют ранние
+type sortWeight struct {
+	path   string
+	weight int
+}
+
+// SetSortWeight sets the manual sort weight of the given entries. For folders the
+// weight applies to every file inside. Zero removes the manual weight.
+func (s *ProjectService) SetSortWeight(project *models.Project, destPaths []string, weight int) (*models.Project, error) {
+	if err := validateSortWeight(weight); err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool, len(project.Entries))
+	for _, e := range project.Entries {
+		existing[e.DestPath] = true
+	}
+	targets := make(map[string]bool, len(destPaths))
+	for _, p := range destPaths {
+		if !existing[p] {
+			return nil, fmt.Errorf("entry not found: %s", p)
+		}
+		targets[p] = true
+	}
+
+	label := "Change sort weight"
+	if weight == 0 {
+		label = "Reset sort weight"
+	}
+	err := s.recordEdit(project, label, func() error {
+		for i, e := range project.Entries {
+			if targets[e.DestPath] {
+				project.Entries[i].SortWeight = weight
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	project.UpdatedAt = time.Now()
+	return project, nil
+}
+
+// SetSortRules replaces the glob sort rules of the project. Later rules win
+// over earlier ones, manual entry weights win over all rules.
+func (s *ProjectService) SetSortRules(project *models.Project, rules []models.SortRule) (*models.Project, error) {
+	for _, r := range rules {
+		if err := validateGlob(r.Pattern); err != nil {
+			return nil, err
+		}
+		if err := validateSortWeight(r.Weight); err != nil {
+			return nil, err
+		}
+	}
+
+	err := s.recordEdit(project, "Sort rules", func() error {
+		project.SortRules = slices.Clone(rules)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	project.UpdatedAt = time.Now()
+	return project, nil
+}
+
+// validateGlob проверяет шаблон правила сортировки или исключения
+func validateGlob(pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("pattern is empty")
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	return nil
+}
+
+// validateSortWeight — libisofs хранит вес как 32-битное целое
+func validateSortWeight(weight int) error {
+	if weight < math.MinInt32 || weight > math.MaxInt32 {
+		return fmt.Errorf("sort weight out of range: %d", weight)
+	}
+	return nil
+}
+
+// layoutWeights возвращает веса для -sort_weight. Веса применяются в порядке пресет, правила,
+// ручные веса записей, а затем сжимаются до весов каталогов, где это возможно.
+func layoutWeights(project *models.Project) []sortWeight {
+	var weights []sortWeight
+
+	var files []models.FileEntry
+	for _, e := range project.Entries {
+		if !e.IsDir && e.SourcePath != "" {
+			files = append(files, e)
+		}
+	}
+	// Пресет делит файлы на группы с общим весом, иначе каждому файлу нужна своя команда:
+	// small-first — по степени двойки размера, by-path — по каталогу в порядке путей
+	var group func(e models.FileEntry) string
+	switch project.ISOOptions.SortPreset {
+	case models.SortPresetSmallFirst:
+		slices.SortStableFunc(files, func(a, b models.FileEntry) int {
+			return cmp.Or(cmp.Compare(a.Size, b.Size), cmp.Compare(a.DestPath, b.DestPath))
+		})
+		group = func(e models.FileEntry) string { return strconv.Itoa(bits.Len64(uint64(max(e.Size, 0)))) }
+	case models.SortPresetByPath:
+		slices.SortFunc(files, func(a, b models.FileEntry) int { return cmp.Compare(a.DestPath, b.DestPath) })
+		group = func(e models.FileEntry) string { return path.Dir(e.DestPath) }
+	default:
+		files = nil
+	}
+	// Первая группа получает вес 0 — значение по умолчанию, команды для неё не нужны
+	ranks := make(map[string]int)
+	for _, e := range files {
+		g := group(e)
+		rank, ok := ranks[g]
+		if !ok {
+			rank = len(ranks)
+			ranks[g] = rank
+		}
+		if rank > 0 {
+			weights = append(weights, sortWeight{path: e.DestPath, weight: -rank})
+		}
+	}
+
+	byDepth := slices.Clone(project.Entries)
+	slices.SortStableFunc(byDepth, func(a, b models.FileEntry) int {
+		return cmp.Compare(strings.Count(a.DestPath, "/"), strings.Count(b.DestPath, "/"))
+	})
+
+	for _, r := range project.SortRules {
+		var matched []string
+		for _, e := range byDepth {
+			if !globMatches(r.Pattern, e.DestPath) {
+				continue
+			}
+			// Вес каталога уже распространяется на вложенные записи
+			if slices.ContainsFunc(matched, func(dir string) bool { return isUnderPath(e.DestPath, dir) }) {
+				continue
+			}
+			matched = append(matched, e.DestPath)
+			weights = append(weights, sortWeight{path: e.DestPath, weight: r.Weight})
+		}
+	}
+
+	for _, e := range byDepth {
+		if e.SortWeight != 0 {
+			weights = append(weights, sortWeight{path: e.DestPath, weight: e.SortWeight})
+		}
+	}
+	return compactWeights(project.Entries, weights)
+}
+
+// compactWeights вычисляет итоговый вес каждого файла и выражает те же веса меньшим числом
+// команд: вес каталога распространяется на всё поддерево, поэтому каталог, большая часть
+// файлов которого имеет общий вес, получает одну команду вместо команды на каждый файл.
+// Так длина командной строки не растёт с числом файлов с одинаковым весом.
+func compactWeights(entries []models.FileEntry, weights []sortWeight) []sortWeight {
+	if len(weights) == 0 {
+		return nil
+	}
+	last := make(map[string]int, len(weights))
+	for i, w := range weights {
+		last[path.Clean(w.path)] = i
+	}
+
+	// Листья — файлы и каталоги, перенесённые целиком: их содержимого нет среди записей
+	leaves := make(map[string]int)
+	children := make(map[string][]string)
+	counts := make(map[string]map[int]int)
+	for _, e := range entries {
+		if e.SourcePath == "" || e.IsDir && e.Listed {
+			continue
+		}
+		leaf := path.Clean(e.DestPath)
+		if _, ok := leaves[leaf]; ok {
+			continue
+		}
+		// итоговый вес — от последней команды для самого пути или его предка
+		weight, applied := 0, -1
+		for p := leaf; ; p = path.Dir(p) {
+			if i, ok := last[p]; ok && i > applied {
+				weight, applied = weights[i].weight, i
+			}
+			if p == "/" {
+				break
+			}
+		}
+		leaves[leaf] = weight
+
+		for p := leaf; p != "/"; p = path.Dir(p) {
+			parent := path.Dir(p)
+			if counts[parent] == nil {
+				counts[parent] = make(map[int]int)
+				if parent != "/" {
+					children[path.Dir(parent)] = append(children[path.Dir(parent)], parent)
+				}
+			}
+			counts[parent][weight]++
+			if p == leaf {
+				children[parent] = append(children[parent], leaf)
+			}
+		}
+	}
+
+	var compact []sortWeight
+	var walk func(dir string, inherited int)
+	walk = func(dir string, inherited int) {
+		slices.Sort(children[dir])
+		for _, p := range slices.Compact(children[dir]) {
+			weight := inherited
+			if w, ok := leaves[p]; ok {
+				weight = w
+			} else if w, n := commonWeight(counts[p]); n > counts[p][inherited] {
+				// команда каталога заменяет n команд файлов, но файлам с унаследованным
+				// весом теперь нужны свои
+				weight = w
+			}
+			if weight != inherited {
+				compact = append(compact, sortWeight{path: p, weight: weight})
+			}
+			walk(p, weight)
+		}
+	}
+	walk("/", 0)
+	return compact
+}
+
+// commonWeight возвращает самый частый вес и число файлов с ним; при равенстве — меньший вес
+func commonWeight(counts map[int]int) (int, int) {
+	weight, best := 0, 0
+	for _, w := range slices.Sorted(maps.Keys(counts)) {
+		if counts[w] > best {
+			weight, best = w, counts[w]
+		}
+	}
+	return weight, best
+}
+
+// globMatches сравнивает шаблон с полным путём, если в нём есть '/', иначе с именем
+func globMatches(pattern, p string) bool {
+	target := path.Base(p)
+	if strings.Contains(pattern, "/") {
+		target = p
+	}
+	ok, _ := path.Match(pattern, target)
+	return ok
+}
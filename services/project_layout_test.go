@@ -0,0 +1,157 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"xorriso-ui/pkg/models"
+)
+
+func newLayoutTestProject() *models.Project {
+	return &models.Project{
+		ID: "p1",
+		Entries: []models.FileEntry{
+			{SourcePath: "/src/video", DestPath: "/video", IsDir: true, Listed: true},
+			{SourcePath: "/src/video/movie.mkv", DestPath: "/video/movie.mkv", Size: 4 << 30},
+			{SourcePath: "/src/video/index.txt", DestPath: "/video/index.txt", Size: 100},
+			{SourcePath: "/src/autorun.inf", DestPath: "/autorun.inf", Size: 50},
+			{SourcePath: "/src/setup.exe", DestPath: "/setup.exe", Size: 1 << 20},
+		},
+	}
+}
+
+func TestLayoutWeights_Presets(t *testing.T) {
+	tests := []struct {
+		preset string
+		want   []sortWeight
+	}{
+		{models.SortPresetManual, nil},
+		{"", nil},
+		// группы по степени двойки размера: 50 байт, 100 байт, 1 MiB, 4 GiB
+		{models.SortPresetSmallFirst, []sortWeight{
+			{"/setup.exe", -2}, {"/video", -3}, {"/video/index.txt", -1},
+		}},
+		// группы по каталогам: файлы корня, затем /video
+		{models.SortPresetByPath, []sortWeight{{"/video", -1}}},
+	}
+	for _, tt := range tests {
+		project := newLayoutTestProject()
+		project.ISOOptions.SortPreset = tt.preset
+		if got := layoutWeights(project); !slices.Equal(got, tt.want) {
+			t.Errorf("%q: weights = %v, want %v", tt.preset, got, tt.want)
+		}
+	}
+}
+
+func TestLayoutWeights_RulesAndManual(t *testing.T) {
+	svc := NewProjectService()
+	project := newLayoutTestProject()
+
+	rules := []models.SortRule{
+		{Pattern: "*.inf", Weight: 100},
+		{Pattern: "/video*", Weight: 10},
+	}
+	if _, err := svc.SetSortRules(project, rules); err != nil {
+		t.Fatalf("SetSortRules: %v", err)
+	}
+	if _, err := svc.SetSortWeight(project, []string{"/video/index.txt"}, 50); err != nil {
+		t.Fatalf("SetSortWeight: %v", err)
+	}
+
+	want := []sortWeight{
+		{"/autorun.inf", 100},
+		{"/video", 10}, // вложенные файлы не повторяются
+		{"/video/index.txt", 50},
+	}
+	if got := layoutWeights(project); !slices.Equal(got, want) {
+		t.Errorf("weights = %v, want %v", got, want)
+	}
+
+	// Правила отменяются отдельно от ручного веса
+	if _, err := svc.Undo(project); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Undo(project); err != nil {
+		t.Fatal(err)
+	}
+	if len(project.SortRules) != 0 || len(layoutWeights(project)) != 0 {
+		t.Errorf("undo should clear layout, got rules %v weights %v", project.SortRules, layoutWeights(project))
+	}
+	if _, err := svc.Redo(project); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(project.SortRules, rules) {
+		t.Errorf("redo: rules = %v, want %v", project.SortRules, rules)
+	}
+}
+
+func TestLayoutWeights_Compact(t *testing.T) {
+	project := &models.Project{
+		ISOOptions: models.ISOOptions{SortPreset: models.SortPresetByPath},
+		Entries: []models.FileEntry{
+			{SourcePath: "/src/docs", DestPath: "/docs", IsDir: true, Listed: true},
+			{SourcePath: "/src/photos", DestPath: "/photos", IsDir: true}, // перенесена целиком
+			{SourcePath: "/src/readme.txt", DestPath: "/readme.txt"},
+		},
+		SortRules: []models.SortRule{{Pattern: "*.txt", Weight: 5}},
+	}
+	for i := range 500 {
+		name := fmt.Sprintf("/docs/%03d.txt", i)
+		project.Entries = append(project.Entries, models.FileEntry{SourcePath: "/src" + name, DestPath: name})
+	}
+	project.Entries = append(project.Entries, models.FileEntry{SourcePath: "/src/docs/index.html", DestPath: "/docs/index.html"})
+
+	// пятьсот файлов *.txt получают одну команду на каталог, а index.html возвращается
+	// вес пресета: /docs идёт первым в порядке путей
+	want := []sortWeight{{"/docs", 5}, {"/docs/index.html", 0}, {"/readme.txt", 5}}
+	if got := layoutWeights(project); !slices.Equal(got, want) {
+		t.Errorf("weights = %v, want %v", got, want)
+	}
+
+	// вес каталога, перенесённого целиком, нельзя заменить весами файлов
+	project.SortRules = []models.SortRule{{Pattern: "/photos", Weight: 7}}
+	project.ISOOptions.SortPreset = models.SortPresetManual
+	if got := layoutWeights(project); !slices.Equal(got, []sortWeight{{"/photos", 7}}) {
+		t.Errorf("whole folder weights = %v", got)
+	}
+}
+
+func TestSetSortRules_Invalid(t *testing.T) {
+	svc := NewProjectService()
+	project := newLayoutTestProject()
+
+	for _, rules := range [][]models.SortRule{
+		{{Pattern: "", Weight: 1}},
+		{{Pattern: "[a-", Weight: 1}},
+		{{Pattern: "*.inf", Weight: 1 << 40}},
+	} {
+		if _, err := svc.SetSortRules(project, rules); err == nil {
+			t.Errorf("SetSortRules(%v): expected error", rules)
+		}
+	}
+	if _, err := svc.SetSortWeight(project, []string{"/missing"}, 1); err == nil {
+		t.Error("SetSortWeight on a missing entry: expected error")
+	}
+}
+
+func TestGetBurnCommand_SortWeights(t *testing.T) {
+	svc := NewBurnService(&mockRunner{})
+	project := newLayoutTestProject()
+	project.ISOOptions.SortPreset = models.SortPresetSmallFirst
+	project.Entries[3].SortWeight = 1000
+
+	cmdline, err := svc.GetBurnCommand(project, "/dev/sr0", models.BurnOptions{Speed: "auto", BurnMode: "auto"})
+	if err != nil {
+		t.Fatalf("GetBurnCommand: %v", err)
+	}
+	for _, want := range []string{"-sort_weight -1 /video/index.txt", "-sort_weight 1000 /autorun.inf"} {
+		if !strings.Contains(cmdline, want) {
+			t.Errorf("expected %q in %s", want, cmdline)
+		}
+	}
+	if strings.Index(cmdline, "-sort_weight") < strings.Index(cmdline, "-map /src/setup.exe") {
+		t.Errorf("sort weights must follow the file mappings: %s", cmdline)
+	}
+}
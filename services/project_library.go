@@ -0,0 +1,260 @@
+package services
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"xorriso-ui/pkg/models"
+)
+
+// Сколько незакреплённых проектов хранит список недавних
+const libraryMaxRecent = 50
+
+// LibraryEntry is a project file remembered in the project library
+type LibraryEntry struct {
+	Path           string    `json:"path"`
+	Name           string    `json:"name"`
+	VolumeID       string    `json:"volumeId"`
+	Size           int64     `json:"size"` // объём данных проекта
+	Files          int       `json:"files"`
+	LastOpened     time.Time `json:"lastOpened"`
+	Pinned         bool      `json:"pinned"`
+	Missing        bool      `json:"missing"`        // файл проекта не найден; только у закреплённых
+	MissingSources int       `json:"missingSources"` // источники, не найденные при последнем открытии или сохранении
+	LastBurn       *LastBurn `json:"lastBurn,omitempty"`
+}
+
+// LastBurn is the outcome of the last burn or ISO creation of a library project
+type LastBurn struct {
+	Time    time.Time `json:"time"`
+	Target  string    `json:"target"` // устройство или путь к ISO-файлу
+	Success bool      `json:"success"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// Библиотеку изменяют и ProjectService, и BurnService
+var libraryMu sync.Mutex
+
+func libraryPath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		configDir = os.TempDir()
+	}
+	return filepath.Join(configDir, "xorriso-ui", "library.json")
+}
+
+// loadLibrary читает библиотеку; повреждённый или отсутствующий файл — пустая библиотека
+func loadLibrary() []LibraryEntry {
+	library := []LibraryEntry{}
+	data, err := os.ReadFile(libraryPath())
+	if err != nil {
+		return library
+	}
+	if err := json.Unmarshal(data, &library); err != nil || library == nil {
+		return []LibraryEntry{}
+	}
+	return library
+}
+
+func saveLibrary(library []LibraryEntry) error {
+	if err := os.MkdirAll(filepath.Dir(libraryPath()), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(library, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(libraryPath(), data, 0644)
+}
+
+// rememberProject добавляет сохранённый проект в начало списка недавних.
+// Ошибки библиотеки не мешают открытию и сохранению проекта.
+func rememberProject(project *models.Project) {
+	if project.FilePath == "" {
+		return
+	}
+	path, err := filepath.Abs(project.FilePath)
+	if err != nil {
+		return
+	}
+	missing := 0
+	for _, e := range project.Entries {
+		if e.SourcePath == "" {
+			continue
+		}
+		if _, err := os.Lstat(e.SourcePath); err != nil {
+			missing++
+		}
+	}
+
+	libraryMu.Lock()
+	defer libraryMu.Unlock()
+	library := loadLibrary()
+	entry := LibraryEntry{Path: path}
+	if i := slices.IndexFunc(library, func(e LibraryEntry) bool { return e.Path == path }); i >= 0 {
+		entry = library[i]
+		library = slices.Delete(library, i, i+1)
+	}
+	entry.Name = project.Name
+	entry.VolumeID = project.VolumeID
+	entry.Size = entriesDataSize(project.Entries)
+	entry.Files = countFiles(project.Entries)
+	entry.LastOpened = time.Now()
+	entry.Missing = false
+	entry.MissingSources = missing
+	library = append([]LibraryEntry{entry}, library...)
+
+	// Самые старые незакреплённые проекты вытесняются из списка
+	recent := 0
+	library = slices.DeleteFunc(library, func(e LibraryEntry) bool {
+		if e.Pinned {
+			return false
+		}
+		recent++
+		return recent > libraryMaxRecent
+	})
+	_ = saveLibrary(library)
+}
+
+// recordLibraryBurn запоминает результат записи проекта из библиотеки
+func recordLibraryBurn(filePath string, burn LastBurn) {
+	if filePath == "" {
+		return
+	}
+	path, err := filepath.Abs(filePath)
+	if err != nil {
+		return
+	}
+	libraryMu.Lock()
+	defer libraryMu.Unlock()
+	library := loadLibrary()
+	i := slices.IndexFunc(library, func(e LibraryEntry) bool { return e.Path == path })
+	if i < 0 {
+		return
+	}
+	library[i].LastBurn = &burn
+	_ = saveLibrary(library)
+}
+
+func countFiles(entries []models.FileEntry) int {
+	n := 0
+	for _, e := range entries {
+		if !e.IsDir {
+			n++
+		}
+	}
+	return n
+}
+
+// ListRecentProjects returns the project library: pinned projects first, then the
+// most recently opened. Projects whose files were deleted are dropped from the
+// library, pinned ones are kept and marked missing.
+func (s *ProjectService) ListRecentProjects() ([]LibraryEntry, error) {
+	libraryMu.Lock()
+	defer libraryMu.Unlock()
+
+	library := loadLibrary()
+	kept := make([]LibraryEntry, 0, len(library))
+	changed := false
+	for _, e := range library {
+		_, err := os.Stat(e.Path)
+		missing := err != nil
+		if missing && !e.Pinned {
+			changed = true
+			continue
+		}
+		if missing != e.Missing {
+			e.Missing = missing
+			changed = true
+		}
+		kept = append(kept, e)
+	}
+	if changed {
+		if err := saveLibrary(kept); err != nil {
+			return nil, err
+		}
+	}
+	library = kept
+
+	slices.SortStableFunc(library, func(a, b LibraryEntry) int {
+		if a.Pinned != b.Pinned {
+			if a.Pinned {
+				return -1
+			}
+			return 1
+		}
+		return b.LastOpened.Compare(a.LastOpened)
+	})
+	return library, nil
+}
+
+// SearchLibrary returns library projects whose name, volume ID or path contains
+// the query, ignoring case
+func (s *ProjectService) SearchLibrary(query string) ([]LibraryEntry, error) {
+	library, err := s.ListRecentProjects()
+	if err != nil {
+		return nil, err
+	}
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return library, nil
+	}
+	return slices.DeleteFunc(library, func(e LibraryEntry) bool {
+		return !strings.Contains(strings.ToLower(e.Name), query) &&
+			!strings.Contains(strings.ToLower(e.VolumeID), query) &&
+			!strings.Contains(strings.ToLower(e.Path), query)
+	}), nil
+}
+
+// PinProject pins or unpins a library project. Pinned projects stay at the top of
+// the list and are kept even when their file disappears.
+func (s *ProjectService) PinProject(filePath string, pinned bool) error {
+	return changeLibraryEntry(filePath, func(library []LibraryEntry, i int) []LibraryEntry {
+		library[i].Pinned = pinned
+		return library
+	})
+}
+
+// ForgetProject removes a project from the library; the project file is not touched
+func (s *ProjectService) ForgetProject(filePath string) error {
+	return changeLibraryEntry(filePath, func(library []LibraryEntry, i int) []LibraryEntry {
+		return slices.Delete(library, i, i+1)
+	})
+}
+
+func changeLibraryEntry(filePath string, change func(library []LibraryEntry, i int) []LibraryEntry) error {
+	path, err := filepath.Abs(filePath)
+	if err != nil {
+		return err
+	}
+	libraryMu.Lock()
+	defer libraryMu.Unlock()
+
+	library := loadLibrary()
+	i := slices.IndexFunc(library, func(e LibraryEntry) bool { return e.Path == path })
+	if i < 0 {
+		return fmt.Errorf("project not in library: %s", filePath)
+	}
+	return saveLibrary(change(library, i))
+}
+
+// lastBurnOf описывает завершённую задачу записи для библиотеки
+func lastBurnOf(job *models.BurnJob, target string) LastBurn {
+	burn := LastBurn{
+		Time:    cmp.Or(job.FinishedAt, time.Now()),
+		Target:  target,
+		Success: job.State == models.BurnStateDone,
+		Error:   job.Error,
+	}
+	if job.State == models.BurnStateCancelled {
+		burn.Error = cmp.Or(burn.Error, "cancelled")
+	}
+	return burn
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"xorriso-ui/pkg/models"
+)
+
+// saveLibraryProject сохраняет проект с одним источником и возвращает путь к файлу проекта
+func saveLibraryProject(t *testing.T, svc *ProjectService, dir, name string) (*models.Project, string) {
+	t.Helper()
+	src := filepath.Join(dir, name+".txt")
+	os.WriteFile(src, []byte("data"), 0644)
+	project := svc.NewProject(name, name+"_VOL")
+	project.Entries = []models.FileEntry{{SourcePath: src, DestPath: "/" + name + ".txt", Name: name + ".txt", Size: 4}}
+	path := filepath.Join(dir, name+".xorriso-project")
+	if err := svc.SaveProjectAs(project, path); err != nil {
+		t.Fatalf("SaveProjectAs: %v", err)
+	}
+	return project, path
+}
+
+func TestLibrary_RecentOrderAndCleanup(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+	svc := NewProjectService()
+
+	_, first := saveLibraryProject(t, svc, dir, "first")
+	_, second := saveLibraryProject(t, svc, dir, "second")
+	if _, err := svc.OpenProject(first); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := svc.ListRecentProjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Path != first || list[1].Path != second {
+		t.Fatalf("ListRecentProjects = %+v", list)
+	}
+	if e := list[0]; e.Name != "first" || e.VolumeID != "first_VOL" || e.Size != 4 || e.Files != 1 || e.MissingSources != 0 {
+		t.Errorf("entry = %+v", e)
+	}
+
+	// Закреплённый проект остаётся наверху и в библиотеке после удаления файла
+	if err := svc.PinProject(second, true); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(first)
+	os.Remove(second)
+	list, err = svc.ListRecentProjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Path != second || !list[0].Pinned || !list[0].Missing {
+		t.Fatalf("after deletion = %+v", list)
+	}
+
+	if err := svc.ForgetProject(second); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := svc.ListRecentProjects(); len(list) != 0 {
+		t.Errorf("ForgetProject left %+v", list)
+	}
+	if err := svc.PinProject(second, true); err == nil {
+		t.Error("PinProject must fail for a project outside the library")
+	}
+}
+
+func TestLibrary_MissingSourcesAndSearch(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+	svc := NewProjectService()
+
+	saveLibraryProject(t, svc, dir, "Holidays")
+	_, archive := saveLibraryProject(t, svc, dir, "Archive")
+	os.Remove(filepath.Join(dir, "Archive.txt"))
+	if _, err := svc.OpenProject(archive); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := svc.SearchLibrary("archive_vol")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Name != "Archive" || found[0].MissingSources != 1 {
+		t.Fatalf("SearchLibrary = %+v", found)
+	}
+	if all, _ := svc.SearchLibrary("  "); len(all) != 2 {
+		t.Errorf("empty query must return the whole library, got %d", len(all))
+	}
+}
+
+func TestLibrary_LastBurn(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+	svc := NewProjectService()
+	project, path := saveLibraryProject(t, svc, dir, "burned")
+
+	burn := NewBurnService(&mockRunner{})
+	burn.emitEvent = noopEmit
+	burn.currentJob = &models.BurnJob{ID: "job", State: models.BurnStateError, Error: "write failed"}
+	burn.rememberBurn(project, "/dev/sr0", "job")
+
+	list, err := svc.ListRecentProjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Path != path || list[0].LastBurn == nil {
+		t.Fatalf("ListRecentProjects = %+v", list)
+	}
+	if lb := list[0].LastBurn; lb.Success || lb.Target != "/dev/sr0" || lb.Error != "write failed" {
+		t.Errorf("LastBurn = %+v", lb)
+	}
+
+	// Проекты вне библиотеки не добавляются записью
+	burn.rememberBurn(&models.Project{FilePath: filepath.Join(dir, "other.xorriso-project")}, "/dev/sr0", "job")
+	if list, _ := svc.ListRecentProjects(); len(list) != 1 {
+		t.Errorf("burn of unknown project changed library: %+v", list)
+	}
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+
+	"xorriso-ui/pkg/models"
+)
+
+// SkippedSource is a source file left out of the project by the link or special file policy
+type SkippedSource struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+const (
+	skipReasonLink        = "symbolic link"
+	skipReasonBrokenLink  = "broken symbolic link"
+	skipReasonOutsideLink = "symbolic link points outside the project"
+	skipReasonLinkLoop    = "symbolic link loop"
+	skipReasonSpecial     = "special file"
+	skipReasonExcluded    = "excluded"
+)
+
+// sourceWalker добавляет источники в проект с учётом политики ссылок и специальных файлов
+type sourceWalker struct {
+	project *models.Project
+	links   string
+	special string
+	exclude []string
+	roots   []string        // каталоги проекта, куда могут вести ссылки при reject-outside
+	walking map[string]bool // реальные пути каталогов текущей ветви обхода
+	skipped []SkippedSource
+}
+
+func newSourceWalker(project *models.Project, src string) *sourceWalker {
+	w := &sourceWalker{
+		project: project,
+		links:   project.ISOOptions.LinkPolicy,
+		special: project.ISOOptions.SpecialFiles,
+		exclude: project.Exclude,
+		walking: make(map[string]bool),
+	}
+	if w.links == models.LinkPolicyRejectOutside {
+		w.roots = append(w.roots, realPath(src))
+		for _, e := range project.Entries {
+			if e.IsDir && e.SourcePath != "" {
+				w.roots = append(w.roots, realPath(e.SourcePath))
+			}
+		}
+	}
+	return w
+}
+
+// add добавляет запись для src и, если recursive, для всего его содержимого
+func (w *sourceWalker) add(src, destPath string, recursive bool) error {
+	entry, dir, reason, err := w.entryFor(src, destPath)
+	if err != nil {
+		return err
+	}
+	if reason != "" {
+		w.skip(src, reason)
+		return nil
+	}
+	entry.Name = filepath.Base(destPath)
+	idx := len(w.project.Entries)
+	w.project.Entries = append(w.project.Entries, entry)
+	if dir != "" {
+		w.project.Entries[idx].Size = w.walkDir(dir, destPath, recursive)
+	}
+	return nil
+}
+
+// walkDir обходит каталог и возвращает объём данных в нём.
+// При record=false записи не создаются — только считается размер.
+func (w *sourceWalker) walkDir(dir, destPath string, record bool) int64 {
+	real := realPath(dir)
+	w.walking[real] = true
+	defer delete(w.walking, real)
+
+	items, err := os.ReadDir(dir)
+	if err != nil {
+		return 0
+	}
+	var total int64
+	for _, item := range items {
+		src := filepath.Join(dir, item.Name())
+		dest := filepath.Join(destPath, item.Name())
+		// Шаблоны исключения действуют на содержимое папок, а не на выбранные источники
+		if matchesAny(w.exclude, src) {
+			if record {
+				w.skip(src, skipReasonExcluded)
+			}
+			continue
+		}
+		entry, sub, reason, err := w.entryFor(src, dest)
+		if err != nil {
+			continue
+		}
+		if sub != "" && w.walking[realPath(sub)] {
+			// Ссылка на один из каталогов выше по дереву
+			reason = skipReasonLinkLoop
+		}
+		if reason != "" {
+			if record {
+				w.skip(src, reason)
+			}
+			continue
+		}
+
+		idx := len(w.project.Entries)
+		if record {
+			w.project.Entries = append(w.project.Entries, entry)
+		}
+		size := entry.Size
+		if sub != "" {
+			size = w.walkDir(sub, dest, record)
+			if record {
+				w.project.Entries[idx].Size = size
+			}
+		}
+		total += size
+	}
+	return total
+}
+
+// entryFor описывает источник по политике проекта. dir — каталог, содержимое которого
+// нужно обойти, reason — причина пропуска источника.
+func (w *sourceWalker) entryFor(src, destPath string) (entry models.FileEntry, dir, reason string, err error) {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return entry, "", "", err
+	}
+	entry = models.FileEntry{
+		SourcePath: src,
+		DestPath:   destPath,
+		Name:       info.Name(),
+		ModTime:    info.ModTime().UnixMilli(),
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		switch w.links {
+		case models.LinkPolicySkip:
+			return entry, "", skipReasonLink, nil
+		case models.LinkPolicyFollow:
+			target, err := filepath.EvalSymlinks(src)
+			if err != nil {
+				return entry, "", skipReasonBrokenLink, nil
+			}
+			if info, err = os.Stat(target); err != nil {
+				return entry, "", skipReasonBrokenLink, nil
+			}
+			// Источником становится цель ссылки — её содержимое и попадёт в образ
+			entry.SourcePath = target
+			entry.ModTime = info.ModTime().UnixMilli()
+		case models.LinkPolicyRejectOutside:
+			if !w.insideProject(src) {
+				return entry, "", skipReasonOutsideLink, nil
+			}
+			entry.Type = models.EntryTypeSymlink
+			return entry, "", "", nil
+		default:
+			entry.Type = models.EntryTypeSymlink
+			return entry, "", "", nil
+		}
+	}
+
+	switch {
+	case info.IsDir():
+		entry.IsDir = true
+		// Содержимое добавляется отдельными записями, при нерекурсивном добавлении — никакое
+		entry.Listed = true
+		return entry, entry.SourcePath, "", nil
+	case info.Mode().IsRegular():
+		entry.Size = info.Size()
+		return entry, "", "", nil
+	case w.special == models.SpecialFilesSkip:
+		return entry, "", skipReasonSpecial, nil
+	default:
+		entry.Type = models.EntryTypeSpecial
+		return entry, "", "", nil
+	}
+}
+
+// insideProject сообщает, ведёт ли ссылка внутрь одного из каталогов проекта
+func (w *sourceWalker) insideProject(link string) bool {
+	target, err := filepath.EvalSymlinks(link)
+	if err != nil {
+		return false
+	}
+	for _, root := range w.roots {
+		if isUnderPath(target, root) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *sourceWalker) skip(src, reason string) {
+	w.skipped = append(w.skipped, SkippedSource{Path: src, Reason: reason})
+}
+
+// matchesAny сравнивает путь с шаблонами: шаблон со знаком '/' — с полным путём, иначе с именем
+func matchesAny(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		if globMatches(pattern, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// realPath раскрывает ссылки в пути; для недоступного пути возвращает его как есть
+func realPath(p string) string {
+	if real, err := filepath.EvalSymlinks(p); err == nil {
+		return real
+	}
+	return filepath.Clean(p)
+}
+
+// followOccasions — аргумент -follow для политики ссылок. Записи проекта уже
+// отражают политику, поэтому xorriso не должен сам раскрывать ссылки-аргументы -map.
+// При следовании по ссылкам раскрываются только ссылки внутри каталогов, которые
+// переносятся целиком.
+func followOccasions(policy string) string {
+	if policy == models.LinkPolicyFollow {
+		return "default:link"
+	}
+	return "default"
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"xorriso-ui/pkg/models"
+)
+
+// newLinkTree создаёт дерево с вложенными ссылками, петлёй и FIFO:
+//
+//	tree/data.txt, tree/sub/inner.txt
+//	tree/inside -> data.txt, tree/outside -> ../external.txt
+//	tree/subdir -> sub, tree/loop -> ., tree/broken -> missing, tree/pipe (FIFO)
+func newLinkTree(t *testing.T) string {
+	t.Helper()
+	base := t.TempDir()
+	tree := filepath.Join(base, "tree")
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(os.MkdirAll(filepath.Join(tree, "sub"), 0755))
+	must(os.WriteFile(filepath.Join(tree, "data.txt"), []byte("12345"), 0644))
+	must(os.WriteFile(filepath.Join(tree, "sub", "inner.txt"), []byte("abc"), 0644))
+	must(os.WriteFile(filepath.Join(base, "external.txt"), []byte("0123456789"), 0644))
+	must(os.Symlink("data.txt", filepath.Join(tree, "inside")))
+	must(os.Symlink("../external.txt", filepath.Join(tree, "outside")))
+	must(os.Symlink("sub", filepath.Join(tree, "subdir")))
+	must(os.Symlink(".", filepath.Join(tree, "loop")))
+	must(os.Symlink("missing", filepath.Join(tree, "broken")))
+	must(syscall.Mkfifo(filepath.Join(tree, "pipe"), 0644))
+	return tree
+}
+
+func addWithPolicy(t *testing.T, tree, links, special string) (*models.Project, []SkippedSource) {
+	t.Helper()
+	svc := NewProjectService()
+	var skipped []SkippedSource
+	svc.emitEvent = func(name string, data ...any) {
+		if name == models.EventSourcesSkipped {
+			skipped = data[0].([]SkippedSource)
+		}
+	}
+	project := svc.NewProject("links", "LINKS")
+	project.ISOOptions.LinkPolicy = links
+	project.ISOOptions.SpecialFiles = special
+	if _, err := svc.AddFiles(project, []string{tree}, "/"); err != nil {
+		t.Fatalf("AddFiles: %v", err)
+	}
+	return project, skipped
+}
+
+func entryTypes(project *models.Project) map[string]string {
+	types := make(map[string]string, len(project.Entries))
+	for _, e := range project.Entries {
+		types[e.DestPath] = e.Type
+	}
+	return types
+}
+
+func TestAddFiles_LinkPolicyKeep(t *testing.T) {
+	tree := newLinkTree(t)
+	project, skipped := addWithPolicy(t, tree, "", "")
+
+	types := entryTypes(project)
+	for _, p := range []string{"/tree/inside", "/tree/outside", "/tree/subdir", "/tree/loop", "/tree/broken"} {
+		if types[p] != models.EntryTypeSymlink {
+			t.Errorf("%s: type = %q, want symlink", p, types[p])
+		}
+	}
+	if types["/tree/pipe"] != models.EntryTypeSpecial {
+		t.Errorf("pipe: type = %q, want special", types["/tree/pipe"])
+	}
+	if _, ok := types["/tree/subdir/inner.txt"]; ok {
+		t.Error("kept directory link must not be descended into")
+	}
+	if len(skipped) != 0 {
+		t.Errorf("nothing should be skipped, got %v", skipped)
+	}
+
+	size, _ := NewProjectService().CalculateSize(project)
+	if size != 8 {
+		t.Errorf("CalculateSize = %d, want 8 (links and FIFO carry no data)", size)
+	}
+}
+
+func TestAddFiles_LinkPolicyFollow(t *testing.T) {
+	tree := newLinkTree(t)
+	project, skipped := addWithPolicy(t, tree, models.LinkPolicyFollow, models.SpecialFilesSkip)
+
+	sources := destPaths(project)
+	real, _ := filepath.EvalSymlinks(tree)
+	if sources["/tree/inside"] != filepath.Join(real, "data.txt") {
+		t.Errorf("inside: source = %q, want the link target", sources["/tree/inside"])
+	}
+	if _, ok := sources["/tree/subdir/inner.txt"]; !ok {
+		t.Error("followed directory link should be descended into")
+	}
+	if _, ok := sources["/tree/pipe"]; ok {
+		t.Error("FIFO should be skipped")
+	}
+	for _, e := range project.Entries {
+		if e.Type != "" {
+			t.Errorf("%s: followed entries must not keep type %q", e.DestPath, e.Type)
+		}
+	}
+
+	reasons := make(map[string]string)
+	for _, s := range skipped {
+		reasons[filepath.Base(s.Path)] = s.Reason
+	}
+	for name, want := range map[string]string{
+		"broken": skipReasonBrokenLink,
+		"pipe":   skipReasonSpecial,
+		"loop":   skipReasonLinkLoop,
+	} {
+		if reasons[name] != want {
+			t.Errorf("%s: reason = %q, want %q (all: %v)", name, reasons[name], want, skipped)
+		}
+	}
+}
+
+func TestAddFiles_LinkPolicySkipAndRejectOutside(t *testing.T) {
+	tree := newLinkTree(t)
+
+	project, skipped := addWithPolicy(t, tree, models.LinkPolicySkip, "")
+	for p, typ := range entryTypes(project) {
+		if typ == models.EntryTypeSymlink {
+			t.Errorf("%s: links should be skipped", p)
+		}
+	}
+	if len(skipped) != 5 {
+		t.Errorf("expected 5 skipped links, got %v", skipped)
+	}
+
+	project, skipped = addWithPolicy(t, tree, models.LinkPolicyRejectOutside, "")
+	types := entryTypes(project)
+	if types["/tree/inside"] != models.EntryTypeSymlink || types["/tree/subdir"] != models.EntryTypeSymlink {
+		t.Errorf("links inside the project should be kept: %v", types)
+	}
+	if _, ok := types["/tree/outside"]; ok {
+		t.Error("link outside the project should be rejected")
+	}
+	if _, ok := types["/tree/broken"]; ok {
+		t.Error("broken link should be rejected")
+	}
+	if !hasSkipped(skipped, "outside", skipReasonOutsideLink) {
+		t.Errorf("expected outside link to be reported, got %v", skipped)
+	}
+}
+
+func hasSkipped(list []SkippedSource, name, reason string) bool {
+	for _, s := range list {
+		if filepath.Base(s.Path) == name && s.Reason == reason {
+			return true
+		}
+	}
+	return false
+}
+
+func TestBuildISOCommand_Follow(t *testing.T) {
+	svc := NewBurnService(&mockRunner{})
+	for policy, want := range map[string]string{
+		"":                             "default",
+		models.LinkPolicySkip:          "default",
+		models.LinkPolicyRejectOutside: "default",
+		models.LinkPolicyFollow:        "default:link",
+	} {
+		project := &models.Project{
+			Entries:    []models.FileEntry{{SourcePath: "/src/a", DestPath: "/a"}},
+			ISOOptions: models.ISOOptions{LinkPolicy: policy},
+		}
+		cmdline, err := svc.GetBurnCommand(project, "/dev/sr0", models.BurnOptions{Speed: "auto", BurnMode: "auto"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(cmdline, "-follow "+want+" ") {
+			t.Errorf("%q: expected -follow %s in %s", policy, want, cmdline)
+		}
+	}
+}
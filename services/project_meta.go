@@ -0,0 +1,70 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"xorriso-ui/pkg/models"
+)
+
+var (
+	octalModeRe    = regexp.MustCompile(`^[0-7]{3,4}$`)
+	symbolicModeRe = regexp.MustCompile(`^[ugoa]*[-+=][rwxXst]*(,[ugoa]*[-+=][rwxXst]*)*$`)
+	ownerNameRe    = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*\$?$`)
+)
+
+// SetEntryMetadata replaces the metadata overrides of the given entries.
+// An empty EntryMetadata removes all overrides.
+func (s *ProjectService) SetEntryMetadata(project *models.Project, destPaths []string, meta models.EntryMetadata) (*models.Project, error) {
+	if err := validateEntryMetadata(meta); err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool, len(project.Entries))
+	for _, e := range project.Entries {
+		existing[e.DestPath] = true
+	}
+	targets := make(map[string]bool, len(destPaths))
+	for _, p := range destPaths {
+		if !existing[p] {
+			return nil, fmt.Errorf("entry not found: %s", p)
+		}
+		targets[p] = true
+	}
+
+	label := "Change attributes"
+	if meta == (models.EntryMetadata{}) {
+		label = "Reset attributes"
+	}
+	err := s.recordEdit(project, label, func() error {
+		for i, e := range project.Entries {
+			if targets[e.DestPath] {
+				project.Entries[i].Meta = meta
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	project.UpdatedAt = time.Now()
+	return project, nil
+}
+
+// validateEntryMetadata проверяет значения, которые попадут в аргументы xorriso
+func validateEntryMetadata(m models.EntryMetadata) error {
+	if m.Mode != "" && !octalModeRe.MatchString(m.Mode) && !symbolicModeRe.MatchString(m.Mode) {
+		return fmt.Errorf("invalid mode: %s", m.Mode)
+	}
+	if m.Owner != "" && !ownerNameRe.MatchString(m.Owner) {
+		return fmt.Errorf("invalid owner: %s", m.Owner)
+	}
+	if m.Group != "" && !ownerNameRe.MatchString(m.Group) {
+		return fmt.Errorf("invalid group: %s", m.Group)
+	}
+	if m.ATime < 0 || m.MTime < 0 || m.CTime < 0 {
+		return fmt.Errorf("timestamps before 1970 are not supported")
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"testing"
+
+	"xorriso-ui/pkg/models"
+)
+
+func TestSetEntryMetadata(t *testing.T) {
+	svc := NewProjectService()
+	project := newEditTestProject()
+	project.ID = "p1"
+	meta := models.EntryMetadata{Mode: "0644", Owner: "root", Group: "0", MTime: 1700000000000, HideISO: true}
+
+	dest := project.Entries[0].DestPath
+	if _, err := svc.SetEntryMetadata(project, []string{dest}, meta); err != nil {
+		t.Fatalf("SetEntryMetadata: %v", err)
+	}
+	if project.Entries[0].Meta != meta {
+		t.Errorf("Meta = %+v, want %+v", project.Entries[0].Meta, meta)
+	}
+
+	if _, err := svc.Undo(project); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if project.Entries[0].Meta != (models.EntryMetadata{}) {
+		t.Errorf("undo should clear metadata, got %+v", project.Entries[0].Meta)
+	}
+}
+
+func TestSetEntryMetadata_Invalid(t *testing.T) {
+	svc := NewProjectService()
+	project := newEditTestProject()
+	dest := project.Entries[0].DestPath
+
+	tests := []struct {
+		name  string
+		paths []string
+		meta  models.EntryMetadata
+	}{
+		{"bad mode", []string{dest}, models.EntryMetadata{Mode: "999"}},
+		{"mode with spaces", []string{dest}, models.EntryMetadata{Mode: "u+x -- /etc"}},
+		{"bad owner", []string{dest}, models.EntryMetadata{Owner: "-root"}},
+		{"negative time", []string{dest}, models.EntryMetadata{ATime: -1}},
+		{"missing entry", []string{"/nonexistent"}, models.EntryMetadata{Mode: "0644"}},
+	}
+	for _, tt := range tests {
+		if _, err := svc.SetEntryMetadata(project, tt.paths, tt.meta); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+	for _, mode := range []string{"0755", "go-w", "u=rwX,go=rX", "a+t"} {
+		if err := validateEntryMetadata(models.EntryMetadata{Mode: mode}); err != nil {
+			t.Errorf("mode %q should be valid: %v", mode, err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+
+	"xorriso-ui/pkg/models"
+)
+
+// projectDoc — сырой JSON проекта, над которым работают миграции
+type projectDoc map[string]json.RawMessage
+
+// projectMigration upgrades a project document from version `from` to `from+1`
+type projectMigration struct {
+	from    uint8
+	migrate func(doc projectDoc) error
+}
+
+// projectMigrations — миграции формата по порядку версий.
+// Новая версия формата = новая запись в конце списка.
+var projectMigrations = []projectMigration{
+	{from: 0, migrate: migrateProjectV0},
+	{from: 1, migrate: migrateProjectV1},
+	{from: 2, migrate: migrateProjectV2},
+}
+
+// migrateProject detects the format version of a project file and upgrades it
+// to models.ProjectFormatVersion. Returns the migrated JSON and the original version.
+func migrateProject(data []byte) ([]byte, uint8, error) {
+	var doc projectDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, 0, fmt.Errorf("invalid project file: %w", err)
+	}
+
+	version, err := projectDocVersion(doc)
+	if err != nil {
+		return nil, 0, err
+	}
+	if version > models.ProjectFormatVersion {
+		return nil, version, fmt.Errorf("project file version %d is newer than supported version %d, please update xorriso-ui",
+			version, models.ProjectFormatVersion)
+	}
+	if version == models.ProjectFormatVersion {
+		return data, version, nil
+	}
+
+	for _, m := range projectMigrations {
+		if m.from < version {
+			continue
+		}
+		if err := m.migrate(doc); err != nil {
+			return nil, version, fmt.Errorf("migrate project from version %d: %w", m.from, err)
+		}
+		next, _ := json.Marshal(m.from + 1)
+		doc["version"] = next
+	}
+
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return nil, version, err
+	}
+	return migrated, version, nil
+}
+
+// projectDocVersion returns the format version of a raw project; a missing field means version 0
+func projectDocVersion(doc projectDoc) (uint8, error) {
+	raw, ok := doc["version"]
+	if !ok || string(raw) == "null" {
+		return 0, nil
+	}
+	var version uint8
+	if err := json.Unmarshal(raw, &version); err != nil {
+		return 0, fmt.Errorf("invalid project version: %s", raw)
+	}
+	return version, nil
+}
+
+// backupProjectFile keeps the original file before it gets overwritten in the new format.
+// An existing backup of the same version is not replaced.
+func backupProjectFile(filePath string, data []byte, version uint8) error {
+	backupPath := fmt.Sprintf("%s.v%d.bak", filePath, version)
+	if _, err := os.Stat(backupPath); err == nil {
+		return nil
+	}
+	if err := os.WriteFile(backupPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to back up project file: %w", err)
+	}
+	return nil
+}
+
+// migrateProjectV0 — файлы до введения версионирования: поле version отсутствует,
+// entries мог сохраняться как null у пустого проекта
+func migrateProjectV0(doc projectDoc) error {
+	if raw, ok := doc["entries"]; !ok || string(raw) == "null" {
+		doc["entries"] = json.RawMessage("[]")
+	}
+	return nil
+}
+
+// migrateProjectV1 — версия 2 только добавляет поля (базовый образ, imagePath, meta и
+// sortWeight записей, sortRules, поля заголовка тома и другие), их отсутствие означает
+// прежнее поведение. Новая версия нужна, чтобы старые сборки не открывали файл: они
+// превратили бы узлы образа в пустые папки и молча потеряли остальные поля при сохранении.
+func migrateProjectV1(doc projectDoc) error {
+	return nil
+}
+
+// migrateProjectV2 — до версии 3 способ переноса папки выводился из её дочерних записей:
+// папка с детьми переносилась без содержимого, без детей — целиком. Теперь это поле
+// listed, его значение для старых файлов восстанавливается тем же правилом.
+// Добавленное в той же версии removed пусто у старых файлов.
+func migrateProjectV2(doc projectDoc) error {
+	raw, ok := doc["entries"]
+	if !ok || string(raw) == "null" {
+		return nil
+	}
+	var entries []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return fmt.Errorf("invalid entries: %w", err)
+	}
+	parents := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		var dest string
+		_ = json.Unmarshal(e["destPath"], &dest)
+		parents[path.Dir(dest)] = true
+	}
+	for _, e := range entries {
+		var isDir bool
+		var dest, src string
+		_ = json.Unmarshal(e["isDir"], &isDir)
+		_ = json.Unmarshal(e["destPath"], &dest)
+		_ = json.Unmarshal(e["sourcePath"], &src)
+		// У виртуальной папки нет источника — её содержимое всегда отдельные записи
+		if isDir && (parents[dest] || src == "") {
+			e["listed"] = json.RawMessage("true")
+		}
+	}
+	raw, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	doc["entries"] = raw
+	return nil
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"xorriso-ui/pkg/models"
+)
+
+// copyGoldenProject copies a golden project file into a temp dir so backups do not land in testdata
+func copyGoldenProject(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "projects", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// normalizeOpened clears fields that depend on where and when the file was opened
+func normalizeOpened(p *models.Project) models.Project {
+	n := *p
+	n.ID = ""
+	n.FilePath = ""
+	return n
+}
+
+func TestOpenProject_GoldenVersions(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	svc := NewProjectService()
+
+	current, err := svc.OpenProject(copyGoldenProject(t, "v3.xorriso-project"))
+	if err != nil {
+		t.Fatalf("open current golden: %v", err)
+	}
+	if current.Version != models.ProjectFormatVersion {
+		t.Fatalf("golden v%d is not the current format version %d", current.Version, models.ProjectFormatVersion)
+	}
+
+	// Каждая историческая версия должна открываться в тот же проект, что и текущая
+	for _, name := range []string{"v0.xorriso-project", "v1.xorriso-project", "v2.xorriso-project"} {
+		t.Run(name, func(t *testing.T) {
+			project, err := svc.OpenProject(copyGoldenProject(t, name))
+			if err != nil {
+				t.Fatalf("OpenProject: %v", err)
+			}
+			if got, want := normalizeOpened(project), normalizeOpened(current); !reflect.DeepEqual(got, want) {
+				t.Errorf("migrated project differs\n  got:  %+v\n  want: %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestOpenProject_V0NullEntries(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	svc := NewProjectService()
+	project, err := svc.OpenProject(copyGoldenProject(t, "v0-empty.xorriso-project"))
+	if err != nil {
+		t.Fatalf("OpenProject: %v", err)
+	}
+	if project.Entries == nil {
+		t.Error("Entries should be an empty slice after migration")
+	}
+	if project.Version != models.ProjectFormatVersion {
+		t.Errorf("Version = %d, want %d", project.Version, models.ProjectFormatVersion)
+	}
+}
+
+func TestOpenProject_BackupOnMigration(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	svc := NewProjectService()
+	path := copyGoldenProject(t, "v0.xorriso-project")
+	original, _ := os.ReadFile(path)
+
+	if _, err := svc.OpenProject(path); err != nil {
+		t.Fatal(err)
+	}
+
+	backup, err := os.ReadFile(path + ".v0.bak")
+	if err != nil {
+		t.Fatalf("backup not created: %v", err)
+	}
+	if string(backup) != string(original) {
+		t.Error("backup content differs from original file")
+	}
+}
+
+func TestOpenProject_NoBackupForCurrentVersion(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	svc := NewProjectService()
+	path := copyGoldenProject(t, "v3.xorriso-project")
+
+	if _, err := svc.OpenProject(path); err != nil {
+		t.Fatal(err)
+	}
+	matches, _ := filepath.Glob(path + ".*.bak")
+	if len(matches) != 0 {
+		t.Errorf("unexpected backups: %v", matches)
+	}
+}
+
+func TestOpenProject_TooNew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "future.xorriso-project")
+	os.WriteFile(path, []byte(`{"version": 200, "name": "Future"}`), 0644)
+
+	svc := NewProjectService()
+	_, err := svc.OpenProject(path)
+	if err == nil {
+		t.Fatal("expected error for newer project version")
+	}
+	if !strings.Contains(err.Error(), "newer than supported") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestOpenProject_InvalidVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.xorriso-project")
+	os.WriteFile(path, []byte(`{"version": "one"}`), 0644)
+
+	svc := NewProjectService()
+	if _, err := svc.OpenProject(path); err == nil {
+		t.Fatal("expected error for invalid version field")
+	}
+}
+
+func TestProjectMigrations_Ordered(t *testing.T) {
+	// Миграции должны покрывать все версии от 0 до текущей без пропусков
+	if len(projectMigrations) != int(models.ProjectFormatVersion) {
+		t.Fatalf("migrations = %d, want %d", len(projectMigrations), models.ProjectFormatVersion)
+	}
+	for i, m := range projectMigrations {
+		if m.from != uint8(i) {
+			t.Errorf("migration %d has from=%d, want %d", i, m.from, i)
+		}
+	}
+}
+
+func TestOpenProject_V2Fields(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	svc := NewProjectService()
+	path := copyGoldenProject(t, "v2-full.xorriso-project")
+
+	project, err := svc.OpenProject(path)
+	if err != nil {
+		t.Fatalf("OpenProject: %v", err)
+	}
+	if project.BaseImage == nil || *project.BaseImage != (models.BaseImage{Path: "/home/user/rescue.iso", KeepBoot: true}) {
+		t.Errorf("BaseImage = %+v", project.BaseImage)
+	}
+	if project.Entries[1].ImagePath != "/boot/vmlinuz" || project.Entries[2].ImagePath != "" {
+		t.Errorf("entries = %+v", project.Entries)
+	}
+	notes := project.Entries[2]
+	if notes.Meta != (models.EntryMetadata{Mode: "0600", MTime: 1700000000000, HideJoliet: true}) || notes.SortWeight != 50 {
+		t.Errorf("notes entry = %+v", notes)
+	}
+	if project.Entries[3].Type != models.EntryTypeSymlink {
+		t.Errorf("link entry = %+v", project.Entries[3])
+	}
+	if !reflect.DeepEqual(project.SortRules, []models.SortRule{{Pattern: "*.txt", Weight: 10}}) ||
+		!reflect.DeepEqual(project.Exclude, []string{"*.tmp"}) {
+		t.Errorf("SortRules = %+v, Exclude = %v", project.SortRules, project.Exclude)
+	}
+	wantISO := models.ISOOptions{
+		ISOLevel: 3, RockRidge: true, Joliet: true, MD5: true,
+		Hardlinks: true, SplitSize: 4293918720, SortPreset: "small-first",
+		LinkPolicy: "keep", SpecialFiles: "skip", PublisherID: "ACME",
+		VolumeSetID: "ARCHIVES", PreparerID: "Backup team", ApplicationID: "My Tool 1.0", SystemID: "GNU",
+		AbstractFile: "ABSTRACT.TXT", BiblioFile: "BIBLIO.TXT", CopyrightFile: "COPYING",
+		ExpirationDate: 1900000000000, EffectiveDate: 1700000000000, VolumeUUID: "2026101812000000",
+	}
+	if project.ISOOptions != wantISO {
+		t.Errorf("ISOOptions = %+v, want %+v", project.ISOOptions, wantISO)
+	}
+
+	// Сохранение не теряет поля версии 2
+	if err := svc.SaveProject(project); err != nil {
+		t.Fatalf("SaveProject: %v", err)
+	}
+	reopened, err := svc.OpenProject(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	reopened.UpdatedAt = project.UpdatedAt
+	if got, want := normalizeOpened(reopened), normalizeOpened(project); !reflect.DeepEqual(got, want) {
+		t.Errorf("saved project differs\n  got:  %+v\n  want: %+v", got, want)
+	}
+	data, _ := os.ReadFile(path)
+	if want := fmt.Sprintf(`"version": %d`, models.ProjectFormatVersion); !strings.Contains(string(data), want) {
+		t.Errorf("saved file is not the current version:\n%s", data)
+	}
+}
+
+func TestMigrateProjectV2_Listed(t *testing.T) {
+	data := []byte(`{"version": 2, "entries": [
+		{"sourcePath": "/src/docs", "destPath": "/docs", "isDir": true},
+		{"sourcePath": "/src/docs/a", "destPath": "/docs/a", "isDir": false},
+		{"sourcePath": "/src/photos", "destPath": "/photos", "isDir": true},
+		{"sourcePath": "", "destPath": "/empty", "isDir": true}
+	]}`)
+	migrated, _, err := migrateProject(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var project models.Project
+	if err := json.Unmarshal(migrated, &project); err != nil {
+		t.Fatal(err)
+	}
+	// Прежнее правило: папка с дочерними записями переносится без содержимого, без них — целиком
+	want := map[string]bool{"/docs": true, "/docs/a": false, "/photos": false, "/empty": true}
+	for _, e := range project.Entries {
+		if e.Listed != want[e.DestPath] {
+			t.Errorf("%s: Listed = %v, want %v", e.DestPath, e.Listed, want[e.DestPath])
+		}
+	}
+}
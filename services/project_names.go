@@ -0,0 +1,263 @@
+package services
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"xorriso-ui/pkg/models"
+	"xorriso-ui/pkg/xorriso"
+)
+
+const (
+	NamespaceISO9660   = "iso9660"
+	NamespaceJoliet    = "joliet"
+	NamespaceRockRidge = "rockridge"
+	NamespaceHFSPlus   = "hfsplus"
+)
+
+const (
+	NameIssueChanged     = "changed"
+	NameIssueCollision   = "collision"
+	NameIssueTooLong     = "too_long"
+	NameIssueTooDeep     = "too_deep"
+	NameIssuePathTooLong = "path_too_long"
+	NameIssueCharset     = "charset" // идентификатор заголовка тома вне наборов символов ECMA-119
+)
+
+const (
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+// NameIssue describes one name that will not appear in a namespace as it is in the project
+type NameIssue struct {
+	Path          string `json:"path"` // путь в образе
+	Namespace     string `json:"namespace"`
+	Kind          string `json:"kind"`
+	Severity      string `json:"severity"`
+	Mapped        string `json:"mapped,omitempty"`        // имя, которое получится в образе
+	SuggestedName string `json:"suggestedName,omitempty"` // переименование, которое устраняет проблему
+	Message       string `json:"message"`
+}
+
+// NameReport is the result of CheckNames
+type NameReport struct {
+	Issues      []NameIssue `json:"issues"`
+	Suggestions []string    `json:"suggestions"` // изменения ISO-опций
+}
+
+// CheckNames simulates how file names are mapped to every namespace enabled in
+// the project's ISO options and reports names that will be changed, collide
+// or exceed depth and length limits. Volume descriptor IDs with characters
+// outside the ECMA-119 sets are reported too, with an empty Path.
+func (s *ProjectService) CheckNames(project *models.Project) *NameReport {
+	c := &nameChecker{opts: project.ISOOptions, report: &NameReport{Issues: []NameIssue{}, Suggestions: []string{}}}
+	c.opts.ISOLevel = isoLevel(c.opts)
+
+	children, isDir := isoTree(project.Entries)
+	dirs := make([]string, 0, len(children))
+	for dir := range children {
+		dirs = append(dirs, dir)
+	}
+	slices.Sort(dirs)
+	for _, dir := range dirs {
+		c.checkDir(children[dir], isDir)
+	}
+
+	for _, issue := range volumeCharsetIssues(project.VolumeID, project.ISOOptions) {
+		c.add(issue)
+	}
+	c.addSuggestions()
+	return c.report
+}
+
+// isoTree группирует пути образа по родительским каталогам, включая неявные каталоги
+func isoTree(entries []models.FileEntry) (map[string][]string, map[string]bool) {
+	children := make(map[string][]string)
+	isDir := make(map[string]bool)
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		p := path.Clean("/" + e.DestPath)
+		isDir[p] = isDir[p] || e.IsDir
+		for p != "/" && !seen[p] {
+			seen[p] = true
+			parent := path.Dir(p)
+			children[parent] = append(children[parent], p)
+			if parent != "/" {
+				isDir[parent] = true
+			}
+			p = parent
+		}
+	}
+	for _, c := range children {
+		slices.Sort(c)
+	}
+	return children, isDir
+}
+
+type nameChecker struct {
+	opts   models.ISOOptions
+	report *NameReport
+
+	isoChanged    bool
+	jolietTooLong bool
+	isoTooDeep    bool
+}
+
+func (c *nameChecker) add(issue NameIssue) {
+	c.report.Issues = append(c.report.Issues, issue)
+}
+
+func (c *nameChecker) checkDir(paths []string, isDir map[string]bool) {
+	iso := make(map[string][]string)
+	joliet := make(map[string][]string)
+	hfs := make(map[string][]string)
+
+	for _, p := range paths {
+		name := path.Base(p)
+		mapped := xorriso.ISO9660Name(name, c.opts.ISOLevel, isDir[p])
+		iso[mapped] = append(iso[mapped], p)
+		// Регистр в ISO 9660 меняется всегда, сообщаем только о потере символов
+		if !strings.EqualFold(mapped, name) {
+			c.isoChanged = true
+			c.add(NameIssue{Path: p, Namespace: NamespaceISO9660, Kind: NameIssueChanged, Severity: c.isoSeverity(),
+				Mapped: mapped, Message: fmt.Sprintf("ISO 9660 level %d name will be %s", c.opts.ISOLevel, mapped)})
+		}
+
+		if c.opts.Joliet {
+			jname := xorriso.JolietName(name, isDir[p])
+			joliet[jname] = append(joliet[jname], p)
+			if jname != name {
+				issue := NameIssue{Path: p, Namespace: NamespaceJoliet, Kind: NameIssueChanged, Severity: SeverityWarning,
+					Mapped: jname, Message: fmt.Sprintf("Joliet name will be %s", jname)}
+				if xorriso.UTF16Len(name) > xorriso.JolietMaxNameLen {
+					c.jolietTooLong = true
+					issue.Kind = NameIssueTooLong
+					issue.SuggestedName = xorriso.TruncateName(name, xorriso.JolietMaxNameLen, isDir[p])
+					issue.Message = fmt.Sprintf("name is longer than %d characters, Joliet name will be %s", xorriso.JolietMaxNameLen, jname)
+				}
+				c.add(issue)
+			}
+		}
+
+		if c.opts.RockRidge && len(name) > xorriso.RockRidgeMaxNameLen {
+			c.add(NameIssue{Path: p, Namespace: NamespaceRockRidge, Kind: NameIssueTooLong, Severity: SeverityError,
+				SuggestedName: truncateBytes(name, xorriso.RockRidgeMaxNameLen),
+				Message:       fmt.Sprintf("name is longer than %d bytes", xorriso.RockRidgeMaxNameLen)})
+		}
+
+		if c.opts.HFSPlus {
+			key := strings.ToLower(name) // HFS+ не различает регистр
+			hfs[key] = append(hfs[key], p)
+			if xorriso.UTF16Len(name) > xorriso.HFSPlusMaxNameLen {
+				c.add(NameIssue{Path: p, Namespace: NamespaceHFSPlus, Kind: NameIssueTooLong, Severity: SeverityError,
+					SuggestedName: xorriso.TruncateName(name, xorriso.HFSPlusMaxNameLen, isDir[p]),
+					Message:       fmt.Sprintf("name is longer than %d characters", xorriso.HFSPlusMaxNameLen)})
+			}
+		}
+
+		c.checkPath(p)
+	}
+
+	c.addCollisions(NamespaceISO9660, c.isoSeverity(), iso, paths)
+	c.addCollisions(NamespaceJoliet, SeverityWarning, joliet, paths)
+	c.addCollisions(NamespaceHFSPlus, SeverityWarning, hfs, paths)
+}
+
+// checkPath проверяет глубину и длину полного пути
+func (c *nameChecker) checkPath(p string) {
+	depth := strings.Count(p, "/")
+	if c.opts.ISOLevel < 4 && depth > xorriso.ISO9660MaxDepth {
+		c.isoTooDeep = true
+		c.add(NameIssue{Path: p, Namespace: NamespaceISO9660, Kind: NameIssueTooDeep, Severity: c.isoSeverity(),
+			Message: fmt.Sprintf("path is %d levels deep, ISO 9660 allows %d", depth, xorriso.ISO9660MaxDepth)})
+	}
+	if c.opts.ISOLevel < 4 && len(p) > xorriso.ISO9660MaxPathLen {
+		c.isoTooDeep = true
+		c.add(NameIssue{Path: p, Namespace: NamespaceISO9660, Kind: NameIssuePathTooLong, Severity: c.isoSeverity(),
+			Message: fmt.Sprintf("path is longer than %d characters", xorriso.ISO9660MaxPathLen)})
+	}
+	if c.opts.Joliet && xorriso.UTF16Len(p) > xorriso.JolietMaxPathLen {
+		c.add(NameIssue{Path: p, Namespace: NamespaceJoliet, Kind: NameIssuePathTooLong, Severity: SeverityError,
+			Message: fmt.Sprintf("path is longer than %d characters, Joliet tree cannot hold it", xorriso.JolietMaxPathLen)})
+	}
+}
+
+// addCollisions сообщает о разных именах, совпавших после преобразования.
+// Для ISO 9660 переименование не предлагается — libisofs сам делает имена уникальными.
+func (c *nameChecker) addCollisions(namespace, severity string, mapped map[string][]string, paths []string) {
+	used := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		used[path.Base(p)] = true
+	}
+	names := make([]string, 0, len(mapped))
+	for name, group := range mapped {
+		if len(group) > 1 {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		group := mapped[name]
+		for _, other := range group[1:] {
+			issue := NameIssue{Path: other, Namespace: namespace, Kind: NameIssueCollision, Severity: severity,
+				Mapped: name, Message: fmt.Sprintf("name collides with %s as %s", path.Base(group[0]), name)}
+			if namespace != NamespaceISO9660 {
+				issue.SuggestedName = uniqueName(path.Base(other), used)
+			}
+			c.add(issue)
+		}
+	}
+}
+
+// isoSeverity — имена ISO 9660 видны только если нет Rock Ridge и Joliet
+func (c *nameChecker) isoSeverity() string {
+	if c.opts.RockRidge || c.opts.Joliet {
+		return SeverityInfo
+	}
+	return SeverityWarning
+}
+
+func (c *nameChecker) addSuggestions() {
+	r := c.report
+	if c.isoChanged && !c.opts.RockRidge && !c.opts.Joliet {
+		r.Suggestions = append(r.Suggestions, "Enable Rock Ridge and Joliet to keep original file names on Linux and Windows")
+	}
+	if c.isoTooDeep {
+		r.Suggestions = append(r.Suggestions, "Use ISO level 4 (ISO 9660:1999) to lift directory depth and path length limits")
+	}
+	if c.jolietTooLong && !c.opts.UDF {
+		r.Suggestions = append(r.Suggestions, "Enable UDF to keep names longer than 64 characters for Windows readers")
+	}
+}
+
+// uniqueName добавляет к имени номер " (N)" перед расширением, пока оно не станет свободным
+func uniqueName(name string, used map[string]bool) string {
+	base, ext := name, ""
+	if dot := strings.LastIndexByte(name, '.'); dot > 0 {
+		base, ext = name[:dot], name[dot:]
+	}
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, n, ext)
+		if !used[candidate] {
+			used[candidate] = true
+			return candidate
+		}
+	}
+}
+
+// truncateBytes обрезает строку до n байт, не разрывая символы UTF-8
+func truncateBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
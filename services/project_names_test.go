@@ -0,0 +1,110 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"xorriso-ui/pkg/models"
+)
+
+func findIssue(report *NameReport, path, namespace, kind string) *NameIssue {
+	for i, issue := range report.Issues {
+		if issue.Path == path && issue.Namespace == namespace && issue.Kind == kind {
+			return &report.Issues[i]
+		}
+	}
+	return nil
+}
+
+func TestCheckNames_JolietAndCollisions(t *testing.T) {
+	longName := strings.Repeat("n", 70) + ".txt"
+	project := &models.Project{
+		ISOOptions: models.ISOOptions{ISOLevel: 1, RockRidge: true, Joliet: true},
+		Entries: []models.FileEntry{
+			{SourcePath: "/src/a", DestPath: "/docs", IsDir: true, Listed: true},
+			{SourcePath: "/src/a/1", DestPath: "/docs/holiday-2024.jpg"},
+			{SourcePath: "/src/a/2", DestPath: "/docs/holiday-2023.jpg"},
+			{SourcePath: "/src/a/3", DestPath: "/docs/" + longName},
+			{SourcePath: "/src/a/4", DestPath: "/docs/a:b.txt"},
+			{SourcePath: "/src/a/5", DestPath: "/docs/a;b.txt"},
+		},
+	}
+
+	svc := NewProjectService()
+	report := svc.CheckNames(project)
+
+	iso := findIssue(report, "/docs/holiday-2024.jpg", NamespaceISO9660, NameIssueCollision)
+	if iso == nil || iso.Mapped != "HOLIDAY_.JPG" || iso.Severity != SeverityInfo {
+		t.Errorf("expected ISO collision with info severity, got %+v", iso)
+	}
+
+	joliet := findIssue(report, "/docs/"+longName, NamespaceJoliet, NameIssueTooLong)
+	if joliet == nil || len([]rune(joliet.SuggestedName)) != 64 {
+		t.Errorf("expected Joliet too_long with a 64-char suggestion, got %+v", joliet)
+	}
+
+	collision := findIssue(report, "/docs/a;b.txt", NamespaceJoliet, NameIssueCollision)
+	if collision == nil || collision.Mapped != "a_b.txt" || collision.SuggestedName != "a;b (2).txt" {
+		t.Errorf("expected Joliet collision, got %+v", collision)
+	}
+
+	if !hasWarning(report.Suggestions, "UDF") {
+		t.Errorf("expected UDF suggestion, got %v", report.Suggestions)
+	}
+}
+
+func TestCheckNames_DepthAndPlainISO(t *testing.T) {
+	deep := "/a/b/c/d/e/f/g/h/i/file.txt"
+	project := &models.Project{
+		ISOOptions: models.ISOOptions{ISOLevel: 2},
+		Entries:    []models.FileEntry{{SourcePath: "/src/file.txt", DestPath: deep}},
+	}
+
+	svc := NewProjectService()
+	report := svc.CheckNames(project)
+
+	issue := findIssue(report, deep, NamespaceISO9660, NameIssueTooDeep)
+	if issue == nil || issue.Severity != SeverityWarning {
+		t.Errorf("expected too_deep warning for %s, got %+v", deep, report.Issues)
+	}
+	if findIssue(report, "/a/b/c/d/e/f/g/h", NamespaceISO9660, NameIssueTooDeep) != nil {
+		t.Error("directory at depth 8 should be allowed")
+	}
+	if !hasWarning(report.Suggestions, "ISO level 4") {
+		t.Errorf("expected ISO level 4 suggestion, got %v", report.Suggestions)
+	}
+
+	project.ISOOptions.ISOLevel = 4
+	if report := svc.CheckNames(project); len(report.Issues) != 0 {
+		t.Errorf("ISO level 4 should accept the tree, got %+v", report.Issues)
+	}
+}
+
+func TestCheckNames_HFSPlusCase(t *testing.T) {
+	project := &models.Project{
+		ISOOptions: models.ISOOptions{ISOLevel: 4, RockRidge: true, HFSPlus: true},
+		Entries: []models.FileEntry{
+			{SourcePath: "/src/1", DestPath: "/Readme.md"},
+			{SourcePath: "/src/2", DestPath: "/README.md"},
+		},
+	}
+
+	svc := NewProjectService()
+	report := svc.CheckNames(project)
+	if findIssue(report, "/Readme.md", NamespaceHFSPlus, NameIssueCollision) == nil {
+		t.Errorf("expected case-insensitive HFS+ collision, got %+v", report.Issues)
+	}
+}
+
+func TestCheckNames_VolumeCharset(t *testing.T) {
+	project := &models.Project{
+		VolumeID:   "backup",
+		ISOOptions: models.ISOOptions{RockRidge: true, Joliet: true},
+		Entries:    []models.FileEntry{{SourcePath: "/src/a.txt", DestPath: "/a.txt"}},
+	}
+	report := NewProjectService().CheckNames(project)
+	issue := findIssue(report, "", NamespaceISO9660, NameIssueCharset)
+	if issue == nil || issue.SuggestedName != "BACKUP" || issue.Severity != SeverityWarning {
+		t.Errorf("expected volume ID charset warning, got %+v", report.Issues)
+	}
+}
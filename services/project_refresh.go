@@ -0,0 +1,372 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"xorriso-ui/pkg/inotify"
+	"xorriso-ui/pkg/models"
+)
+
+const (
+	SourceAdded    = "added"
+	SourceRemoved  = "removed"
+	SourceModified = "modified"
+)
+
+const (
+	// Пауза без событий, после которой отправляется EventSourcesChanged
+	sourceWatchDebounce = 500 * time.Millisecond
+	// Ограничение числа наблюдаемых каталогов одного проекта — inotify watches не бесконечны
+	sourceWatchMaxDirs = 8192
+	// Сколько изменённых путей передаётся в одном событии
+	sourceWatchMaxPaths = 100
+)
+
+// SourceChange is a difference between a project entry and its source on disk
+type SourceChange struct {
+	Kind       string `json:"kind"`
+	DestPath   string `json:"destPath"`
+	SourcePath string `json:"sourcePath"`
+	OldSize    int64  `json:"oldSize"`
+	NewSize    int64  `json:"newSize"`
+}
+
+// SourceReport lists the sources that changed since they were added to the project.
+// Project is set only by RefreshSources.
+type SourceReport struct {
+	Project *models.Project `json:"project,omitempty"`
+	Changes []SourceChange  `json:"changes"`
+	Checked int             `json:"checked"` // проверено записей с источником
+}
+
+// sourceDiff — найденные изменения и данные для их применения
+type sourceDiff struct {
+	report  SourceReport
+	added   []models.FileEntry
+	fresh   map[string]models.FileEntry // DestPath → запись с новыми размером и временем
+	removed map[string]bool
+}
+
+// CheckSources re-stats the sources of all project entries and reports files that
+// were removed or modified, and files added to folders whose contents are listed
+// in the project entry by entry. The project is not changed.
+func (s *ProjectService) CheckSources(project *models.Project) *SourceReport {
+	diff := diffSources(project)
+	return &diff.report
+}
+
+// RefreshSources updates the project entries to match their sources: removed
+// files are dropped, sizes and dates of modified files are updated and new files
+// in folders are added. The refresh is a single undoable edit.
+func (s *ProjectService) RefreshSources(project *models.Project) (*SourceReport, error) {
+	diff := diffSources(project)
+	if len(diff.report.Changes) > 0 {
+		err := s.recordEdit(project, "Refresh sources", func() error {
+			entries := slices.DeleteFunc(slices.Clone(project.Entries), func(e models.FileEntry) bool {
+				return diff.removed[e.DestPath]
+			})
+			for i, e := range entries {
+				if f, ok := diff.fresh[e.DestPath]; ok {
+					entries[i].Size = f.Size
+					entries[i].ModTime = f.ModTime
+				}
+			}
+			project.Entries = append(entries, diff.added...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		project.UpdatedAt = time.Now()
+	}
+	diff.report.Project = project
+	return &diff.report, nil
+}
+
+func diffSources(project *models.Project) *sourceDiff {
+	diff := &sourceDiff{
+		report:  SourceReport{Changes: []SourceChange{}},
+		fresh:   make(map[string]models.FileEntry),
+		removed: make(map[string]bool),
+	}
+	existing := make(map[string]bool, len(project.Entries))
+	used := make(map[string]bool, len(project.Entries))
+	for _, e := range project.Entries {
+		existing[e.DestPath] = true
+		used[e.SourcePath] = true
+	}
+	removed := make(map[string]bool, len(project.Removed))
+	for _, src := range project.Removed {
+		removed[src] = true
+	}
+
+	// Содержимое каталогов с отдельными записями заново обходится по политике проекта
+	scanned := scanSourceDirs(project)
+
+	for _, e := range project.Entries {
+		if e.SourcePath == "" {
+			continue
+		}
+		diff.report.Checked++
+		info, err := os.Lstat(e.SourcePath)
+		if err != nil {
+			diff.removed[e.DestPath] = true
+			diff.report.Changes = append(diff.report.Changes, SourceChange{
+				Kind: SourceRemoved, DestPath: e.DestPath, SourcePath: e.SourcePath, OldSize: e.Size,
+			})
+			continue
+		}
+
+		fresh := e
+		switch {
+		case e.Type != "":
+			// Для ссылок и специальных файлов важно только наличие
+			continue
+		case e.IsDir && e.Listed:
+			if scan, ok := scanned[e.DestPath]; ok {
+				fresh.Size = scan.Size
+			}
+		case e.IsDir:
+			// Каталог переносится целиком — новые файлы попадут в образ сами, меняется только объём
+			fresh.Size = newSourceWalker(project, e.SourcePath).walkDir(e.SourcePath, e.DestPath, false)
+		default:
+			fresh.Size = info.Size()
+			fresh.ModTime = info.ModTime().UnixMilli()
+		}
+		if fresh.Size != e.Size || fresh.ModTime != e.ModTime {
+			diff.fresh[e.DestPath] = fresh
+			diff.report.Changes = append(diff.report.Changes, SourceChange{
+				Kind: SourceModified, DestPath: e.DestPath, SourcePath: e.SourcePath, OldSize: e.Size, NewSize: fresh.Size,
+			})
+		}
+	}
+
+	for _, dest := range slices.Sorted(maps.Keys(scanned)) {
+		e := scanned[dest]
+		// Переименованный или перенесённый файл остаётся в проекте под другим путём,
+		// а убранный пользователем — вместе с содержимым, если это папка
+		if existing[dest] || used[e.SourcePath] || underRemoved(e.SourcePath, removed) {
+			continue
+		}
+		diff.added = append(diff.added, e)
+		diff.report.Changes = append(diff.report.Changes, SourceChange{
+			Kind: SourceAdded, DestPath: e.DestPath, SourcePath: e.SourcePath, NewSize: e.Size,
+		})
+	}
+
+	slices.SortStableFunc(diff.report.Changes, func(a, b SourceChange) int { return cmp.Compare(a.DestPath, b.DestPath) })
+	return diff
+}
+
+// underRemoved сообщает, убран ли из проекта источник или одна из папок над ним
+func underRemoved(src string, removed map[string]bool) bool {
+	for p := src; p != "/" && p != "."; p = filepath.Dir(p) {
+		if removed[p] {
+			return true
+		}
+	}
+	return false
+}
+
+// rememberRemoved запоминает в project.Removed источники убранных записей, которые
+// обход папок проекта при обновлении нашёл бы снова. Источники, вернувшиеся в проект
+// или оказавшиеся вне его папок, забываются.
+func rememberRemoved(project *models.Project, dropped []models.FileEntry) {
+	roots := make(map[string]bool)
+	used := make(map[string]bool, len(project.Entries))
+	for _, e := range project.Entries {
+		if e.IsDir && e.Listed && e.Type == "" && e.SourcePath != "" {
+			roots[e.SourcePath] = true
+		}
+		used[e.SourcePath] = true
+	}
+	inRoots := func(src string) bool {
+		for p := filepath.Dir(src); p != "/" && p != "."; p = filepath.Dir(p) {
+			if roots[p] {
+				return true
+			}
+		}
+		return false
+	}
+
+	var kept []string
+	seen := make(map[string]bool)
+	for _, src := range project.Removed {
+		if !seen[src] && !used[src] && inRoots(src) {
+			seen[src] = true
+			kept = append(kept, src)
+		}
+	}
+	for _, e := range dropped {
+		if e.SourcePath != "" && !seen[e.SourcePath] && !used[e.SourcePath] && inRoots(e.SourcePath) {
+			seen[e.SourcePath] = true
+			kept = append(kept, e.SourcePath)
+		}
+	}
+	project.Removed = kept
+}
+
+// scanSourceDirs обходит каталоги-источники, содержимое которых перечислено в проекте
+// по записям, и возвращает найденные записи по DestPath
+func scanSourceDirs(project *models.Project) map[string]models.FileEntry {
+	var roots []models.FileEntry
+	for _, e := range project.Entries {
+		if e.IsDir && e.Type == "" && e.SourcePath != "" && e.Listed {
+			roots = append(roots, e)
+		}
+	}
+	slices.SortStableFunc(roots, func(a, b models.FileEntry) int {
+		return cmp.Compare(strings.Count(a.DestPath, "/"), strings.Count(b.DestPath, "/"))
+	})
+
+	scratch := &models.Project{}
+	var walked []string
+	for _, root := range roots {
+		// Вложенный каталог уже обойдён вместе с родителем
+		if slices.ContainsFunc(walked, func(dir string) bool { return isUnderPath(root.DestPath, dir) }) {
+			continue
+		}
+		if _, err := os.Stat(root.SourcePath); err != nil {
+			continue
+		}
+		walked = append(walked, root.DestPath)
+		// Политика и корни для reject-outside — от проекта, записи — в отдельный список
+		w := newSourceWalker(project, root.SourcePath)
+		w.project = scratch
+		_ = w.add(root.SourcePath, root.DestPath, true)
+	}
+
+	found := make(map[string]models.FileEntry, len(scratch.Entries))
+	for _, e := range scratch.Entries {
+		found[e.DestPath] = e
+	}
+	return found
+}
+
+// sourceWatch — наблюдение за источниками одного открытого проекта
+type sourceWatch struct {
+	watcher *inotify.Watcher
+	cancel  context.CancelFunc
+}
+
+// WatchSources starts watching the source folders of the project with inotify.
+// Changes are reported with EventSourcesChanged; call CheckSources to see them.
+// Watching again replaces the previous watch of the project.
+func (s *ProjectService) WatchSources(project *models.Project) error {
+	if project.ID == "" {
+		return fmt.Errorf("project has no id")
+	}
+	dirs, trees := sourceWatchDirs(project)
+	if len(dirs) > sourceWatchMaxDirs {
+		return fmt.Errorf("too many source folders to watch: %d, limit %d", len(dirs), sourceWatchMaxDirs)
+	}
+
+	watcher, err := inotify.New()
+	if err != nil {
+		return fmt.Errorf("failed to start watching sources: %w", err)
+	}
+	for _, dir := range dirs {
+		// Пропавший каталог уже виден в CheckSources как удалённый
+		if trees[dir] {
+			_ = watcher.AddTree(dir)
+		} else {
+			_ = watcher.Add(dir)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	if old, ok := s.watches[project.ID]; ok {
+		old.stop()
+	}
+	s.watches[project.ID] = &sourceWatch{watcher: watcher, cancel: cancel}
+	s.mu.Unlock()
+
+	go s.forwardSourceEvents(project.ID, watcher.Listen(ctx))
+	return nil
+}
+
+// UnwatchSources stops watching the sources of a project
+func (s *ProjectService) UnwatchSources(projectID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if w, ok := s.watches[projectID]; ok {
+		w.stop()
+		delete(s.watches, projectID)
+	}
+}
+
+func (w *sourceWatch) stop() {
+	w.cancel()
+	_ = w.watcher.Close()
+}
+
+// forwardSourceEvents собирает события inotify и после паузы отправляет одно
+// событие EventSourcesChanged со списком изменившихся путей
+func (s *ProjectService) forwardSourceEvents(projectID string, events <-chan inotify.Event) {
+	var paths []string
+	timer := time.NewTimer(sourceWatchDebounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			if ev.Path != "" && len(paths) < sourceWatchMaxPaths && !slices.Contains(paths, ev.Path) {
+				paths = append(paths, ev.Path)
+			}
+			timer.Reset(sourceWatchDebounce)
+		case <-timer.C:
+			s.emitEvent(models.EventSourcesChanged, map[string]any{
+				"projectId": projectID,
+				"paths":     paths,
+			})
+			paths = nil
+		}
+	}
+}
+
+// sourceWatchDirs — каталоги, изменения в которых затрагивают источники проекта.
+// trees — каталоги внутри папок, переносимых целиком: в них наблюдаются и новые подкаталоги.
+func sourceWatchDirs(project *models.Project) ([]string, map[string]bool) {
+	seen := make(map[string]bool)
+	trees := make(map[string]bool)
+	var dirs []string
+	add := func(dir string) {
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	for _, e := range project.Entries {
+		switch {
+		case e.SourcePath == "":
+		case !e.IsDir || e.Type != "":
+			add(filepath.Dir(e.SourcePath))
+		case e.Listed:
+			// Содержимое папки — отдельные записи проекта со своими каталогами
+			add(e.SourcePath)
+		case !trees[e.SourcePath]:
+			// Папка переносится целиком: обходим её один раз со всеми подкаталогами
+			_ = filepath.WalkDir(e.SourcePath, func(p string, d os.DirEntry, err error) error {
+				if err == nil && d.IsDir() {
+					add(p)
+					trees[p] = true
+				}
+				return nil
+			})
+		}
+	}
+	return dirs, trees
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"xorriso-ui/pkg/models"
+)
+
+// changeKinds возвращает вид изменения по DestPath
+func changeKinds(report *SourceReport) map[string]string {
+	m := make(map[string]string, len(report.Changes))
+	for _, c := range report.Changes {
+		m[c.DestPath] = c.Kind
+	}
+	return m
+}
+
+func TestCheckSources_AddedRemovedModified(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "photos")
+	os.MkdirAll(filepath.Join(src, "2024"), 0755)
+	os.WriteFile(filepath.Join(src, "a.jpg"), []byte("aaaa"), 0644)
+	os.WriteFile(filepath.Join(src, "2024", "b.jpg"), []byte("bb"), 0644)
+	single := filepath.Join(t.TempDir(), "notes.txt")
+	os.WriteFile(single, []byte("n"), 0644)
+
+	svc := NewProjectService()
+	svc.emitEvent = noopEmit
+	project := svc.NewProject("Photos", "PHOTOS")
+	svc.AddFiles(project, []string{src, single}, "/")
+
+	if report := svc.CheckSources(project); len(report.Changes) != 0 || report.Checked != 5 {
+		t.Fatalf("fresh project reports changes: %+v", report)
+	}
+
+	os.Remove(filepath.Join(src, "a.jpg"))
+	os.WriteFile(filepath.Join(src, "2024", "b.jpg"), []byte("bbbbbb"), 0644)
+	os.WriteFile(filepath.Join(src, "2024", "c.jpg"), []byte("c"), 0644)
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(single, later, later)
+
+	report := svc.CheckSources(project)
+	want := map[string]string{
+		"/photos/a.jpg":      SourceRemoved,
+		"/photos/2024/b.jpg": SourceModified,
+		"/photos/2024/c.jpg": SourceAdded,
+		"/notes.txt":         SourceModified,
+		// Объём каталогов меняется вместе с содержимым
+		"/photos":      SourceModified,
+		"/photos/2024": SourceModified,
+	}
+	got := changeKinds(report)
+	if len(got) != len(want) {
+		t.Errorf("changes = %v, want %v", got, want)
+	}
+	for dest, kind := range want {
+		if got[dest] != kind {
+			t.Errorf("%s: kind = %q, want %q", dest, got[dest], kind)
+		}
+	}
+	if report.Project != nil {
+		t.Error("CheckSources must not return the project")
+	}
+	if _, ok := destPaths(project)["/photos/2024/c.jpg"]; ok {
+		t.Error("CheckSources must not change the project")
+	}
+}
+
+func TestRefreshSources_UpdatesEntriesAndUndo(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "docs")
+	os.MkdirAll(src, 0755)
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("a"), 0644)
+	os.WriteFile(filepath.Join(src, "b.txt"), []byte("b"), 0644)
+
+	svc := NewProjectService()
+	svc.emitEvent = noopEmit
+	project := svc.NewProject("Docs", "DOCS")
+	svc.AddFiles(project, []string{src}, "/")
+	// Переопределения атрибутов переживают обновление
+	svc.SetEntryMetadata(project, []string{"/docs/b.txt"}, models.EntryMetadata{HideJoliet: true})
+
+	os.Remove(filepath.Join(src, "a.txt"))
+	os.WriteFile(filepath.Join(src, "b.txt"), []byte("bbb"), 0644)
+	os.WriteFile(filepath.Join(src, "c.txt"), []byte("cc"), 0644)
+
+	report, err := svc.RefreshSources(project)
+	if err != nil {
+		t.Fatalf("RefreshSources: %v", err)
+	}
+	if report.Project != project || len(report.Changes) != 4 {
+		t.Fatalf("report = %+v", report)
+	}
+
+	entries := make(map[string]models.FileEntry)
+	for _, e := range project.Entries {
+		entries[e.DestPath] = e
+	}
+	if _, ok := entries["/docs/a.txt"]; ok {
+		t.Error("removed file still in project")
+	}
+	if b := entries["/docs/b.txt"]; b.Size != 3 || !b.Meta.HideJoliet {
+		t.Errorf("b.txt = %+v", b)
+	}
+	if c, ok := entries["/docs/c.txt"]; !ok || c.Size != 2 {
+		t.Errorf("added file = %+v", c)
+	}
+	if d := entries["/docs"]; d.Size != 5 {
+		t.Errorf("folder size = %d, want 5", d.Size)
+	}
+	if again := svc.CheckSources(project); len(again.Changes) != 0 {
+		t.Errorf("changes after refresh: %+v", again.Changes)
+	}
+
+	if _, err := svc.Undo(project); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := destPaths(project)["/docs/a.txt"]; !ok {
+		t.Error("undo must restore removed entries")
+	}
+}
+
+func TestRefreshSources_KeepsEdits(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "docs")
+	os.MkdirAll(filepath.Join(src, "old"), 0755)
+	for _, name := range []string{"a.txt", "b.txt", "c.txt", "old/x.txt"} {
+		os.WriteFile(filepath.Join(src, name), []byte(name), 0644)
+	}
+
+	svc := NewProjectService()
+	svc.emitEvent = noopEmit
+	project := svc.NewProject("Docs", "DOCS")
+	svc.AddFiles(project, []string{src}, "/")
+
+	svc.RemoveEntry(project, "/docs/b.txt")
+	svc.RenameEntry(project, "/docs/a.txt", "renamed.txt")
+	svc.MoveEntries(project, []string{"/docs/c.txt"}, "/other")
+	svc.RemoveEntries(project, []string{"/docs/old", "/docs/old/x.txt"})
+	if len(project.Removed) != 3 {
+		t.Errorf("Removed = %v", project.Removed)
+	}
+
+	// Новые файлы в папке и в убранной подпапке
+	os.WriteFile(filepath.Join(src, "d.txt"), []byte("d"), 0644)
+	os.WriteFile(filepath.Join(src, "old", "y.txt"), []byte("y"), 0644)
+
+	report, err := svc.RefreshSources(project)
+	if err != nil {
+		t.Fatalf("RefreshSources: %v", err)
+	}
+	got := changeKinds(report)
+	if got["/docs/d.txt"] != SourceAdded || len(got) != 2 || got["/docs"] != SourceModified {
+		t.Errorf("changes = %v", got)
+	}
+	sources := make(map[string]int)
+	for _, e := range project.Entries {
+		sources[e.SourcePath]++
+	}
+	for _, name := range []string{"a.txt", "c.txt"} {
+		if n := sources[filepath.Join(src, name)]; n != 1 {
+			t.Errorf("%s is in the project %d times", name, n)
+		}
+	}
+	for _, name := range []string{"b.txt", "old", "old/y.txt"} {
+		if sources[filepath.Join(src, name)] != 0 {
+			t.Errorf("removed %s came back", name)
+		}
+	}
+
+	// Отмена удаления возвращает запись и забывает её источник
+	svc.Undo(project)
+	svc.Undo(project)
+	if !slices.Equal(project.Removed, []string{filepath.Join(src, "b.txt")}) {
+		t.Errorf("after undo Removed = %v", project.Removed)
+	}
+	// Источник, снова добавленный в проект, больше не считается убранным
+	svc.AddFiles(project, []string{filepath.Join(src, "b.txt")}, "/")
+	svc.RemoveEntry(project, "/other/c.txt")
+	if !slices.Equal(project.Removed, []string{filepath.Join(src, "c.txt")}) {
+		t.Errorf("Removed = %v", project.Removed)
+	}
+}
+
+func TestCheckSources_WholeFolder(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "music")
+	os.MkdirAll(src, 0755)
+	os.WriteFile(filepath.Join(src, "a.mp3"), []byte("aa"), 0644)
+
+	project := &models.Project{Entries: []models.FileEntry{
+		{SourcePath: src, DestPath: "/music", Name: "music", IsDir: true, Size: 2},
+	}}
+	os.WriteFile(filepath.Join(src, "b.mp3"), []byte("bbb"), 0644)
+
+	// Каталог переносится целиком: новые файлы не становятся записями, меняется только объём
+	report := NewProjectService().CheckSources(project)
+	if len(report.Changes) != 1 || report.Changes[0].Kind != SourceModified || report.Changes[0].NewSize != 5 {
+		t.Errorf("changes = %+v", report.Changes)
+	}
+}
+
+func TestWatchSources_EmitsChanges(t *testing.T) {
+	src := t.TempDir()
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("a"), 0644)
+
+	events := make(chan map[string]any, 4)
+	svc := NewProjectService()
+	svc.emitEvent = func(name string, data ...any) {
+		if name == models.EventSourcesChanged {
+			events <- data[0].(map[string]any)
+		}
+	}
+	project := svc.NewProject("Watch", "WATCH")
+	svc.AddFiles(project, []string{filepath.Join(src, "a.txt")}, "/")
+
+	if err := svc.WatchSources(project); err != nil {
+		t.Skipf("inotify unavailable: %v", err)
+	}
+	defer svc.CloseProject(project.ID)
+
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("changed"), 0644)
+	select {
+	case ev := <-events:
+		paths, _ := ev["paths"].([]string)
+		if ev["projectId"] != project.ID || len(paths) == 0 || paths[0] != filepath.Join(src, "a.txt") {
+			t.Errorf("event = %+v", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no sources-changed event")
+	}
+}
+
+func TestSourceWatchDirs(t *testing.T) {
+	root := t.TempDir()
+	whole := filepath.Join(root, "whole")
+	os.MkdirAll(filepath.Join(whole, "a", "b"), 0755)
+	listed := filepath.Join(root, "listed")
+	os.MkdirAll(filepath.Join(listed, "skipped"), 0755)
+	os.WriteFile(filepath.Join(listed, "f.txt"), []byte("f"), 0644)
+
+	project := &models.Project{Entries: []models.FileEntry{
+		{SourcePath: whole, DestPath: "/whole", IsDir: true},
+		{SourcePath: listed, DestPath: "/listed", IsDir: true, Listed: true},
+		{SourcePath: filepath.Join(listed, "f.txt"), DestPath: "/listed/f.txt"},
+		{DestPath: "/virtual", IsDir: true},
+	}}
+	dirs, trees := sourceWatchDirs(project)
+
+	want := []string{whole, filepath.Join(whole, "a"), filepath.Join(whole, "a", "b"), listed}
+	if len(dirs) != len(want) {
+		t.Fatalf("dirs = %v, want %v", dirs, want)
+	}
+	for i := range want {
+		if dirs[i] != want[i] {
+			t.Errorf("dirs[%d] = %s, want %s", i, dirs[i], want[i])
+		}
+	}
+	// Подкаталоги папки с отдельными записями не обходятся и не наблюдаются как дерево
+	if !trees[filepath.Join(whole, "a", "b")] || trees[listed] {
+		t.Errorf("trees = %v", trees)
+	}
+}
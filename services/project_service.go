@@ -2,23 +2,58 @@ package services
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"xorriso-ui/pkg/models"
+
+	"github.com/google/uuid"
 )
 
-type ProjectService struct{}
+type ProjectService struct {
+	mu sync.Mutex
+	// История изменений открытых проектов по Project.ID
+	histories map[string]*editHistory
+	// Фоновые поиски дубликатов по ID
+	scans map[string]*duplicateScan
+	// Состояние изменённых открытых проектов для автосохранения по ID
+	open map[string]*openProject
+	// Наблюдение за источниками открытых проектов по ID
+	watches map[string]*sourceWatch
+	// Порядок записи и удаления файлов автосохранения; берётся раньше mu
+	autosaveMu sync.Mutex
+	// Блокировки записанных автосохранений по ID, под autosaveMu
+	autosaveLocks map[string]*os.File
+	stopAutosave  func()
+	emitEvent     func(name string, data ...any)
+}
 
 func NewProjectService() *ProjectService {
-	return &ProjectService{}
+	return &ProjectService{
+		histories: make(map[string]*editHistory),
+		scans:     make(map[string]*duplicateScan),
+		open:      make(map[string]*openProject),
+		watches:   make(map[string]*sourceWatch),
+		emitEvent: defaultEmitEvent,
+
+		autosaveLocks: make(map[string]*os.File),
+	}
 }
 
 // NewProject creates a new empty project
 func (s *ProjectService) NewProject(name string, volumeID string) *models.Project {
+	return newProject(name, volumeID)
+}
+
+// newProject создаёт пустой проект с настройками по умолчанию
+func newProject(name string, volumeID string) *models.Project {
 	return &models.Project{
-		Version:  1,
+		ID:       uuid.New().String(),
+		Version:  models.ProjectFormatVersion,
 		Name:     name,
 		VolumeID: volumeID,
 		Entries:  []models.FileEntry{},
@@ -38,17 +73,78 @@ func (s *ProjectService) NewProject(name string, volumeID string) *models.Projec
 	}
 }
 
-// SaveProject saves the project to its file path
+// SaveProject saves the project to its file path. The file is replaced atomically,
+// the previous version is kept next to it with the .bak suffix.
+// Untitled projects are only covered by the autosave.
 func (s *ProjectService) SaveProject(project *models.Project) error {
 	if project.FilePath == "" {
 		return nil
 	}
+	project.Version = models.ProjectFormatVersion
 	project.UpdatedAt = time.Now()
 	data, err := json.MarshalIndent(project, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(project.FilePath, data, 0644)
+	if err := backupBeforeSave(project.FilePath); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(project.FilePath, data, 0644); err != nil {
+		return err
+	}
+	s.forgetAutosave(project.ID, false)
+	rememberProject(project)
+	return nil
+}
+
+// backupBeforeSave сохраняет текущую версию файла проекта в path.bak
+func backupBeforeSave(filePath string) error {
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filePath+".bak", data, 0644); err != nil {
+		return fmt.Errorf("failed to back up project file: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic записывает файл через временный файл в том же каталоге и rename,
+// так что при сбое на диске остаётся либо старое, либо новое содержимое целиком
+func writeFileAtomic(filePath string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(filePath)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return err
+	}
+	// Синхронизация каталога фиксирует сам rename
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+	return nil
 }
 
 // SaveProjectAs saves the project to a new file path
@@ -57,83 +153,92 @@ func (s *ProjectService) SaveProjectAs(project *models.Project, filePath string)
 	return s.SaveProject(project)
 }
 
-// OpenProject loads a project from file
+// OpenProject loads a project from file.
+// Files of older format versions are migrated to the current one,
+// the original file is kept next to it as a backup.
 func (s *ProjectService) OpenProject(filePath string) (*models.Project, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
+	migrated, fromVersion, err := migrateProject(data)
+	if err != nil {
+		return nil, err
+	}
+	if fromVersion < models.ProjectFormatVersion {
+		if err := backupProjectFile(filePath, data, fromVersion); err != nil {
+			return nil, err
+		}
+	}
 	var project models.Project
-	if err := json.Unmarshal(data, &project); err != nil {
+	if err := json.Unmarshal(migrated, &project); err != nil {
 		return nil, err
 	}
 	project.FilePath = filePath
+	// Каждое открытие — отдельный сеанс редактирования со своей историей
+	project.ID = uuid.New().String()
+	rememberProject(&project)
 	return &project, nil
 }
 
 // AddFiles adds files/directories to the project.
 // Directories are added recursively — each file inside gets its own entry.
+// Symbolic links and special files follow the project's link and special file
+// policies; sources left out are reported with EventSourcesSkipped.
 func (s *ProjectService) AddFiles(project *models.Project, sourcePaths []string, destDir string) (*models.Project, error) {
-	for _, src := range sourcePaths {
-		info, err := os.Stat(src)
-		if err != nil {
-			continue
-		}
-
-		if info.IsDir() {
-			// Add directory entry itself
-			size, _ := dirSize(src)
-			dirEntry := models.FileEntry{
-				SourcePath: src,
-				DestPath:   filepath.Join(destDir, filepath.Base(src)),
-				Name:       filepath.Base(src),
-				IsDir:      true,
-				Size:       size,
-				ModTime:    info.ModTime().UnixMilli(),
-			}
-			project.Entries = append(project.Entries, dirEntry)
-
-			// Recursively add all files inside
-			baseDest := filepath.Join(destDir, filepath.Base(src))
-			_ = filepath.Walk(src, func(path string, fi os.FileInfo, err error) error {
-				if err != nil || path == src {
-					return nil
-				}
-				rel, _ := filepath.Rel(src, path)
-				project.Entries = append(project.Entries, models.FileEntry{
-					SourcePath: path,
-					DestPath:   filepath.Join(baseDest, rel),
-					Name:       fi.Name(),
-					IsDir:      fi.IsDir(),
-					Size:       fi.Size(),
-					ModTime:    fi.ModTime().UnixMilli(),
-				})
-				return nil
-			})
-		} else {
-			project.Entries = append(project.Entries, models.FileEntry{
-				SourcePath: src,
-				DestPath:   filepath.Join(destDir, filepath.Base(src)),
-				Name:       filepath.Base(src),
-				IsDir:      false,
-				Size:       info.Size(),
-				ModTime:    info.ModTime().UnixMilli(),
-			})
-		}
-	}
+	var skipped []SkippedSource
+	_ = s.recordEdit(project, "Add files", func() error {
+		skipped = addFileEntries(project, sourcePaths, destDir)
+		return nil
+	})
 	project.UpdatedAt = time.Now()
+	if len(skipped) > 0 {
+		s.emitEvent(models.EventSourcesSkipped, skipped)
+	}
 	return project, nil
 }
 
+// addFileEntries appends entries for the given sources under destDir
+func addFileEntries(project *models.Project, sourcePaths []string, destDir string) []SkippedSource {
+	var skipped []SkippedSource
+	for _, src := range sourcePaths {
+		s, _ := addSourceAt(project, src, filepath.Join(destDir, filepath.Base(src)))
+		skipped = append(skipped, s...)
+	}
+	return skipped
+}
+
+// addSourceAt appends entries for a single source placed at destPath in the image.
+// Directories are added recursively — each file inside gets its own entry.
+func addSourceAt(project *models.Project, src string, destPath string) ([]SkippedSource, error) {
+	w := newSourceWalker(project, src)
+	err := w.add(src, destPath, true)
+	return w.skipped, err
+}
+
+// addSingleSourceAt appends an entry for the source itself without directory contents
+func addSingleSourceAt(project *models.Project, src string, destPath string) ([]SkippedSource, error) {
+	w := newSourceWalker(project, src)
+	err := w.add(src, destPath, false)
+	return w.skipped, err
+}
+
 // RemoveEntry removes a file entry from the project by dest path
 func (s *ProjectService) RemoveEntry(project *models.Project, destPath string) (*models.Project, error) {
-	filtered := make([]models.FileEntry, 0, len(project.Entries))
-	for _, e := range project.Entries {
-		if e.DestPath != destPath {
-			filtered = append(filtered, e)
+	_ = s.recordEdit(project, "Remove "+filepath.Base(destPath), func() error {
+		filtered := make([]models.FileEntry, 0, len(project.Entries))
+		var dropped []models.FileEntry
+		for _, e := range project.Entries {
+			if e.DestPath != destPath {
+				filtered = append(filtered, e)
+			} else {
+				dropped = append(dropped, e)
+			}
 		}
-	}
-	project.Entries = filtered
+		project.Entries = filtered
+		rememberRemoved(project, dropped)
+		return nil
+	})
 	project.UpdatedAt = time.Now()
 	return project, nil
 }
@@ -145,24 +250,49 @@ func (s *ProjectService) RemoveEntries(project *models.Project, destPaths []stri
 		toRemove[p] = struct{}{}
 	}
 
-	filtered := make([]models.FileEntry, 0, len(project.Entries))
-	for _, e := range project.Entries {
-		if _, ok := toRemove[e.DestPath]; !ok {
-			filtered = append(filtered, e)
+	_ = s.recordEdit(project, "Remove entries", func() error {
+		filtered := make([]models.FileEntry, 0, len(project.Entries))
+		var dropped []models.FileEntry
+		for _, e := range project.Entries {
+			if _, ok := toRemove[e.DestPath]; !ok {
+				filtered = append(filtered, e)
+			} else {
+				dropped = append(dropped, e)
+			}
 		}
-	}
-	project.Entries = filtered
+		project.Entries = filtered
+		rememberRemoved(project, dropped)
+		return nil
+	})
 	project.UpdatedAt = time.Now()
 	return project, nil
 }
 
-// CalculateSize returns total size of all entries in bytes
+// CalculateSize returns total size of all entries in bytes.
+// A source file mapped to several paths is stored once and counted once.
 func (s *ProjectService) CalculateSize(project *models.Project) (int64, error) {
+	return entriesDataSize(project.Entries), nil
+}
+
+// entriesDataSize суммирует объём данных записей. Размер каталога уже включает
+// его содержимое, поэтому каталог с отдельными дочерними записями не учитывается.
+func entriesDataSize(entries []models.FileEntry) int64 {
 	var total int64
-	for _, e := range project.Entries {
+	counted := make(map[string]bool)
+	for _, e := range entries {
+		// Каталог с отдельными дочерними записями, ссылка или специальный файл — без данных
+		if e.IsDir && e.Listed || e.Type != "" {
+			continue
+		}
+		if e.SourcePath != "" {
+			if counted[e.SourcePath] {
+				continue
+			}
+			counted[e.SourcePath] = true
+		}
 		total += e.Size
 	}
-	return total, nil
+	return total
 }
 
 func dirSize(path string) (int64, error) {
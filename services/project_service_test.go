@@ -525,6 +525,7 @@ func TestSaveProject_EmptyPath(t *testing.T) {
 }
 
 func TestSaveAndOpenProject(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 	dir := t.TempDir()
 	filePath := filepath.Join(dir, "test.xorriso-project")
 
@@ -755,3 +756,20 @@ func TestUnescapeMountPath(t *testing.T) {
 		}
 	}
 }
+
+func TestCalculateSize_DirWithChildren(t *testing.T) {
+	svc := NewProjectService()
+	project := svc.NewProject("Test", "VOL")
+	project.Entries = []models.FileEntry{
+		{SourcePath: "/src/docs", DestPath: "/docs", IsDir: true, Listed: true, Size: 3000},
+		{SourcePath: "/src/docs/a", DestPath: "/docs/a", Size: 1000},
+		{SourcePath: "/src/docs/b", DestPath: "/docs/b", Size: 2000},
+		{SourcePath: "/src/photos", DestPath: "/photos", IsDir: true, Size: 5000},
+	}
+
+	// /docs учитывается через дочерние записи, /photos — целиком
+	total, _ := svc.CalculateSize(project)
+	if total != 8000 {
+		t.Errorf("total = %d, want 8000", total)
+	}
+}
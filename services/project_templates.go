@@ -0,0 +1,207 @@
+package services
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"xorriso-ui/pkg/models"
+)
+
+// Длина идентификатора тома ISO 9660
+const volumeIDMaxLen = 32
+
+// templatesDir — каталог шаблонов рядом с настройками приложения
+func templatesDir() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		configDir = os.TempDir()
+	}
+	return filepath.Join(configDir, "xorriso-ui", "templates")
+}
+
+// templatePath — имя файла шаблона; экранирование сохраняет различие любых имён
+func templatePath(name string) string {
+	return filepath.Join(templatesDir(), url.PathEscape(name)+".json")
+}
+
+// ListTemplates returns the saved project templates sorted by name.
+// Unreadable template files are skipped.
+func (s *ProjectService) ListTemplates() ([]models.ProjectTemplate, error) {
+	files, err := filepath.Glob(filepath.Join(templatesDir(), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	templates := []models.ProjectTemplate{}
+	for _, f := range files {
+		if tmpl, err := readTemplate(f); err == nil {
+			templates = append(templates, *tmpl)
+		}
+	}
+	slices.SortFunc(templates, func(a, b models.ProjectTemplate) int { return cmp.Compare(a.Name, b.Name) })
+	return templates, nil
+}
+
+// SaveTemplate stores a template under its name, replacing a template with the same name
+func (s *ProjectService) SaveTemplate(tmpl models.ProjectTemplate) error {
+	if err := validateTemplate(&tmpl); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(templatesDir(), 0755); err != nil {
+		return err
+	}
+	return writeTemplate(templatePath(tmpl.Name), tmpl)
+}
+
+// SaveProjectAsTemplate stores the settings of a project as a named template.
+// The project's volume ID becomes the volume ID pattern.
+func (s *ProjectService) SaveProjectAsTemplate(project *models.Project, name string) (*models.ProjectTemplate, error) {
+	tmpl := models.ProjectTemplate{
+		Name:            name,
+		VolumeIDPattern: project.VolumeID,
+		ISOOptions:      project.ISOOptions,
+		BurnOptions:     project.BurnOptions,
+		Exclude:         slices.Clone(project.Exclude),
+	}
+	if err := s.SaveTemplate(tmpl); err != nil {
+		return nil, err
+	}
+	return &tmpl, nil
+}
+
+// DeleteTemplate removes a saved template
+func (s *ProjectService) DeleteTemplate(name string) error {
+	if err := os.Remove(templatePath(name)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("template not found: %s", name)
+		}
+		return err
+	}
+	return nil
+}
+
+// NewProjectFromTemplate creates a new project with the settings of a saved template
+func (s *ProjectService) NewProjectFromTemplate(templateName string, projectName string) (*models.Project, error) {
+	tmpl, err := readTemplate(templatePath(templateName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("template not found: %s", templateName)
+		}
+		return nil, err
+	}
+
+	project := s.NewProject(projectName, expandVolumeID(tmpl.VolumeIDPattern, projectName, time.Now()))
+	project.ISOOptions = tmpl.ISOOptions
+	project.BurnOptions = tmpl.BurnOptions
+	project.Exclude = slices.Clone(tmpl.Exclude)
+	return project, nil
+}
+
+// ExportTemplate writes a saved template to filePath to share it
+func (s *ProjectService) ExportTemplate(name string, filePath string) error {
+	tmpl, err := readTemplate(templatePath(name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("template not found: %s", name)
+		}
+		return err
+	}
+	return writeTemplate(filePath, *tmpl)
+}
+
+// ImportTemplate reads a template file and saves it, replacing a template with the same name
+func (s *ProjectService) ImportTemplate(filePath string) (*models.ProjectTemplate, error) {
+	tmpl, err := readTemplate(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.SaveTemplate(*tmpl); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+func readTemplate(filePath string) (*models.ProjectTemplate, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var tmpl models.ProjectTemplate
+	if err := json.Unmarshal(data, &tmpl); err != nil {
+		return nil, fmt.Errorf("invalid template file: %w", err)
+	}
+	if tmpl.Version > models.TemplateFormatVersion {
+		return nil, fmt.Errorf("template version %d is newer than supported version %d, please update xorriso-ui",
+			tmpl.Version, models.TemplateFormatVersion)
+	}
+	if err := validateTemplate(&tmpl); err != nil {
+		return nil, err
+	}
+	return &tmpl, nil
+}
+
+func writeTemplate(filePath string, tmpl models.ProjectTemplate) error {
+	tmpl.Version = models.TemplateFormatVersion
+	if tmpl.Exclude == nil {
+		tmpl.Exclude = []string{}
+	}
+	data, err := json.MarshalIndent(tmpl, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0644)
+}
+
+func validateTemplate(tmpl *models.ProjectTemplate) error {
+	tmpl.Name = strings.TrimSpace(tmpl.Name)
+	if tmpl.Name == "" {
+		return fmt.Errorf("template name is empty")
+	}
+	if err := validateBurnOptions(tmpl.BurnOptions); err != nil {
+		return err
+	}
+	for _, pattern := range tmpl.Exclude {
+		if err := validateGlob(pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetExcludeRules replaces the patterns of files skipped when folders are added to the project
+func (s *ProjectService) SetExcludeRules(project *models.Project, patterns []string) (*models.Project, error) {
+	for _, pattern := range patterns {
+		if err := validateGlob(pattern); err != nil {
+			return nil, err
+		}
+	}
+	err := s.recordEdit(project, "Exclude rules", func() error {
+		project.Exclude = slices.Clone(patterns)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	project.UpdatedAt = time.Now()
+	return project, nil
+}
+
+// expandVolumeID подставляет в шаблон идентификатора тома имя проекта и дату
+func expandVolumeID(pattern, projectName string, now time.Time) string {
+	id := strings.NewReplacer(
+		"{name}", projectName,
+		"{date}", now.Format("20060102"),
+		"{year}", now.Format("2006"),
+		"{month}", now.Format("01"),
+		"{day}", now.Format("02"),
+		"{time}", now.Format("1504"),
+	).Replace(pattern)
+	return truncateBytes(id, volumeIDMaxLen)
+}
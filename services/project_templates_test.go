@@ -0,0 +1,162 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"xorriso-ui/pkg/models"
+)
+
+func photoArchiveTemplate() models.ProjectTemplate {
+	return models.ProjectTemplate{
+		Name:            "Photo archive BD-R",
+		VolumeIDPattern: "PHOTOS_{date}",
+		ISOOptions:      models.ISOOptions{UDF: true, ISOLevel: 3, RockRidge: true, MD5: true},
+		BurnOptions:     models.BurnOptions{Speed: "4x", BurnMode: "DAO", Verify: true, CloseDisc: true},
+		Exclude:         []string{"*.tmp", "Thumbs.db"},
+	}
+}
+
+func TestTemplates_SaveListAndCreateProject(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	svc := NewProjectService()
+
+	if err := svc.SaveTemplate(photoArchiveTemplate()); err != nil {
+		t.Fatalf("SaveTemplate: %v", err)
+	}
+	other := photoArchiveTemplate()
+	other.Name = "Windows data DVD"
+	if err := svc.SaveTemplate(other); err != nil {
+		t.Fatalf("SaveTemplate: %v", err)
+	}
+
+	list, err := svc.ListTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Name != "Photo archive BD-R" || list[1].Name != "Windows data DVD" {
+		t.Fatalf("ListTemplates = %+v", list)
+	}
+
+	project, err := svc.NewProjectFromTemplate("Photo archive BD-R", "Holidays")
+	if err != nil {
+		t.Fatalf("NewProjectFromTemplate: %v", err)
+	}
+	want := photoArchiveTemplate()
+	if project.ISOOptions != want.ISOOptions || project.BurnOptions != want.BurnOptions {
+		t.Errorf("options not applied: %+v %+v", project.ISOOptions, project.BurnOptions)
+	}
+	if !strings.HasPrefix(project.VolumeID, "PHOTOS_"+time.Now().Format("2006")) || project.Name != "Holidays" {
+		t.Errorf("VolumeID = %q, Name = %q", project.VolumeID, project.Name)
+	}
+	if len(project.Exclude) != 2 {
+		t.Errorf("Exclude = %v", project.Exclude)
+	}
+
+	if err := svc.DeleteTemplate("Windows data DVD"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.DeleteTemplate("Windows data DVD"); err == nil {
+		t.Error("deleting a missing template should fail")
+	}
+	if _, err := svc.NewProjectFromTemplate("missing", "x"); err == nil {
+		t.Error("missing template should fail")
+	}
+}
+
+func TestTemplates_ExportImportAndSaveProject(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	svc := NewProjectService()
+
+	project := svc.NewProject("Backup", "BACKUP")
+	project.ISOOptions.Joliet = true
+	project.Exclude = []string{"node_modules"}
+	if _, err := svc.SaveProjectAsTemplate(project, "Team backup"); err != nil {
+		t.Fatalf("SaveProjectAsTemplate: %v", err)
+	}
+
+	exported := filepath.Join(t.TempDir(), "team.json")
+	if err := svc.ExportTemplate("Team backup", exported); err != nil {
+		t.Fatalf("ExportTemplate: %v", err)
+	}
+	if err := svc.DeleteTemplate("Team backup"); err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := svc.ImportTemplate(exported)
+	if err != nil {
+		t.Fatalf("ImportTemplate: %v", err)
+	}
+	if tmpl.Name != "Team backup" || tmpl.VolumeIDPattern != "BACKUP" || !tmpl.ISOOptions.Joliet || tmpl.Exclude[0] != "node_modules" {
+		t.Errorf("imported template = %+v", tmpl)
+	}
+	if list, _ := svc.ListTemplates(); len(list) != 1 {
+		t.Errorf("imported template should be saved, got %+v", list)
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.json")
+	for _, data := range []string{`{"name": ""}`, `{"name": "x", "exclude": ["[a-"]}`, `{"version": 99, "name": "x"}`, `not json`} {
+		if err := os.WriteFile(bad, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := svc.ImportTemplate(bad); err == nil {
+			t.Errorf("ImportTemplate(%s): expected error", data)
+		}
+	}
+}
+
+func TestExpandVolumeID(t *testing.T) {
+	now := time.Date(2026, 3, 7, 9, 5, 0, 0, time.UTC)
+	tests := []struct {
+		pattern, want string
+	}{
+		{"BACKUP_{date}", "BACKUP_20260307"},
+		{"{year}-{month}-{day}_{time}", "2026-03-07_0905"},
+		{"{name}", "Holidays"},
+		{"{name}_{name}_{name}_{name}", "Holidays_Holidays_Holidays_Holid"},
+	}
+	for _, tt := range tests {
+		if got := expandVolumeID(tt.pattern, "Holidays", now); got != tt.want {
+			t.Errorf("expandVolumeID(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestAddFiles_ExcludeRules(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "docs")
+	for _, f := range []string{"a.txt", "b.tmp", "cache/c.txt", "keep/d.txt"} {
+		p := filepath.Join(src, f)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	svc := NewProjectService()
+	svc.emitEvent = noopEmit
+	project := svc.NewProject("p", "P")
+	if _, err := svc.SetExcludeRules(project, []string{"*.tmp", "cache"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.SetExcludeRules(project, []string{"[a-"}); err == nil {
+		t.Error("invalid pattern should be rejected")
+	}
+	svc.AddFiles(project, []string{src, filepath.Join(src, "b.tmp")}, "/")
+
+	paths := destPaths(project)
+	for _, p := range []string{"/docs/b.tmp", "/docs/cache", "/docs/cache/c.txt"} {
+		if _, ok := paths[p]; ok {
+			t.Errorf("%s should be excluded", p)
+		}
+	}
+	for _, p := range []string{"/docs/a.txt", "/docs/keep/d.txt", "/b.tmp"} {
+		if _, ok := paths[p]; !ok {
+			t.Errorf("%s should be added (explicit sources are never excluded)", p)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"xorriso-ui/pkg/models"
+	"xorriso-ui/pkg/xorriso"
+)
+
+// Длины полей заголовка тома по ECMA-119
+const (
+	systemIDMaxLen  = 32
+	volumeSetMaxLen = 128 // а также издатель, автор и приложение
+	fileRefMaxLen   = 37
+)
+
+// fileRefRe — имя файла ISO 9660 в корне образа: d-символы, точка и номер версии
+var fileRefRe = regexp.MustCompile(`^[A-Z0-9_]*(\.[A-Z0-9_]*)?(;[0-9]+)?$`)
+
+// Наборы символов ECMA-119: d-символы для идентификатора тома, a-символы для остальных идентификаторов
+const (
+	dCharacters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_"
+	aCharacters = dCharacters + ` !"%&'()*+,-./:;<=>?`
+)
+
+// volumeUUIDLayout — формат -volume_date uuid без сотых долей секунды
+const volumeUUIDLayout = "20060102150405"
+
+// volumeDate — дата заголовка тома и её тип для -volume_date
+type volumeDate struct {
+	kind string
+	name string
+	ms   int64
+}
+
+func volumeDates(opts models.ISOOptions) []volumeDate {
+	return []volumeDate{
+		{"c", "creation date", opts.CreationDate},
+		{"m", "modification date", opts.ModificationDate},
+		{"x", "expiration date", opts.ExpirationDate},
+		{"f", "effective date", opts.EffectiveDate},
+	}
+}
+
+// validateVolumeDescriptor проверяет длину и набор символов полей заголовка тома
+func validateVolumeDescriptor(volumeID string, opts models.ISOOptions) error {
+	ids := []struct {
+		name, value string
+		maxLen      int
+	}{
+		{"volume ID", volumeID, volumeIDMaxLen},
+		{"system ID", opts.SystemID, systemIDMaxLen},
+		{"volume set ID", opts.VolumeSetID, volumeSetMaxLen},
+		{"publisher ID", opts.PublisherID, volumeSetMaxLen},
+		{"preparer ID", opts.PreparerID, volumeSetMaxLen},
+		{"application ID", opts.ApplicationID, volumeSetMaxLen},
+	}
+	for _, id := range ids {
+		if len(id.value) > id.maxLen {
+			return fmt.Errorf("%s is %d bytes long, the limit is %d", id.name, len(id.value), id.maxLen)
+		}
+		if !utf8.ValidString(id.value) {
+			return fmt.Errorf("%s is not valid UTF-8", id.name)
+		}
+		for _, r := range id.value {
+			if unicode.IsControl(r) {
+				return fmt.Errorf("%s contains a control character", id.name)
+			}
+		}
+	}
+
+	files := []struct{ name, value string }{
+		{"abstract file", opts.AbstractFile},
+		{"bibliographic file", opts.BiblioFile},
+		{"copyright file", opts.CopyrightFile},
+	}
+	for _, f := range files {
+		if len(f.value) > fileRefMaxLen {
+			return fmt.Errorf("%s name is %d bytes long, the limit is %d", f.name, len(f.value), fileRefMaxLen)
+		}
+		if !fileRefRe.MatchString(f.value) {
+			return fmt.Errorf("%s %q must be an ISO 9660 name: A-Z, 0-9, _ and one dot", f.name, f.value)
+		}
+	}
+
+	for _, d := range volumeDates(opts) {
+		if d.ms < 0 {
+			return fmt.Errorf("%s before 1970 is not supported", d.name)
+		}
+		if time.UnixMilli(d.ms).UTC().Year() > 9999 {
+			return fmt.Errorf("%s is past year 9999", d.name)
+		}
+	}
+	if opts.VolumeUUID != "" {
+		if err := validateVolumeUUID(opts.VolumeUUID); err != nil {
+			return err
+		}
+		// uuid задаёт обе даты, отдельные значения были бы перезаписаны
+		if opts.CreationDate != 0 || opts.ModificationDate != 0 {
+			return fmt.Errorf("volume UUID replaces the creation and modification dates; clear them or the UUID")
+		}
+	}
+	return nil
+}
+
+// volumeCharsetIssues предупреждает об идентификаторах заголовка тома с символами вне наборов ECMA-119.
+// xorriso записывает их как есть, но строгие системы могут показать такое поле иначе или отвергнуть его.
+func volumeCharsetIssues(volumeID string, opts models.ISOOptions) []NameIssue {
+	ids := []struct {
+		name, value, allowed, set string
+	}{
+		{"volume ID", volumeID, dCharacters, "d-characters (A-Z, 0-9, _)"},
+		{"system ID", opts.SystemID, aCharacters, "a-characters"},
+		{"volume set ID", opts.VolumeSetID, aCharacters, "a-characters"},
+		{"publisher ID", opts.PublisherID, aCharacters, "a-characters"},
+		{"preparer ID", opts.PreparerID, aCharacters, "a-characters"},
+		{"application ID", opts.ApplicationID, aCharacters, "a-characters"},
+	}
+	var issues []NameIssue
+	for _, id := range ids {
+		var bad []string
+		suggested := []rune(strings.ToUpper(id.value))
+		for i, r := range suggested {
+			if !strings.ContainsRune(id.allowed, r) {
+				suggested[i] = '_'
+			}
+		}
+		for _, r := range id.value {
+			if !strings.ContainsRune(id.allowed, r) && !slices.Contains(bad, string(r)) {
+				bad = append(bad, string(r))
+			}
+		}
+		if len(bad) == 0 {
+			continue
+		}
+		issue := NameIssue{Namespace: NamespaceISO9660, Kind: NameIssueCharset, Severity: SeverityWarning,
+			Message: fmt.Sprintf("%s %q has characters outside the ISO 9660 %s: %q", id.name, id.value, id.set, strings.Join(bad, ""))}
+		// из одних подчёркиваний подсказка бесполезна
+		if s := string(suggested); strings.Trim(s, "_") != "" {
+			issue.SuggestedName = s
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// validateVolumeUUID — 16 цифр YYYYMMDDhhmmsscc, составляющих существующую дату
+func validateVolumeUUID(uuid string) error {
+	if len(uuid) != 16 {
+		return fmt.Errorf("volume UUID must have 16 digits YYYYMMDDhhmmsscc, got %q", uuid)
+	}
+	if _, err := strconv.ParseUint(uuid, 10, 64); err != nil {
+		return fmt.Errorf("volume UUID must have 16 digits YYYYMMDDhhmmsscc, got %q", uuid)
+	}
+	if _, err := time.Parse(volumeUUIDLayout, uuid[:14]); err != nil {
+		return fmt.Errorf("volume UUID %q is not a valid date", uuid)
+	}
+	return nil
+}
+
+// buildVolumeDescriptor добавляет идентификаторы, ссылки на файлы и даты заголовка тома
+func buildVolumeDescriptor(cmd *xorriso.CommandBuilder, opts models.ISOOptions) {
+	cmd.ApplicationID(cmp.Or(opts.ApplicationID, appApplicationID))
+	cmd.SystemID(cmp.Or(opts.SystemID, appSystemID))
+	if opts.PublisherID != "" {
+		cmd.Publisher(opts.PublisherID)
+	}
+	if opts.VolumeSetID != "" {
+		cmd.VolsetID(opts.VolumeSetID)
+	}
+	if opts.PreparerID != "" {
+		cmd.PreparerID(opts.PreparerID)
+	}
+	if opts.AbstractFile != "" {
+		cmd.AbstractFile(opts.AbstractFile)
+	}
+	if opts.BiblioFile != "" {
+		cmd.BiblioFile(opts.BiblioFile)
+	}
+	if opts.CopyrightFile != "" {
+		cmd.CopyrightFile(opts.CopyrightFile)
+	}
+	for _, d := range volumeDates(opts) {
+		if d.ms != 0 {
+			cmd.VolumeDate(d.kind, "="+strconv.FormatInt(d.ms/1000, 10))
+		}
+	}
+	if opts.VolumeUUID != "" {
+		cmd.VolumeDate("uuid", opts.VolumeUUID)
+	}
+}
+
+// mkisofsVolumeArgs переводит поля заголовка тома в опции эмуляции mkisofs
+func mkisofsVolumeArgs(opts models.ISOOptions) ([]string, []string) {
+	var args, warnings []string
+	if opts.PublisherID != "" {
+		args = append(args, "-publisher", opts.PublisherID)
+	}
+	args = append(args, "-A", cmp.Or(opts.ApplicationID, appApplicationID), "-sysid", cmp.Or(opts.SystemID, appSystemID))
+	if opts.VolumeSetID != "" {
+		args = append(args, "-volset", opts.VolumeSetID)
+	}
+	if opts.PreparerID != "" {
+		args = append(args, "-p", opts.PreparerID)
+	}
+	if opts.AbstractFile != "" {
+		args = append(args, "-abstract", opts.AbstractFile)
+	}
+	if opts.BiblioFile != "" {
+		args = append(args, "-biblio", opts.BiblioFile)
+	}
+	if opts.CopyrightFile != "" {
+		args = append(args, "-copyright", opts.CopyrightFile)
+	}
+	if opts.VolumeUUID != "" {
+		args = append(args, "--modification-date="+opts.VolumeUUID)
+	}
+	for _, d := range volumeDates(opts) {
+		if d.ms != 0 {
+			warnings = append(warnings, fmt.Sprintf("the %s has no mkisofs equivalent and was omitted", d.name))
+		}
+	}
+	return args, warnings
+}
+
+// volumeUUIDFromTime возвращает UUID тома из времени заголовка в выводе -pvd_info
+func volumeUUIDFromTime(pvdTime string) string {
+	if len(pvdTime) < 16 {
+		return ""
+	}
+	if _, err := strconv.ParseUint(pvdTime[:16], 10, 64); err != nil || pvdTime[:16] == "0000000000000000" {
+		return ""
+	}
+	return pvdTime[:16]
+}
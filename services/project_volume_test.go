@@ -0,0 +1,204 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"xorriso-ui/pkg/models"
+	"xorriso-ui/pkg/xorriso"
+)
+
+func TestValidateVolumeDescriptor(t *testing.T) {
+	valid := models.ISOOptions{
+		PublisherID:    "ACME Corp.",
+		PreparerID:     "Отдел архива",
+		ApplicationID:  "My Tool 1.0",
+		SystemID:       "GNU",
+		VolumeSetID:    "ARCHIVES",
+		AbstractFile:   "ABSTRACT.TXT",
+		BiblioFile:     "BIBLIO",
+		CopyrightFile:  "COPYING.TXT;1",
+		ExpirationDate: 1900000000000,
+		EffectiveDate:  1700000000000,
+		VolumeUUID:     "2026101812000000",
+	}
+	if err := validateVolumeDescriptor("ARCHIVE_2026", valid); err != nil {
+		t.Fatalf("valid descriptor rejected: %v", err)
+	}
+	if err := validateVolumeDescriptor("", models.ISOOptions{}); err != nil {
+		t.Fatalf("empty descriptor rejected: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		volumeID string
+		edit     func(*models.ISOOptions)
+		want     string
+	}{
+		{"long volume ID", strings.Repeat("V", 33), func(*models.ISOOptions) {}, "volume ID"},
+		{"long system ID", "", func(o *models.ISOOptions) { o.SystemID = strings.Repeat("S", 33) }, "system ID"},
+		{"long preparer", "", func(o *models.ISOOptions) { o.PreparerID = strings.Repeat("П", 65) }, "preparer ID"},
+		{"control character", "", func(o *models.ISOOptions) { o.PublisherID = "ACME\nCorp" }, "control character"},
+		{"invalid UTF-8", "", func(o *models.ISOOptions) { o.ApplicationID = "\xff" }, "UTF-8"},
+		{"lowercase file", "", func(o *models.ISOOptions) { o.CopyrightFile = "copying" }, "ISO 9660 name"},
+		{"file in subdir", "", func(o *models.ISOOptions) { o.AbstractFile = "DOCS/ABSTRACT" }, "ISO 9660 name"},
+		{"long file", "", func(o *models.ISOOptions) { o.BiblioFile = strings.Repeat("B", 38) }, "limit is 37"},
+		{"negative date", "", func(o *models.ISOOptions) { o.ExpirationDate = -1 }, "before 1970"},
+		{"short UUID", "", func(o *models.ISOOptions) { o.VolumeUUID = "20261018" }, "16 digits"},
+		{"UUID not a date", "", func(o *models.ISOOptions) { o.VolumeUUID = "2026139912000000" }, "not a valid date"},
+		{"UUID with dates", "", func(o *models.ISOOptions) { o.CreationDate = 1700000000000 }, "replaces the creation"},
+	}
+	for _, tt := range tests {
+		opts := valid
+		tt.edit(&opts)
+		err := validateVolumeDescriptor(tt.volumeID, opts)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestVolumeCharsetIssues(t *testing.T) {
+	if issues := volumeCharsetIssues("ARCHIVE_2026", models.ISOOptions{
+		PublisherID: `ACME CORP. "X" (1/2): A+B=C?`,
+		SystemID:    "LINUX",
+	}); len(issues) != 0 {
+		t.Errorf("valid IDs reported: %+v", issues)
+	}
+
+	issues := volumeCharsetIssues("My Disc", models.ISOOptions{
+		PublisherID:   "ACME Corp.",
+		PreparerID:    "Отдел",
+		ApplicationID: "TOOL@HOME#1",
+		VolumeSetID:   "SET 1",
+	})
+	want := []struct{ message, suggested string }{
+		{`volume ID "My Disc" has characters outside the ISO 9660 d-characters (A-Z, 0-9, _): "y isc"`, "MY_DISC"},
+		{`publisher ID "ACME Corp." has characters outside the ISO 9660 a-characters: "orp"`, "ACME CORP."},
+		{`preparer ID "Отдел" has characters outside the ISO 9660 a-characters: "Отдел"`, ""},
+		{`application ID "TOOL@HOME#1" has characters outside the ISO 9660 a-characters: "@#"`, "TOOL_HOME_1"},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("issues = %+v, want %d", issues, len(want))
+	}
+	for i, w := range want {
+		issue := issues[i]
+		if issue.Message != w.message || issue.SuggestedName != w.suggested ||
+			issue.Kind != NameIssueCharset || issue.Severity != SeverityWarning || issue.Path != "" {
+			t.Errorf("issue %d = %+v, want %q suggesting %q", i, issue, w.message, w.suggested)
+		}
+	}
+}
+
+func TestBuildVolumeDescriptor(t *testing.T) {
+	cmd := xorriso.NewCommand()
+	buildVolumeDescriptor(cmd, models.ISOOptions{
+		ApplicationID:  "My Tool 1.0",
+		VolumeSetID:    "ARCHIVES",
+		PreparerID:     "Backup team",
+		CopyrightFile:  "COPYING",
+		CreationDate:   1700000000500,
+		ExpirationDate: 1900000000000,
+	})
+	args := cmd.Build()
+
+	for _, seq := range [][]string{
+		{"-application_id", "My Tool 1.0"},
+		{"-system_id", appSystemID},
+		{"-volset_id", "ARCHIVES"},
+		{"-preparer_id", "Backup team"},
+		{"-copyright_file", "COPYING"},
+		{"-volume_date", "c", "=1700000000"},
+		{"-volume_date", "x", "=1900000000"},
+	} {
+		if !containsSequence(args, seq...) {
+			t.Errorf("expected %v in %v", seq, args)
+		}
+	}
+	for _, absent := range []string{"-publisher", "-abstract_file", "-biblio_file", "m", "f", "uuid"} {
+		if containsArg(args, absent) {
+			t.Errorf("unexpected %q in %v", absent, args)
+		}
+	}
+
+	cmd = xorriso.NewCommand()
+	buildVolumeDescriptor(cmd, models.ISOOptions{VolumeUUID: "2026101812000000"})
+	if args := cmd.Build(); !containsSequence(args, "-application_id", appApplicationID) ||
+		!containsSequence(args, "-volume_date", "uuid", "2026101812000000") {
+		t.Errorf("args = %v", args)
+	}
+}
+
+func TestProjectISOCommand_InvalidDescriptor(t *testing.T) {
+	svc := NewBurnService(&mockRunner{})
+	svc.emitEvent = noopEmit
+	project := &models.Project{
+		VolumeID: "VOL",
+		ISOOptions: models.ISOOptions{
+			CopyrightFile: "copying.txt",
+		},
+		Entries: []models.FileEntry{{SourcePath: "/src/a", DestPath: "/a"}},
+	}
+	if _, err := svc.projectISOCommand(project, "/tmp/out.iso"); err == nil || !strings.Contains(err.Error(), "copyright file") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestUpdateISOOptions_RejectsInvalidDescriptor(t *testing.T) {
+	svc := NewProjectService()
+	project := newProject("test", "VOL")
+	before := project.ISOOptions
+
+	opts := project.ISOOptions
+	opts.SystemID = strings.Repeat("S", 40)
+	if _, err := svc.UpdateISOOptions(project, opts); err == nil {
+		t.Fatal("expected error for a long system ID")
+	}
+	if project.ISOOptions != before {
+		t.Errorf("rejected options were applied: %+v", project.ISOOptions)
+	}
+	if _, err := svc.SetVolumeID(project, strings.Repeat("V", 40)); err == nil || project.VolumeID != "VOL" {
+		t.Errorf("long volume ID: err = %v, VolumeID = %q", err, project.VolumeID)
+	}
+}
+
+func TestMkisofsVolumeArgs(t *testing.T) {
+	args, warnings := mkisofsVolumeArgs(models.ISOOptions{
+		PublisherID:    "ACME",
+		SystemID:       "GNU",
+		VolumeSetID:    "ARCHIVES",
+		PreparerID:     "Backup team",
+		AbstractFile:   "ABSTRACT.TXT",
+		VolumeUUID:     "2026101812000000",
+		ExpirationDate: 1900000000000,
+	})
+	want := []string{
+		"-publisher", "ACME",
+		"-A", appApplicationID, "-sysid", "GNU",
+		"-volset", "ARCHIVES",
+		"-p", "Backup team",
+		"-abstract", "ABSTRACT.TXT",
+		"--modification-date=2026101812000000",
+	}
+	if strings.Join(args, "|") != strings.Join(want, "|") {
+		t.Errorf("args = %q, want %q", args, want)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "expiration date") {
+		t.Errorf("warnings = %v", warnings)
+	}
+}
+
+func TestVolumeUUIDFromTime(t *testing.T) {
+	tests := map[string]string{
+		"2026101812000000":   "2026101812000000",
+		"2026101812000000+0": "2026101812000000",
+		"0000000000000000":   "",
+		"2024-01-15 10:00":   "",
+		"":                   "",
+	}
+	for in, want := range tests {
+		if got := volumeUUIDFromTime(in); got != want {
+			t.Errorf("volumeUUIDFromTime(%q) = %q, want %q", in, got, want)
+		}
+	}
+}